@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"go.followtheprocess.codes/cli"
+	"go.followtheprocess.codes/cli/flag"
 	"go.followtheprocess.codes/zap/internal/zap"
 )
 
@@ -15,6 +16,9 @@ for validity.
 
 If it is a directory, this directory is scanned recursively for all
 files with the '.http' extension and any matching files will be validated.
+
+Variables the files expect to be provided at runtime may be given with '--var key=value'
+or '--var-file', a warning is shown for any that aren't used by the files checked.
 `
 
 // check returns the check subcommand.
@@ -26,9 +30,11 @@ func check() (*cli.Command, error) {
 		cli.Short("Check http files for syntax errors"),
 		cli.Long(checkLong),
 		cli.Arg(&options.Path, "path", "The path to check", cli.ArgDefault(".")),
+		cli.Flag(&options.Vars, "var", flag.NoShortHand, "Set a variable as key=value, overriding the file"),
+		cli.Flag(&options.VarFiles, "var-file", flag.NoShortHand, "Load variables from a dotenv, JSON or YAML file"),
 		cli.Flag(&options.Debug, "debug", 'd', "Enable debug logging"),
 		cli.Run(func(ctx context.Context, cmd *cli.Command) error {
-			app := zap.New(options.Debug, version, cmd.Stdin(), cmd.Stdout(), cmd.Stderr())
+			app := zap.New(options.Debug, version, cmd.Stdin(), isTerminal(cmd.Stdin()), cmd.Stdout(), cmd.Stderr())
 			return app.Check(ctx, options)
 		}),
 	)
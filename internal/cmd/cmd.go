@@ -3,6 +3,7 @@ package cmd
 
 import (
 	"context"
+	"io"
 	"os"
 
 	"go.followtheprocess.codes/cli"
@@ -16,9 +17,6 @@ var (
 	date    = ""
 )
 
-// TODO(@FollowTheProcess): Also, the opposite! An import subcommand that can take curl snippets, postman
-// collections etc. and convert them to .http files
-
 // Build builds and returns the zap CLI.
 func Build() (*cli.Command, error) {
 	var debug bool
@@ -37,19 +35,36 @@ func Build() (*cli.Command, error) {
 		),
 		cli.Example("Check for syntax errors in a file", "zap check ./demo.http"),
 		cli.Example("Check for syntax errors in multiple files (recursively)", "zap check ./examples"),
+		cli.Example("Convert a Postman collection to a .http file", "zap import --from postman collection.json"),
 		cli.Flag(&debug, "debug", 'd', "Enable debug logs"),
 		cli.SubCommands(
 			run,
 			check,
 			export,
+			importCmd,
 			test,
 			cli.CompletionSubCommand(),
 		),
 		cli.Run(func(ctx context.Context, cmd *cli.Command) error {
-			app := zap.New(debug, version, os.Stdin, os.Stdout, os.Stderr)
+			app := zap.New(debug, version, os.Stdin, isTerminal(os.Stdin), os.Stdout, os.Stderr)
 			app.Hello(ctx)
 
 			return nil
 		}),
 	)
 }
+
+// isTerminal reports whether r is an interactive terminal, so zap may prompt on it.
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"go.followtheprocess.codes/cli"
+	"go.followtheprocess.codes/cli/flag"
 	"go.followtheprocess.codes/zap/internal/zap"
 )
 
@@ -21,12 +22,18 @@ func export() (*cli.Command, error) {
 			&options.Format,
 			"format",
 			'f',
-			"Export format, one of (json|curl|yaml|toml|postman)",
+			"Export format, one of (json|curl|yaml|toml|postman|har)",
 			cli.FlagDefault("json"),
 		),
+		cli.Flag(&options.Prompts, "prompt", flag.NoShortHand, "Answer a prompt as name=value instead of asking"),
+		cli.Flag(&options.PromptFile, "prompt-file", flag.NoShortHand, "Read prompt answers from a file, '-' for stdin"),
+		cli.Flag(&options.Vars, "var", flag.NoShortHand, "Set a variable as key=value, overriding the file"),
+		cli.Flag(&options.VarFiles, "var-file", flag.NoShortHand, "Load variables from a dotenv, JSON or YAML file"),
+		cli.Flag(&options.Environment, "env", 'e', "Name of the environment to use from http-client.env.json"),
+		cli.Flag(&options.Seed, "seed", flag.NoShortHand, "Seed the random builtins for reproducible values, 0 means random"),
 		cli.Flag(&options.Debug, "debug", 'd', "Enable debug logging"),
 		cli.Run(func(ctx context.Context, cmd *cli.Command) error {
-			app := zap.New(options.Debug, version, cmd.Stdin(), cmd.Stdout(), cmd.Stderr())
+			app := zap.New(options.Debug, version, cmd.Stdin(), isTerminal(cmd.Stdin()), cmd.Stdout(), cmd.Stderr())
 
 			f, err := os.Open(options.File)
 			if err != nil {
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.followtheprocess.codes/cli"
+	"go.followtheprocess.codes/cli/flag"
+	"go.followtheprocess.codes/zap/internal/zap"
+)
+
+// importCmd returns the zap import subcommand.
+func importCmd() (*cli.Command, error) {
+	var options zap.ImportOptions
+
+	return cli.New(
+		"import",
+		cli.Short("Import a file from an alternative format as a .http file"),
+		cli.Example("Convert a Postman collection", "zap import --from postman collection.json > api.http"),
+		cli.Example("Convert curl commands from the clipboard", "pbpaste | zap import --from curl > api.http"),
+		cli.Example("Generate a .http file per tag from an OpenAPI spec", "zap import --from openapi --split ./http spec.yaml"),
+		cli.Arg(&options.File, "file", "Path to the file to import, or '-' to read from stdin", cli.ArgDefault("-")),
+		cli.Flag(
+			&options.From,
+			"from",
+			'f',
+			"Format to import from, one of (json|yaml|toml|curl|postman|har|openapi)",
+			cli.FlagDefault("postman"),
+		),
+		cli.Flag(&options.Split, "split", flag.NoShortHand, "Write a .http file per OpenAPI tag to this directory"),
+		cli.Flag(&options.Dedupe, "dedupe", flag.NoShortHand, "Import each static asset in a HAR archive only once"),
+		cli.Flag(&options.Debug, "debug", 'd', "Enable debug logging"),
+		cli.Run(func(ctx context.Context, cmd *cli.Command) error {
+			app := zap.New(options.Debug, version, cmd.Stdin(), isTerminal(cmd.Stdin()), cmd.Stdout(), cmd.Stderr())
+
+			if options.File == "-" {
+				return app.Import(ctx, cmd.Stdin(), options)
+			}
+
+			f, err := os.Open(options.File)
+			if err != nil {
+				return fmt.Errorf("zap import: %w", err)
+			}
+			defer f.Close()
+
+			return app.Import(ctx, f, options)
+		}),
+	)
+}
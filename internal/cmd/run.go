@@ -14,7 +14,31 @@ const runLong = `
 The run command executes one or more http requests from a file, by default all
 the requests in the target file will be run in order of their definition.
 
-The '--request' flag may be used to filter the list of requests to execute.
+The '--request' flag may be used to filter the list of requests to execute. Requests
+that reference other requests e.g. '{{ login.response.body.$.token }}' always have the
+referenced requests executed first, but only the responses to the requests asked for
+are shown.
+
+Values captured from a response with '# @capture name = <source>' are defined globally
+for the rest of the run, so later requests may use them as '{{ name }}'.
+
+Variables may also come from a named environment in a JetBrains compatible environment
+file, 'http-client.env.json' or 'http-client.private.env.json' (for secrets), found next to
+the .http file or in any parent directory. Select one with '--env', e.g. '--env staging'.
+Variables defined in the .http file take precedence over those from the environment.
+
+Variables may be overridden without editing the file with '--var key=value' or
+'--var-file' (a dotenv, JSON or YAML file of variables), both may be repeated. From
+highest to lowest, the precedence is: variables declared on a request, '--var',
+'--var-file' (later files first), the file's global variables and finally the selected
+environment. A warning is shown for any variable given that isn't used.
+
+Any '@prompt' variables are asked for interactively. To run without a terminal, e.g. in CI,
+answer them up front with '--prompt name=value' (or '--prompt request.name=value' to answer
+a single request's prompt) or '--prompt-file' with a dotenv, JSON or YAML file of answers,
+'--prompt-file -' reads them from stdin. If stdin is not a terminal, any unanswered prompts
+without a default are listed and zap exits without sending any requests. Answers must be one
+of the prompt's choices and a valid value of its type, if it declares them.
 
 Configuration such as timeouts, redirects etc. are set in the .http file, or assume their
 default values if not specified. However, they can be overridden by flags with flags
@@ -26,7 +50,14 @@ the entire collection, pass '--overall--timeout'.
 
 Responses can be displayed in different formats with the '--output' flag. By default
 responses are printed in a user-friendly format to stdout, but may also be serialized as
-json by passing '--output json'.
+a structured record per request with '--output json' (one JSON object per line, ready for
+piping into jq) or '--output yaml' (a stream of YAML documents). Each record contains the
+request name, method and URL along with the response status, protocol, headers, body,
+duration (in nanoseconds for JSON) and any values captured from the response.
+
+With '--output har' the requests and their responses, with a breakdown of how long each
+phase took, are written as a single HTTP Archive (HAR) once every request has been sent,
+which can be opened in browser devtools and other HTTP tools.
 `
 
 // run returns the zap run subcommand.
@@ -59,13 +90,19 @@ func run() (*cli.Command, error) {
 			"Overall timeout for the execution",
 			cli.FlagDefault(zap.DefaultOverallTimeout),
 		),
+		cli.Flag(&options.Prompts, "prompt", flag.NoShortHand, "Answer a prompt as name=value instead of asking"),
+		cli.Flag(&options.PromptFile, "prompt-file", flag.NoShortHand, "Read prompt answers from a file, '-' for stdin"),
+		cli.Flag(&options.Vars, "var", flag.NoShortHand, "Set a variable as key=value, overriding the file"),
+		cli.Flag(&options.VarFiles, "var-file", flag.NoShortHand, "Load variables from a dotenv, JSON or YAML file"),
+		cli.Flag(&options.Environment, "env", 'e', "Name of the environment to use from http-client.env.json"),
+		cli.Flag(&options.Seed, "seed", flag.NoShortHand, "Seed the random builtins for reproducible values, 0 means random"),
 		cli.Flag(&options.NoRedirect, "no-redirect", flag.NoShortHand, "Disable following redirects"),
-		cli.Flag(&options.Output, "output", 'o', "Output format, one of (stdout|json|yaml)", cli.FlagDefault("stdout")),
+		cli.Flag(&options.Output, "output", 'o', "Output format, one of (stdout|json|yaml|har)", cli.FlagDefault("stdout")),
 		cli.Flag(&options.Requests, "request", 'r', "Name(s) of requests to execute"),
 		cli.Flag(&options.Verbose, "verbose", 'v', "Show additional response data"),
 		cli.Flag(&options.Debug, "debug", 'd', "Enable debug logging"),
 		cli.Run(func(ctx context.Context, cmd *cli.Command) error {
-			app := zap.New(options.Debug, version, cmd.Stdin(), cmd.Stdout(), cmd.Stderr())
+			app := zap.New(options.Debug, version, cmd.Stdin(), isTerminal(cmd.Stdin()), cmd.Stdout(), cmd.Stderr())
 
 			f, err := os.Open(options.File)
 			if err != nil {
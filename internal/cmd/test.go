@@ -8,25 +8,48 @@ import (
 	"go.followtheprocess.codes/zap/internal/zap"
 )
 
-// TODO(@FollowTheProcess): Work this out a bit more, would be good to get some sort of assertion
-// like functionality, checking status code, inspecting body elements with JSONPath etc.
-//
-// Idea: The response ref is a yaml file containing two "files", the top one with metadata about the test,
-// this is where assertions could go. The bottom one containing the JSON of
-// the response, since all JSON is valid yaml, this should work?
-
 const testLong = `
 The test command executes a collection of http requests/files as tests.
 
 HTTP requests may define a response reference in the form '<> response.json', in these
 cases, the request will be run as a test with the response ref file serving as the
 golden file. If the fetched response does not match the reference, the test will fail.
+JSON bodies are compared structurally, and a reference may also record the status line and
+headers in the form of a raw HTTP response ('HTTP/1.1 200 OK' followed by headers).
+
+Volatile values can be excluded from the comparison with '# @ignore <JSONPath>' and
+'# @ignore-header <Name>', either per request or for every request in the file e.g.
+'# @ignore $.createdAt' or '@ignore-header Date'.
+
+Requests may also declare assertions about the response in the form
+'# @assert <subject> <operator> [value]' e.g. '# @assert status == 201'. The subject may be
+'status', 'body', 'header.<Name>' or a JSONPath expression into a JSON body such as
+'$.items[0].id', and the operator one of ==, !=, <, <=, >, >=, contains, matches or exists.
+Any request with assertions is run as a test, each failed assertion is reported along with
+its position in the file.
+
+Run with '--update' to overwrite each response reference with the live response instead,
+missing references (and their directories) are created. Each reference is reported as added,
+changed or unchanged. Assertions are still checked in update mode.
 
 Path is a .http file or a directory containing .http files, in the latter case, the directory
 is recursed and all .http files collected for testing.
 
+Pass '--env' to select a named environment from the 'http-client.env.json' and
+'http-client.private.env.json' files nearest each .http file e.g. '--env staging'.
+
+Variables can be overridden for the run with '--var key=value' or '--var-file' (a dotenv,
+JSON or YAML file), taking precedence over the globals in each file.
+
+Prompts may be answered up front with '--prompt name=value' or '--prompt-file' so tests
+can run unattended, unanswered prompts are an error if stdin is not a terminal.
+
 In test mode, the responses are typically hidden (unless the test fails) in favour of
 a compact summary. This can be enhanced with the '--verbose' flag.
+
+For CI, '--reporter' writes machine readable results in JUnit XML, TAP or JSON format, each
+.http file is a test suite and each request a test case. The report replaces the summary on
+stdout, or is written to the file given by '--report-file' alongside it.
 `
 
 // test returns the zap test subcommand.
@@ -59,12 +82,21 @@ func test() (*cli.Command, error) {
 			"Overall timeout for the execution",
 			cli.FlagDefault(zap.DefaultOverallTimeout),
 		),
+		cli.Flag(&options.Prompts, "prompt", flag.NoShortHand, "Answer a prompt as name=value instead of asking"),
+		cli.Flag(&options.PromptFile, "prompt-file", flag.NoShortHand, "Read prompt answers from a file, '-' for stdin"),
+		cli.Flag(&options.Vars, "var", flag.NoShortHand, "Set a variable as key=value, overriding the file"),
+		cli.Flag(&options.VarFiles, "var-file", flag.NoShortHand, "Load variables from a dotenv, JSON or YAML file"),
+		cli.Flag(&options.Environment, "env", 'e', "Name of the environment to use from http-client.env.json"),
+		cli.Flag(&options.Seed, "seed", flag.NoShortHand, "Seed the random builtins for reproducible values, 0 means random"),
 		cli.Flag(&options.NoRedirect, "no-redirect", flag.NoShortHand, "Disable following redirects"),
+		cli.Flag(&options.Update, "update", 'u', "Update response references with the live responses"),
+		cli.Flag(&options.Reporter, "reporter", flag.NoShortHand, "Report format, one of (junit|tap|json)"),
+		cli.Flag(&options.ReportFile, "report-file", flag.NoShortHand, "Write the report to a file instead of stdout"),
 		cli.Flag(&options.Requests, "request", 'r', "Name(s) of requests to test"),
 		cli.Flag(&options.Verbose, "verbose", 'v', "Show additional test information"),
 		cli.Flag(&options.Debug, "debug", 'd', "Enable debug logging"),
 		cli.Run(func(ctx context.Context, cmd *cli.Command) error {
-			app := zap.New(options.Debug, version, cmd.Stdin(), cmd.Stdout(), cmd.Stderr())
+			app := zap.New(options.Debug, version, cmd.Stdin(), isTerminal(cmd.Stdin()), cmd.Stdout(), cmd.Stderr())
 			return app.Test(ctx, options)
 		}),
 	)
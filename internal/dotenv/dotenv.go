@@ -0,0 +1,63 @@
+// Package dotenv parses dotenv files, lines of 'KEY=value' as used to keep secrets
+// e.g. '.env', out of version control.
+package dotenv
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parse parses the contents of a dotenv file, lines of 'KEY=value' optionally
+// prefixed with 'export'.
+//
+// Blank lines and lines beginning with '#' are ignored. Values may be quoted, double
+// quoted values have their escapes (e.g. '\n') interpreted, single quoted values
+// are taken literally.
+func Parse(contents []byte) (map[string]string, error) {
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	line := 0
+
+	for scanner.Scan() {
+		line++
+
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		text = strings.TrimPrefix(text, "export ")
+
+		key, value, ok := strings.Cut(text, "=")
+		key = strings.TrimSpace(key)
+
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=value, got %q", line, text)
+		}
+
+		value = strings.TrimSpace(value)
+
+		switch {
+		case len(value) > 1 && value[0] == '"' && value[len(value)-1] == '"':
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid quoted value %s: %w", line, value, err)
+			}
+
+			value = unquoted
+		case len(value) > 1 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		}
+
+		vars[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return vars, nil
+}
@@ -0,0 +1,66 @@
+package dotenv_test
+
+import (
+	"maps"
+	"testing"
+
+	"go.followtheprocess.codes/test"
+	"go.followtheprocess.codes/zap/internal/dotenv"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		want     map[string]string // Expected variables
+		name     string            // Name of the test case
+		contents string            // Contents of the dotenv file
+		errMsg   string            // If we wanted an error, what should it say
+		wantErr  bool              // Whether we want an error
+	}{
+		{
+			name:     "empty",
+			contents: "",
+			want:     map[string]string{},
+		},
+		{
+			name:     "simple",
+			contents: "API_KEY=abc123\nHOST = localhost\n",
+			want:     map[string]string{"API_KEY": "abc123", "HOST": "localhost"},
+		},
+		{
+			name:     "comments and blanks",
+			contents: "# A comment\n\nexport TOKEN=secret\n",
+			want:     map[string]string{"TOKEN": "secret"},
+		},
+		{
+			name:     "quoted",
+			contents: "DOUBLE=\"line\\nbreak\"\nSINGLE='not\\nescaped'\nEMPTY=\n",
+			want:     map[string]string{"DOUBLE": "line\nbreak", "SINGLE": `not\nescaped`, "EMPTY": ""},
+		},
+		{
+			name:     "missing equals",
+			contents: "OK=1\nnope\n",
+			wantErr:  true,
+			errMsg:   `line 2: expected KEY=value, got "nope"`,
+		},
+		{
+			name:     "bad quotes",
+			contents: `BAD="\q"`,
+			wantErr:  true,
+			errMsg:   `line 1: invalid quoted value "\q": invalid syntax`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := dotenv.Parse([]byte(tt.contents))
+			test.WantErr(t, err, tt.wantErr)
+
+			if err != nil {
+				test.Equal(t, err.Error(), tt.errMsg)
+				return
+			}
+
+			test.EqualFunc(t, got, tt.want, maps.Equal)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+// Package envfile loads JetBrains compatible environment files, 'http-client.env.json' and
+// 'http-client.private.env.json', containing named sets of variables e.g. one per deployment
+// environment.
+//
+// An environment file is a JSON object of environment names to objects of variables:
+//
+//	{
+//	  "$shared": {"version": "v1"},
+//	  "dev": {"host": "localhost:8080"},
+//	  "prod": {"host": "api.company.com"}
+//	}
+//
+// Variables in the special "$shared" environment are available in every environment, and
+// variables in the private file (intended to be kept out of version control) take precedence
+// over those in the public one.
+package envfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+const (
+	// Public is the name of the public environment file, intended to be committed
+	// alongside the .http files.
+	Public = "http-client.env.json"
+
+	// Private is the name of the private environment file, intended for secrets and
+	// kept out of version control.
+	Private = "http-client.private.env.json"
+
+	// Shared is the name of the environment whose variables are available in every other.
+	Shared = "$shared"
+)
+
+// Environments is the collection of named environments loaded from environment files.
+type Environments struct {
+	envs  map[string]map[string]string // Variables by environment name
+	files []string                     // The environment files loaded, public first
+}
+
+// Find searches for the public and private environment files in dir and each of its
+// parent directories in turn, loading the nearest of each.
+//
+// It is not an error if neither file exists, the returned [Environments] is simply empty.
+func Find(dir string) (Environments, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return Environments{}, fmt.Errorf("could not resolve %s: %w", dir, err)
+	}
+
+	var paths []string
+
+	for _, name := range []string{Public, Private} {
+		path, ok, err := nearest(dir, name)
+		if err != nil {
+			return Environments{}, err
+		}
+
+		if ok {
+			paths = append(paths, path)
+		}
+	}
+
+	return Load(paths...)
+}
+
+// Load loads the environment files at paths, in order, with variables in later files
+// taking precedence over those in earlier ones.
+func Load(paths ...string) (Environments, error) {
+	environments := Environments{
+		envs:  make(map[string]map[string]string),
+		files: paths,
+	}
+
+	for _, path := range paths {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return Environments{}, fmt.Errorf("could not read environment file: %w", err)
+		}
+
+		var raw map[string]map[string]json.RawMessage
+		if err := json.Unmarshal(contents, &raw); err != nil {
+			return Environments{}, fmt.Errorf("invalid environment file %s: %w", path, err)
+		}
+
+		for name, variables := range raw {
+			env, ok := environments.envs[name]
+			if !ok {
+				env = make(map[string]string, len(variables))
+				environments.envs[name] = env
+			}
+
+			for key, value := range variables {
+				text, ok := scalar(value)
+				if !ok {
+					// Objects and arrays are editor specific configuration (e.g. SSL
+					// settings) rather than variables, so ignore them
+					continue
+				}
+
+				env[key] = text
+			}
+		}
+	}
+
+	return environments, nil
+}
+
+// Files returns the paths of the environment files that were loaded.
+func (e Environments) Files() []string {
+	return slices.Clone(e.files)
+}
+
+// Names returns the names of the selectable environments, sorted alphabetically.
+//
+// The "$shared" environment is not included.
+func (e Environments) Names() []string {
+	names := slices.Sorted(maps.Keys(e.envs))
+	return slices.DeleteFunc(names, func(name string) bool { return name == Shared })
+}
+
+// Get returns the variables in the environment called name, including any shared
+// variables it does not override.
+//
+// If there is no such environment, ok will be false.
+func (e Environments) Get(name string) (variables map[string]string, ok bool) {
+	env, ok := e.envs[name]
+	if !ok || name == Shared {
+		return nil, false
+	}
+
+	variables = maps.Clone(e.envs[Shared])
+	if variables == nil {
+		variables = make(map[string]string, len(env))
+	}
+
+	maps.Copy(variables, env)
+
+	return variables, true
+}
+
+// nearest returns the path to the file called name in dir or the nearest of its parents.
+func nearest(dir, name string) (path string, ok bool, err error) {
+	for {
+		path := filepath.Join(dir, name)
+
+		_, err := os.Stat(path)
+		if err == nil {
+			return path, true, nil
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", false, fmt.Errorf("could not check for environment file: %w", err)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false, nil
+		}
+
+		dir = parent
+	}
+}
+
+// scalar returns the textual form of a JSON string, number, boolean or null.
+//
+// Strings are returned unquoted, null as an empty string and numbers and booleans
+// as written. Objects and arrays are not scalars so ok will be false.
+func scalar(value json.RawMessage) (text string, ok bool) {
+	var decoded any
+	if err := json.Unmarshal(value, &decoded); err != nil {
+		return "", false
+	}
+
+	switch decoded := decoded.(type) {
+	case string:
+		return decoded, true
+	case nil:
+		return "", true
+	case float64, bool:
+		return string(value), true
+	default:
+		return "", false
+	}
+}
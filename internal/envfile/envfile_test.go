@@ -0,0 +1,97 @@
+package envfile_test
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"go.followtheprocess.codes/test"
+	"go.followtheprocess.codes/zap/internal/envfile"
+)
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "api", "v1")
+	test.Ok(t, os.MkdirAll(nested, 0o755))
+
+	public := `{
+  "$shared": {"version": "v1", "host": "shared.com"},
+  "dev": {"host": "localhost", "port": 8080, "secure": false, "ssl": {"verify": false}},
+  "prod": {"host": "api.com", "token": null}
+}`
+
+	private := `{
+  "dev": {"token": "dev-secret"},
+  "staging": {"token": "staging-secret"}
+}`
+
+	// Public at the root, private closer to the .http file
+	test.Ok(t, os.WriteFile(filepath.Join(root, envfile.Public), []byte(public), 0o644))
+	test.Ok(t, os.WriteFile(filepath.Join(root, "api", envfile.Private), []byte(private), 0o644))
+
+	environments, err := envfile.Find(nested)
+	test.Ok(t, err)
+
+	test.EqualFunc(t, environments.Files(), []string{
+		filepath.Join(root, envfile.Public),
+		filepath.Join(root, "api", envfile.Private),
+	}, slices.Equal)
+
+	test.EqualFunc(t, environments.Names(), []string{"dev", "prod", "staging"}, slices.Equal)
+
+	tests := []struct {
+		want map[string]string // Expected variables
+		name string            // Name of the environment
+		ok   bool              // Whether the environment should exist
+	}{
+		{
+			name: "dev",
+			want: map[string]string{
+				"version": "v1",
+				"host":    "localhost",
+				"port":    "8080",
+				"secure":  "false",
+				"token":   "dev-secret",
+			},
+			ok: true,
+		},
+		{
+			name: "prod",
+			want: map[string]string{"version": "v1", "host": "api.com", "token": ""},
+			ok:   true,
+		},
+		{
+			name: "staging",
+			want: map[string]string{"version": "v1", "host": "shared.com", "token": "staging-secret"},
+			ok:   true,
+		},
+		{name: "$shared", want: nil, ok: false},
+		{name: "missing", want: nil, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := environments.Get(tt.name)
+			test.Equal(t, ok, tt.ok)
+			test.EqualFunc(t, got, tt.want, maps.Equal)
+		})
+	}
+}
+
+func TestFindNone(t *testing.T) {
+	environments, err := envfile.Find(t.TempDir())
+	test.Ok(t, err)
+
+	test.Equal(t, len(environments.Files()), 0)
+	test.Equal(t, len(environments.Names()), 0)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), envfile.Public)
+	test.Ok(t, os.WriteFile(path, []byte(`{"dev": "not an object"}`), 0o644))
+
+	_, err := envfile.Load(path)
+	test.Err(t, err)
+}
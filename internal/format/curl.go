@@ -2,18 +2,21 @@ package format
 
 import (
 	_ "embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	"go.followtheprocess.codes/zap/internal/spec"
 )
 
-// TODO(@FollowTheProcess): Curl importer might be tricky?
-//
-// We'll need to parse the curl command as a shell script basically, could use
-// mvdan/sh for that
-
 // TODO(@FollowTheProcess): It dumps the body as raw text but doesn't correctly escape it to be valid
 //
 // Multi-line JSON needs to be minified. Probably what we should do is if it's up to a certain limit
@@ -54,3 +57,528 @@ type CurlExporter struct{}
 func (c CurlExporter) Export(w io.Writer, file spec.File) error {
 	return curlTemplate.Execute(w, file)
 }
+
+// CurlImporter is an [Importer] that transforms curl command lines, e.g. from API docs or a
+// browser's "Copy as cURL", into a [spec.File] with a request for each curl command.
+//
+// The command lines are split into words as a shell would, with support for quoting, escapes
+// and line continuations but not for expansions such as variables or globs. Options zap has
+// no equivalent of are ignored.
+type CurlImporter struct {
+	// Warn, if not nil, is called with a description of everything in the commands that
+	// could not be imported.
+	Warn func(msg string)
+}
+
+// Import implements [Importer] for [CurlImporter].
+func (c CurlImporter) Import(r io.Reader) (spec.File, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return spec.File{}, fmt.Errorf("could not read curl commands: %w", err)
+	}
+
+	commands, err := shellCommands(string(src))
+	if err != nil {
+		return spec.File{}, fmt.Errorf("could not parse curl commands: %w", err)
+	}
+
+	var file spec.File
+
+	for _, command := range commands {
+		if len(command) == 0 {
+			continue
+		}
+
+		if command[0] != "curl" {
+			c.warnf("%s is not a curl command and was ignored", command[0])
+			continue
+		}
+
+		request, err := c.request(command[1:])
+		if err != nil {
+			return spec.File{}, fmt.Errorf("request %d: %w", len(file.Requests)+1, err)
+		}
+
+		file.Requests = append(file.Requests, request)
+	}
+
+	if len(file.Requests) == 0 {
+		return spec.File{}, errors.New("no curl commands found")
+	}
+
+	return file, nil
+}
+
+// curlData is a body given with one of curl's data options e.g. '--data'.
+type curlData struct {
+	value  string // The data, or the path of the file to read it from
+	file   bool   // Whether value is a path to a file
+	encode bool   // Whether value is to be URL encoded e.g. with '--data-urlencode'
+}
+
+// request returns the request made by a single curl command with the given arguments.
+func (c CurlImporter) request(args []string) (spec.Request, error) {
+	request := spec.Request{
+		Headers:    make(spec.Headers),
+		NoRedirect: true, // curl only follows redirects with --location
+	}
+
+	var (
+		data []curlData
+		get  bool
+	)
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			if len(request.URL) != 0 {
+				c.warnf("more than one URL, %s was ignored", arg)
+				continue
+			}
+
+			request.URL = spec.Text(arg)
+
+			continue
+		}
+
+		name, value, attached := curlOption(arg)
+
+		if !attached && !strings.HasPrefix(arg, "--") && len(arg) > len("-s") {
+			// Combined short options e.g. '-sSL', expanded to '-s -S -L'. The last may
+			// have a value attached e.g. '-sXPOST'
+			expanded := make([]string, 0, len(arg)-1)
+			for j := 1; j < len(arg); j++ {
+				option, _, hasValue := curlOption("-" + arg[j:])
+				if hasValue {
+					expanded = append(expanded, "-"+arg[j:])
+					break
+				}
+
+				expanded = append(expanded, option[:len("-s")])
+			}
+
+			args = slices.Concat(args[:i], expanded, args[i+1:])
+			i--
+
+			continue
+		}
+
+		// next returns the value of the option, attached to it or the next argument
+		next := func() (string, error) {
+			if attached {
+				return value, nil
+			}
+
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("option %s requires a value", arg)
+			}
+
+			i++
+
+			return args[i], nil
+		}
+
+		switch name {
+		case "-L", "--location":
+			request.NoRedirect = false
+		case "--compressed", "-k", "--insecure", "-s", "--silent", "-S", "--show-error",
+			"-v", "--verbose", "-i", "--include", "-f", "--fail", "--create-dirs":
+			// Either the default in zap, or nothing to do with the request itself
+		case "-G", "--get":
+			get = true
+		case "-I", "--head":
+			request.Method = http.MethodHead
+		case "--http1.1":
+			request.HTTPVersion = "HTTP/1.1"
+		case "--http2":
+			request.HTTPVersion = "HTTP/2"
+		case "-F", "--form", "--form-string":
+			if _, err := next(); err != nil {
+				return spec.Request{}, err
+			}
+
+			c.warnf("%s multipart form data is not supported and was ignored", name)
+		default:
+			value, err := next()
+			if err != nil {
+				return spec.Request{}, err
+			}
+
+			if err := c.option(&request, &data, name, value); err != nil {
+				return spec.Request{}, err
+			}
+		}
+	}
+
+	if len(request.URL) == 0 {
+		return spec.Request{}, errors.New("no URL")
+	}
+
+	if !strings.Contains(request.URL.String(), "://") {
+		// curl's default
+		request.URL = spec.Text("http://").Concat(request.URL)
+	}
+
+	if err := c.body(&request, data, get); err != nil {
+		return spec.Request{}, err
+	}
+
+	if request.Method == "" {
+		request.Method = http.MethodGet
+	}
+
+	if len(request.Headers) == 0 {
+		request.Headers = nil
+	}
+
+	return request, nil
+}
+
+// option applies a curl option that takes a value to request, adding any data to data.
+func (c CurlImporter) option(request *spec.Request, data *[]curlData, name, value string) error {
+	switch name {
+	case "-X", "--request":
+		request.Method = strings.ToUpper(value)
+	case "--url":
+		request.URL = spec.Text(value)
+	case "-H", "--header":
+		key, val, ok := strings.Cut(value, ":")
+		if !ok {
+			c.warnf("header %q has no value and was ignored", value)
+			return nil
+		}
+
+		request.Headers.Add(strings.TrimSpace(key), spec.Text(strings.TrimSpace(val)))
+	case "-A", "--user-agent":
+		request.Headers.Set("User-Agent", spec.Text(value))
+	case "-e", "--referer":
+		request.Headers.Set("Referer", spec.Text(value))
+	case "-b", "--cookie":
+		if !strings.Contains(value, "=") {
+			c.warnf("cookie file %s is not supported and was ignored", value)
+			return nil
+		}
+
+		request.Headers.Set("Cookie", spec.Text(value))
+	case "-u", "--user":
+		credentials := base64.StdEncoding.EncodeToString([]byte(value))
+		request.Headers.Set("Authorization", spec.Text("Basic "+credentials))
+	case "-d", "--data", "--data-ascii", "--data-binary":
+		*data = append(*data, curlData{value: strings.TrimPrefix(value, "@"), file: strings.HasPrefix(value, "@")})
+	case "--data-raw":
+		*data = append(*data, curlData{value: value})
+	case "--data-urlencode":
+		*data = append(*data, curlData{value: value, encode: true})
+	case "--json":
+		*data = append(*data, curlData{value: strings.TrimPrefix(value, "@"), file: strings.HasPrefix(value, "@")})
+
+		if len(request.Headers.Get("Content-Type")) == 0 {
+			request.Headers.Set("Content-Type", spec.Text("application/json"))
+		}
+
+		if len(request.Headers.Get("Accept")) == 0 {
+			request.Headers.Set("Accept", spec.Text("application/json"))
+		}
+	case "-m", "--max-time":
+		timeout, err := curlSeconds(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+
+		request.Timeout = timeout
+	case "--connect-timeout":
+		timeout, err := curlSeconds(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+
+		request.ConnectionTimeout = timeout
+	case "-o", "--output":
+		request.ResponseFile = spec.Text(value)
+	default:
+		c.warnf("option %s is not supported and was ignored", name)
+	}
+
+	return nil
+}
+
+// body sets the body of request from the data given with curl's data options, or its URL
+// query if get is set.
+func (c CurlImporter) body(request *spec.Request, data []curlData, get bool) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	if len(data) == 1 && data[0].file && !get {
+		if data[0].value == "-" {
+			return errors.New("data from stdin is not supported")
+		}
+
+		request.BodyFile = spec.Text(data[0].value)
+	} else {
+		parts := make([]string, 0, len(data))
+
+		for _, part := range data {
+			switch {
+			case part.file:
+				c.warnf("data file %s cannot be combined with other data and was ignored", part.value)
+			case part.encode:
+				parts = append(parts, curlURLEncode(part.value))
+			default:
+				parts = append(parts, part.value)
+			}
+		}
+
+		body := strings.Join(parts, "&")
+
+		if get {
+			separator := "?"
+			if strings.Contains(request.URL.String(), "?") {
+				separator = "&"
+			}
+
+			request.URL = request.URL.Concat(spec.Text(separator + body))
+
+			return nil
+		}
+
+		request.Body = spec.Text(body)
+	}
+
+	if request.Method == "" {
+		// curl sends data with a POST unless told otherwise
+		request.Method = http.MethodPost
+	}
+
+	return nil
+}
+
+// curlOption splits a command line option into its name and any value attached to it,
+// e.g. '-XPOST' or '--request=POST'.
+//
+// Short options that take no value may be combined e.g. '-sSL', these are returned as is.
+func curlOption(arg string) (name, value string, attached bool) {
+	if strings.HasPrefix(arg, "--") {
+		name, value, attached = strings.Cut(arg, "=")
+		return name, value, attached
+	}
+
+	const short = 2 // A dash and a single letter
+	if len(arg) > short && strings.ContainsRune("XHdumoAebF", rune(arg[1])) {
+		return arg[:short], arg[short:], true
+	}
+
+	return arg, "", false
+}
+
+// curlURLEncode URL encodes data given with '--data-urlencode', of the form 'content' or
+// 'name=content' where only the content is encoded.
+func curlURLEncode(data string) string {
+	name, content, ok := strings.Cut(data, "=")
+	if !ok {
+		return url.QueryEscape(data)
+	}
+
+	if name == "" {
+		return url.QueryEscape(content)
+	}
+
+	return name + "=" + url.QueryEscape(content)
+}
+
+// curlSeconds parses a number of seconds as given to curl, which may be fractional.
+func curlSeconds(value string) (time.Duration, error) {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// warnf calls Warn with a formatted message, if there is one.
+func (c CurlImporter) warnf(format string, a ...any) {
+	if c.Warn != nil {
+		c.Warn(fmt.Sprintf(format, a...))
+	}
+}
+
+// shellCommands splits src into commands, each a list of words, as a POSIX shell would.
+//
+// Words may be quoted with single quotes, double quotes or ANSI-C quotes e.g. $'line\n', and
+// a backslash escapes the next character or continues the command on the next line. Commands
+// are separated by newlines, ';', '&&', '||' and '|'. Comments are skipped.
+func shellCommands(src string) ([][]string, error) {
+	var (
+		commands [][]string
+		command  []string
+		word     strings.Builder
+		inWord   bool // Whether a word has been started, it may be empty e.g. ''
+	)
+
+	endWord := func() {
+		if inWord {
+			command = append(command, word.String())
+			word.Reset()
+
+			inWord = false
+		}
+	}
+
+	endCommand := func() {
+		endWord()
+
+		if len(command) != 0 {
+			commands = append(commands, command)
+			command = nil
+		}
+	}
+
+	for i := 0; i < len(src); i++ {
+		char := src[i]
+
+		switch {
+		case char == '\\':
+			if i+1 < len(src) {
+				i++
+				if src[i] == '\n' {
+					// Line continuation
+					continue
+				}
+
+				word.WriteByte(src[i])
+
+				inWord = true
+			}
+		case char == '\'':
+			end := strings.IndexByte(src[i+1:], '\'')
+			if end == -1 {
+				return nil, errors.New("unterminated single quote")
+			}
+
+			word.WriteString(src[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case char == '$' && i+1 < len(src) && src[i+1] == '\'':
+			n, err := ansiQuoted(&word, src[i+2:])
+			if err != nil {
+				return nil, err
+			}
+
+			i += n + 1
+			inWord = true
+		case char == '"':
+			n, err := doubleQuoted(&word, src[i+1:])
+			if err != nil {
+				return nil, err
+			}
+
+			i += n
+			inWord = true
+		case char == '#' && !inWord:
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+
+			endCommand()
+		case char == '\n' || char == ';' || char == '|' || char == '&':
+			endCommand()
+
+			if (char == '|' || char == '&') && i+1 < len(src) && src[i+1] == char {
+				i++
+			}
+		case char == ' ' || char == '\t' || char == '\r':
+			endWord()
+		default:
+			word.WriteByte(char)
+
+			inWord = true
+		}
+	}
+
+	endCommand()
+
+	return commands, nil
+}
+
+// doubleQuoted writes the contents of a double quoted string to word, src starts after the
+// opening quote. It returns the number of bytes consumed including the closing quote.
+//
+// Within double quotes a backslash only escapes '"', '\\', '$', '`' and a newline.
+func doubleQuoted(word *strings.Builder, src string) (int, error) {
+	for i := 0; i < len(src); i++ {
+		switch char := src[i]; char {
+		case '"':
+			return i + 1, nil
+		case '\\':
+			if i+1 < len(src) && strings.IndexByte("\"\\$`\n", src[i+1]) != -1 {
+				i++
+				if src[i] != '\n' {
+					word.WriteByte(src[i])
+				}
+
+				continue
+			}
+
+			word.WriteByte(char)
+		default:
+			word.WriteByte(char)
+		}
+	}
+
+	return 0, errors.New("unterminated double quote")
+}
+
+// ansiQuoted writes the contents of an ANSI-C quoted string e.g. $'line\n' to word, src starts
+// after the opening quote. It returns the number of bytes consumed including the closing quote.
+func ansiQuoted(word *strings.Builder, src string) (int, error) {
+	for i := 0; i < len(src); i++ {
+		char := src[i]
+
+		if char == '\'' {
+			return i + 1, nil
+		}
+
+		if char != '\\' || i+1 >= len(src) {
+			word.WriteByte(char)
+			continue
+		}
+
+		i++
+
+		switch escape := src[i]; escape {
+		case 'n':
+			word.WriteByte('\n')
+		case 't':
+			word.WriteByte('\t')
+		case 'r':
+			word.WriteByte('\r')
+		case 'x', 'u', 'U':
+			digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[escape]
+
+			end := i + 1
+			for end < len(src) && end-i-1 < digits && strings.IndexByte("0123456789abcdefABCDEF", src[end]) != -1 {
+				end++
+			}
+
+			code, err := strconv.ParseUint(src[i+1:end], 16, 32)
+			if err != nil {
+				return 0, fmt.Errorf("invalid escape \\%c in $'...'", escape)
+			}
+
+			if escape == 'x' {
+				word.WriteByte(byte(code))
+			} else {
+				word.WriteRune(rune(code))
+			}
+
+			i = end - 1
+		default:
+			// Including \\, \' and \"
+			word.WriteByte(escape)
+		}
+	}
+
+	return 0, errors.New("unterminated $' quote")
+}
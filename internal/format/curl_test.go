@@ -5,6 +5,8 @@ import (
 	"flag"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -31,7 +33,7 @@ func TestCurlExporter(t *testing.T) {
 				Requests: []spec.Request{
 					{
 						Method: http.MethodGet,
-						URL:    "https://api.nowhere.com/v1/items/1234",
+						URL:    spec.Text("https://api.nowhere.com/v1/items/1234"),
 					},
 				},
 			},
@@ -43,7 +45,7 @@ func TestCurlExporter(t *testing.T) {
 				Requests: []spec.Request{
 					{
 						Method:     http.MethodGet,
-						URL:        "https://api.nowhere.com/v1/items/1234",
+						URL:        spec.Text("https://api.nowhere.com/v1/items/1234"),
 						NoRedirect: true,
 					},
 				},
@@ -56,12 +58,12 @@ func TestCurlExporter(t *testing.T) {
 				Requests: []spec.Request{
 					{
 						Method: http.MethodGet,
-						URL:    "https://jsonplaceholder.typicode.com/todos/1",
-						Headers: http.Header{
-							"Content-Type":    []string{"application/json"},
-							"Accept":          []string{"application/json", "application/xml"},
-							"User-Agent":      []string{"go.followtheprocess.codes/zap test"},
-							"X-Custom-Header": []string{"yes", "multiple", "things"},
+						URL:    spec.Text("https://jsonplaceholder.typicode.com/todos/1"),
+						Headers: spec.Headers{
+							"Content-Type":    {spec.Text("application/json")},
+							"Accept":          {spec.Text("application/json"), spec.Text("application/xml")},
+							"User-Agent":      {spec.Text("go.followtheprocess.codes/zap test")},
+							"X-Custom-Header": {spec.Text("yes"), spec.Text("multiple"), spec.Text("things")},
 						},
 					},
 				},
@@ -74,7 +76,7 @@ func TestCurlExporter(t *testing.T) {
 				Requests: []spec.Request{
 					{
 						Method:            http.MethodDelete,
-						URL:               "https://somewhere.org/api",
+						URL:               spec.Text("https://somewhere.org/api"),
 						ConnectionTimeout: 1 * time.Second,
 						Timeout:           15 * time.Second,
 					},
@@ -88,8 +90,8 @@ func TestCurlExporter(t *testing.T) {
 				Requests: []spec.Request{
 					{
 						Method: http.MethodPost,
-						URL:    "https://somewhere.org/api/items/1",
-						Body:   `{"stuff":"here"}`,
+						URL:    spec.Text("https://somewhere.org/api/items/1"),
+						Body:   spec.Text(`{"stuff":"here"}`),
 					},
 				},
 			},
@@ -101,8 +103,8 @@ func TestCurlExporter(t *testing.T) {
 				Requests: []spec.Request{
 					{
 						Method: http.MethodPost,
-						URL:    "https://somewhere.org/api/items/1",
-						Body:   largeBody,
+						URL:    spec.Text("https://somewhere.org/api/items/1"),
+						Body:   spec.Text(largeBody),
 					},
 				},
 			},
@@ -114,8 +116,8 @@ func TestCurlExporter(t *testing.T) {
 				Requests: []spec.Request{
 					{
 						Method:   http.MethodPost,
-						URL:      "https://somewhere.org/api/items/1",
-						BodyFile: "a/file.txt",
+						URL:      spec.Text("https://somewhere.org/api/items/1"),
+						BodyFile: spec.Text("a/file.txt"),
 					},
 				},
 			},
@@ -127,8 +129,8 @@ func TestCurlExporter(t *testing.T) {
 				Requests: []spec.Request{
 					{
 						Method:       http.MethodGet,
-						URL:          "https://api.elsehwere.new/users/1",
-						ResponseFile: "response.200.json",
+						URL:          spec.Text("https://api.elsehwere.new/users/1"),
+						ResponseFile: spec.Text("response.200.json"),
 					},
 				},
 			},
@@ -140,48 +142,48 @@ func TestCurlExporter(t *testing.T) {
 				Requests: []spec.Request{
 					{
 						Method: http.MethodGet,
-						URL:    "https://api.nowhere.com/v1/items/1234",
+						URL:    spec.Text("https://api.nowhere.com/v1/items/1234"),
 					},
 					{
 						Method:     http.MethodGet,
-						URL:        "https://api.nowhere.com/v1/items/1234",
+						URL:        spec.Text("https://api.nowhere.com/v1/items/1234"),
 						NoRedirect: true,
 					},
 					{
 						Method:       http.MethodGet,
-						URL:          "https://api.elsehwere.new/users/1",
-						ResponseFile: "response.200.json",
+						URL:          spec.Text("https://api.elsehwere.new/users/1"),
+						ResponseFile: spec.Text("response.200.json"),
 					},
 					{
 						Method: http.MethodGet,
-						URL:    "https://jsonplaceholder.typicode.com/todos/1",
-						Headers: http.Header{
-							"Content-Type":    []string{"application/json"},
-							"Accept":          []string{"application/json", "application/xml"},
-							"User-Agent":      []string{"go.followtheprocess.codes/zap test"},
-							"X-Custom-Header": []string{"yes", "multiple", "things"},
+						URL:    spec.Text("https://jsonplaceholder.typicode.com/todos/1"),
+						Headers: spec.Headers{
+							"Content-Type":    {spec.Text("application/json")},
+							"Accept":          {spec.Text("application/json"), spec.Text("application/xml")},
+							"User-Agent":      {spec.Text("go.followtheprocess.codes/zap test")},
+							"X-Custom-Header": {spec.Text("yes"), spec.Text("multiple"), spec.Text("things")},
 						},
 					},
 					{
 						Method:            http.MethodDelete,
-						URL:               "https://somewhere.org/api",
+						URL:               spec.Text("https://somewhere.org/api"),
 						ConnectionTimeout: 1 * time.Second,
 						Timeout:           15 * time.Second,
 					},
 					{
 						Method: http.MethodPost,
-						URL:    "https://somewhere.org/api/items/1",
-						Body:   `{"stuff":"here"}`,
+						URL:    spec.Text("https://somewhere.org/api/items/1"),
+						Body:   spec.Text(`{"stuff":"here"}`),
 					},
 					{
 						Method:   http.MethodPost,
-						URL:      "https://somewhere.org/api/items/1",
-						BodyFile: "a/file.txt",
+						URL:      spec.Text("https://somewhere.org/api/items/1"),
+						BodyFile: spec.Text("a/file.txt"),
 					},
 					{
 						Method:       http.MethodGet,
-						URL:          "https://api.elsehwere.new/users/1",
-						ResponseFile: "response.200.json",
+						URL:          spec.Text("https://api.elsehwere.new/users/1"),
+						ResponseFile: spec.Text("response.200.json"),
 					},
 				},
 			},
@@ -205,6 +207,153 @@ func TestCurlExporter(t *testing.T) {
 	}
 }
 
+func TestCurlImporter(t *testing.T) {
+	pattern := filepath.Join("testdata", "curl", "*.sh")
+	files, err := filepath.Glob(pattern)
+	test.Ok(t, err)
+
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".sh")
+		t.Run(name, func(t *testing.T) {
+			snap := snapshot.New(
+				t,
+				snapshot.Update(*update),
+				snapshot.Clean(*clean),
+				snapshot.Color(os.Getenv("CI") == ""),
+			)
+
+			f, err := os.Open(file)
+			test.Ok(t, err)
+			defer f.Close()
+
+			var warnings []string
+
+			importer := format.CurlImporter{
+				Warn: func(msg string) {
+					warnings = append(warnings, msg)
+				},
+			}
+
+			imported, err := importer.Import(f)
+			test.Ok(t, err)
+
+			snap.Snap(imported.String() + "\n# Warnings\n\n" + strings.Join(warnings, "\n") + "\n")
+		})
+	}
+}
+
+func TestCurlImporterInvalid(t *testing.T) {
+	tests := []struct {
+		name   string // Name of the test case
+		src    string // The curl commands to import
+		errMsg string // The expected error message
+	}{
+		{
+			name:   "empty",
+			src:    "",
+			errMsg: "no curl commands found",
+		},
+		{
+			name:   "not curl",
+			src:    "wget https://api.nowhere.com",
+			errMsg: "no curl commands found",
+		},
+		{
+			name:   "unterminated single quote",
+			src:    "curl 'https://api.nowhere.com",
+			errMsg: "could not parse curl commands: unterminated single quote",
+		},
+		{
+			name:   "unterminated double quote",
+			src:    `curl "https://api.nowhere.com`,
+			errMsg: "could not parse curl commands: unterminated double quote",
+		},
+		{
+			name:   "no url",
+			src:    "curl -X GET",
+			errMsg: "request 1: no URL",
+		},
+		{
+			name:   "missing value",
+			src:    "curl https://api.nowhere.com -H",
+			errMsg: "request 1: option -H requires a value",
+		},
+		{
+			name:   "bad timeout",
+			src:    "curl --max-time soon https://api.nowhere.com",
+			errMsg: `request 1: invalid --max-time: strconv.ParseFloat: parsing "soon": invalid syntax`,
+		},
+		{
+			name:   "data from stdin",
+			src:    "curl -d @- https://api.nowhere.com",
+			errMsg: "request 1: data from stdin is not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := format.CurlImporter{}.Import(strings.NewReader(tt.src))
+			test.Err(t, err)
+			test.Equal(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestCurlRoundTrip(t *testing.T) {
+	file := spec.File{
+		Requests: []spec.Request{
+			{
+				Method: http.MethodGet,
+				URL:    spec.Text("https://api.nowhere.com/v1/items/1234"),
+			},
+			{
+				Method:     http.MethodGet,
+				URL:        spec.Text("https://api.nowhere.com/v1/items/1234"),
+				NoRedirect: true,
+			},
+			{
+				Method: http.MethodGet,
+				URL:    spec.Text("https://jsonplaceholder.typicode.com/todos/1"),
+				Headers: spec.Headers{
+					"Content-Type":    {spec.Text("application/json")},
+					"Accept":          {spec.Text("application/json"), spec.Text("application/xml")},
+					"User-Agent":      {spec.Text("go.followtheprocess.codes/zap test")},
+					"X-Custom-Header": {spec.Text("yes"), spec.Text("multiple"), spec.Text("things")},
+				},
+			},
+			{
+				Method:            http.MethodDelete,
+				URL:               spec.Text("https://somewhere.org/api"),
+				ConnectionTimeout: 1500 * time.Millisecond,
+				Timeout:           15 * time.Second,
+			},
+			{
+				Method: http.MethodPost,
+				URL:    spec.Text("https://somewhere.org/api/items/1"),
+				Body:   spec.Text(strings.Join(strings.Fields(largeBody), "")), // The exporter minifies bodies
+			},
+			{
+				Method:   http.MethodPost,
+				URL:      spec.Text("https://somewhere.org/api/items/1"),
+				BodyFile: spec.Text("a/file.txt"),
+			},
+			{
+				Method:       http.MethodGet,
+				URL:          spec.Text("https://api.elsehwere.new/users/1"),
+				ResponseFile: spec.Text("response.200.json"),
+			},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	test.Ok(t, format.CurlExporter{}.Export(buf, file))
+
+	imported, err := format.CurlImporter{}.Import(buf)
+	test.Ok(t, err)
+
+	test.Diff(t, imported.String(), file.String())
+}
+
 const largeBody = `
 {
   "keys": "here",
@@ -8,13 +8,61 @@ package format
 
 import (
 	"io"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"go.followtheprocess.codes/zap/internal/spec"
+	"go.followtheprocess.codes/zap/internal/syntax/token"
 )
 
-// TODO(@FollowTheProcess): A postman exporter
 // TODO(@FollowTheProcess): And an opencollection (bruno) importer/exporter
 
+// bareArgument matches builtin arguments that may be written without quotes.
+//
+//nolint:gochecknoglobals // Compiled once
+var bareArgument = regexp.MustCompile(`^(-?[0-9]+|[A-Za-z][A-Za-z0-9_]*)$`)
+
+// identifierPattern matches names that are valid identifiers in a .http file e.g. the names of
+// variables and requests.
+//
+//nolint:gochecknoglobals // Compiled once
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// nonIdentifier matches runs of characters that are not valid in a lower case identifier.
+//
+//nolint:gochecknoglobals // Compiled once
+var nonIdentifier = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// identifier returns name as an identifier for a .http file, for importing names that may
+// contain anything e.g. 'Get user' becomes 'get-user'.
+func identifier(name string) string {
+	return strings.Trim(nonIdentifier.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// keyword reports whether name is reserved in a .http file e.g. '@name', and so cannot be
+// used as the name of a variable.
+func keyword(name string) bool {
+	_, ok := token.Keyword(name)
+	return ok
+}
+
+// zapBuiltin returns the .http syntax for the builtin called name with args, for formats that
+// are direct representations of a .http file and so can be evaluated by zap itself.
+func zapBuiltin(name string, args ...string) string {
+	parts := []string{"$" + name}
+
+	for _, arg := range args {
+		if !bareArgument.MatchString(arg) {
+			arg = strconv.Quote(arg)
+		}
+
+		parts = append(parts, arg)
+	}
+
+	return "{{ " + strings.Join(parts, " ") + " }}"
+}
+
 // Exporter is the interface defining a mechanism for exporting a .http file
 // into an external format.
 type Exporter interface {
@@ -22,6 +70,20 @@ type Exporter interface {
 	Export(w io.Writer, file spec.File) error
 }
 
+// BuiltinExporter is an [Exporter] for a format with its own equivalents of zap's dynamic
+// builtins e.g. '$uuid', whose values change every time a request is executed.
+//
+// Exporters that do not implement it are given the evaluated value of a dynamic builtin,
+// which is then fixed in the export.
+type BuiltinExporter interface {
+	Exporter
+
+	// Builtin returns the format's equivalent of the dynamic builtin called name (without
+	// the leading '$') called with args, to be written in its place, and whether the format
+	// has one.
+	Builtin(name string, args ...string) (string, bool)
+}
+
 // Importer is the interface defining a mechanism for importing external formats
 // into .http files.
 type Importer interface {
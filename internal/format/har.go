@@ -0,0 +1,569 @@
+package format
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.followtheprocess.codes/zap/internal/spec"
+)
+
+// harVersion is the version of the HAR format written by [HARExporter].
+const harVersion = "1.2"
+
+// harNotApplicable is the value of a HAR timing for a phase that did not happen, or of a
+// size that is not known.
+const harNotApplicable = -1
+
+// HARExporter is an [Exporter] that transforms .http files into HTTP Archive (HAR) 1.2
+// documents, as read by browser devtools and HTTP proxies.
+//
+// Each request becomes an entry in the archive. Requests sent by 'zap run' have their
+// responses and timings recorded in Responses, those without one (e.g. from 'zap export')
+// get an empty response with a status of 0, as HAR has no way of leaving it out.
+//
+// HAR has nowhere to put a request's name or a body read from a file so these are kept in
+// the custom '_name' and '_file' fields, which [HARImporter] reads back.
+type HARExporter struct {
+	// Responses are the responses recorded for the requests in the file, by request name.
+	Responses map[string]HARResponse
+
+	// Version is the version of zap creating the archive.
+	Version string
+}
+
+// HARResponse is a response recorded for a request, to be written to a HAR archive by
+// [HARExporter].
+type HARResponse struct {
+	Started    time.Time   // When the request was started
+	Header     http.Header // Response headers
+	Status     string      // E.g. "200 OK"
+	Proto      string      // E.g. "HTTP/1.1"
+	Body       []byte      // The response body
+	StatusCode int         // HTTP status code
+	Timings    HARTimings  // How long each phase of the request took
+}
+
+// HARTimings are how long each phase of a recorded request took.
+//
+// A phase that did not happen, such as the DNS lookup and connecting when a connection
+// is reused, is zero.
+type HARTimings struct {
+	Blocked time.Duration // Waiting for a connection to become available
+	DNS     time.Duration // Resolving the host name
+	Connect time.Duration // Creating the connection, including TLS
+	TLS     time.Duration // The TLS handshake
+	Send    time.Duration // Sending the request
+	Wait    time.Duration // Waiting for the first byte of the response
+	Receive time.Duration // Reading the response
+}
+
+// Export implements [Exporter] for [HARExporter].
+func (h HARExporter) Export(w io.Writer, file spec.File) error {
+	archive := harArchive{
+		Log: harLog{
+			Version: harVersion,
+			Creator: harCreator{
+				Name:    "zap",
+				Version: h.Version,
+			},
+			Comment: file.Name,
+			Entries: make([]harEntry, 0, len(file.Requests)),
+		},
+	}
+
+	for _, request := range file.Requests {
+		entry := harEntry{
+			Name:     request.Name,
+			Comment:  request.Comment,
+			Request:  harRequestFor(request),
+			Response: harEmptyResponse(),
+			Cache:    struct{}{},
+			Timings: harTimings{
+				Blocked: harNotApplicable,
+				DNS:     harNotApplicable,
+				Connect: harNotApplicable,
+				SSL:     harNotApplicable,
+			},
+		}
+
+		if response, ok := h.Responses[request.Name]; ok {
+			entry.Started = response.Started
+			entry.Response = harResponseFor(response)
+			entry.Timings = harTimingsFor(response.Timings)
+			entry.Time = entry.Timings.total()
+		}
+
+		archive.Log.Entries = append(archive.Log.Entries, entry)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(archive)
+}
+
+// HARImporter is an [Importer] that transforms HTTP Archive (HAR) documents, e.g. saved from
+// a browser's devtools, into a [spec.File] with a request for each entry in the archive.
+//
+// Only the requests are imported, the recorded responses are not. Headers that are derived
+// from the request when it's sent, such as HTTP/2 pseudo headers like ':authority' and
+// Content-Length, are left out.
+type HARImporter struct {
+	// Warn, if not nil, is called with a description of everything in the archive that
+	// could not be imported.
+	Warn func(msg string)
+
+	// Dedupe, if true, imports only the first request for each static asset e.g. scripts,
+	// stylesheets and images, which a page loads over and over again.
+	Dedupe bool
+}
+
+// Import implements [Importer] for [HARImporter].
+func (h HARImporter) Import(r io.Reader) (spec.File, error) {
+	var archive harArchive
+	if err := json.NewDecoder(r).Decode(&archive); err != nil {
+		return spec.File{}, fmt.Errorf("could not decode HAR archive: %w", err)
+	}
+
+	if archive.Log.Version == "" && archive.Log.Entries == nil {
+		return spec.File{}, fmt.Errorf("not a HAR archive, no %q found", "log")
+	}
+
+	file := spec.File{
+		Requests: make([]spec.Request, 0, len(archive.Log.Entries)),
+	}
+
+	if identifierPattern.MatchString(archive.Log.Comment) && !keyword(archive.Log.Comment) {
+		file.Name = archive.Log.Comment
+	}
+
+	names := make(map[string]bool)
+	assets := make(map[string]bool)
+
+	for i, entry := range archive.Log.Entries {
+		if h.Dedupe && entry.static() {
+			asset := entry.Request.Method + " " + entry.Request.URL
+			if assets[asset] {
+				continue
+			}
+
+			assets[asset] = true
+		}
+
+		request, err := h.request(i, entry)
+		if err != nil {
+			return spec.File{}, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+
+		if request.Name != "" {
+			unique := request.Name
+			for n := 2; names[unique]; n++ {
+				unique = request.Name + "-" + strconv.Itoa(n)
+			}
+
+			names[unique] = true
+			request.Name = unique
+		}
+
+		file.Requests = append(file.Requests, request)
+	}
+
+	return file, nil
+}
+
+// request returns the request recorded in the i'th entry in the archive.
+func (h HARImporter) request(i int, entry harEntry) (spec.Request, error) {
+	switch {
+	case entry.Request.Method == "":
+		return spec.Request{}, errors.New("request has no method")
+	case entry.Request.URL == "":
+		return spec.Request{}, errors.New("request has no url")
+	}
+
+	request := spec.Request{
+		Name:        identifier(entry.Name),
+		Method:      strings.ToUpper(entry.Request.Method),
+		URL:         spec.Text(entry.Request.URL),
+		HTTPVersion: harHTTPVersion(entry.Request.HTTPVersion),
+		Headers:     make(spec.Headers),
+	}
+
+	comment := entry.Comment
+	if comment == "" {
+		comment = entry.Request.Comment
+	}
+
+	request.Comment, _, _ = strings.Cut(strings.TrimSpace(comment), "\n")
+
+	for _, header := range entry.Request.Headers {
+		switch {
+		case strings.HasPrefix(header.Name, ":"):
+			// HTTP/2 pseudo headers e.g. ':authority', these are derived from the URL
+		case http.CanonicalHeaderKey(header.Name) == "Content-Length", http.CanonicalHeaderKey(header.Name) == "Host":
+			// Set from the body and URL when sent
+		default:
+			request.Headers.Add(header.Name, spec.Text(header.Value))
+		}
+	}
+
+	if data := entry.Request.PostData; data != nil {
+		switch {
+		case data.File != "":
+			request.BodyFile = spec.Text(data.File)
+		case data.Text != "":
+			request.Body = spec.Text(data.Text)
+		case len(data.Params) != 0:
+			body, ok := harParams(data.Params)
+			if !ok {
+				h.warnf("entry %d: multipart form data is not supported and was ignored", i+1)
+				break
+			}
+
+			request.Body = spec.Text(body)
+		}
+
+		hasBody := len(request.Body) != 0 || len(request.BodyFile) != 0
+		if data.MimeType != "" && len(request.Headers.Get("Content-Type")) == 0 && hasBody {
+			request.Headers.Set("Content-Type", spec.Text(data.MimeType))
+		}
+	}
+
+	if len(request.Headers) == 0 {
+		request.Headers = nil
+	}
+
+	return request, nil
+}
+
+// warnf calls h.Warn with a formatted message, if it's set.
+func (h HARImporter) warnf(format string, a ...any) {
+	if h.Warn != nil {
+		h.Warn(fmt.Sprintf(format, a...))
+	}
+}
+
+// harArchive is the top level of a HAR document.
+//
+// See http://www.softwareishard.com/blog/har-12-spec/.
+type harArchive struct {
+	Log harLog `json:"log"`
+}
+
+// harLog is the log of requests in a HAR document.
+type harLog struct {
+	Creator harCreator `json:"creator"`
+	Version string     `json:"version"`
+	Comment string     `json:"comment,omitempty"`
+	Entries []harEntry `json:"entries"`
+}
+
+// harCreator is the application that created a HAR document.
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// harEntry is a single request and its response in a HAR document.
+type harEntry struct {
+	Started      time.Time   `json:"startedDateTime"`
+	Cache        struct{}    `json:"cache"`
+	Name         string      `json:"_name,omitempty"`
+	ResourceType string      `json:"_resourceType,omitempty"` // Set by Chrome e.g. "script"
+	Comment      string      `json:"comment,omitempty"`
+	Request      harRequest  `json:"request"`
+	Response     harResponse `json:"response"`
+	Timings      harTimings  `json:"timings"`
+	Time         float64     `json:"time"`
+}
+
+// harRequest is a request in a HAR document.
+type harRequest struct {
+	PostData    *harPostData   `json:"postData,omitempty"`
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Comment     string         `json:"comment,omitempty"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+// harResponse is a response in a HAR document.
+type harResponse struct {
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	RedirectURL string         `json:"redirectURL"`
+	Content     harContent     `json:"content"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Status      int            `json:"status"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+// harNameValue is a header, cookie or query parameter in a HAR document.
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// harPostData is the body of a request in a HAR document.
+type harPostData struct {
+	MimeType string     `json:"mimeType"`
+	Text     string     `json:"text"`
+	File     string     `json:"_file,omitempty"` // The file the body is read from
+	Params   []harParam `json:"params,omitempty"`
+}
+
+// harParam is a parameter of a form body in a HAR document.
+type harParam struct {
+	Name     string `json:"name"`
+	Value    string `json:"value,omitempty"`
+	FileName string `json:"fileName,omitempty"`
+}
+
+// harContent is the body of a response in a HAR document.
+type harContent struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Size     int    `json:"size"`
+}
+
+// harTimings are the durations in milliseconds of each phase of a request in a HAR
+// document, -1 for those that did not happen.
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// total returns the total time of the request in milliseconds.
+//
+// The TLS handshake is part of connecting so is not counted again.
+func (t harTimings) total() float64 {
+	var total float64
+
+	for _, timing := range []float64{t.Blocked, t.DNS, t.Connect, t.Send, t.Wait, t.Receive} {
+		total += max(timing, 0)
+	}
+
+	return total
+}
+
+// static reports whether the entry is a request for a static asset, e.g. a script or an
+// image, by the resource type recorded by the browser, the type of the response, or the
+// extension in the URL.
+func (e harEntry) static() bool {
+	switch e.ResourceType {
+	case "script", "stylesheet", "image", "font", "media", "manifest":
+		return true
+	case "":
+		// Not recorded, keep looking
+	default:
+		return false
+	}
+
+	mimeType := e.Response.Content.MimeType
+	for _, prefix := range []string{"image/", "font/", "audio/", "video/", "text/css", "text/javascript", "application/javascript"} {
+		if strings.HasPrefix(mimeType, prefix) {
+			return true
+		}
+	}
+
+	u, err := url.Parse(e.Request.URL)
+	if err != nil {
+		return false
+	}
+
+	switch path.Ext(u.Path) {
+	case ".js", ".mjs", ".css", ".map", ".png", ".jpg", ".jpeg", ".gif", ".svg", ".ico", ".webp", ".avif",
+		".woff", ".woff2", ".ttf", ".otf", ".eot", ".mp3", ".mp4", ".webm":
+		return true
+	default:
+		return false
+	}
+}
+
+// harRequestFor returns the HAR request for a request in a .http file.
+func harRequestFor(request spec.Request) harRequest {
+	result := harRequest{
+		Method:      request.Method,
+		URL:         request.URL.String(),
+		HTTPVersion: request.HTTPVersion,
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(request.Headers.Header()),
+		QueryString: []harNameValue{},
+		HeadersSize: harNotApplicable,
+	}
+
+	if result.HTTPVersion == "" {
+		result.HTTPVersion = "HTTP/1.1"
+	}
+
+	if cookies, err := http.ParseCookie(request.Headers.Get("Cookie").String()); err == nil {
+		for _, cookie := range cookies {
+			result.Cookies = append(result.Cookies, harNameValue{Name: cookie.Name, Value: cookie.Value})
+		}
+	}
+
+	if u, err := url.Parse(request.URL.String()); err == nil {
+		query := u.Query()
+		for _, key := range slices.Sorted(maps.Keys(query)) {
+			for _, value := range query[key] {
+				result.QueryString = append(result.QueryString, harNameValue{Name: key, Value: value})
+			}
+		}
+	}
+
+	switch {
+	case len(request.BodyFile) != 0:
+		result.PostData = &harPostData{
+			MimeType: request.Headers.Get("Content-Type").String(),
+			File:     request.BodyFile.String(),
+		}
+		result.BodySize = harNotApplicable
+	case len(request.Body) != 0:
+		body := request.Body.String()
+		result.PostData = &harPostData{
+			MimeType: request.Headers.Get("Content-Type").String(),
+			Text:     body,
+		}
+		result.BodySize = len(body)
+	}
+
+	return result
+}
+
+// harEmptyResponse returns the HAR response for a request that has not been sent.
+func harEmptyResponse() harResponse {
+	return harResponse{
+		Cookies:     []harNameValue{},
+		Headers:     []harNameValue{},
+		HeadersSize: harNotApplicable,
+		BodySize:    harNotApplicable,
+	}
+}
+
+// harResponseFor returns the HAR response for a recorded response.
+func harResponseFor(response HARResponse) harResponse {
+	result := harResponse{
+		Status:      response.StatusCode,
+		StatusText:  strings.TrimSpace(strings.TrimPrefix(response.Status, strconv.Itoa(response.StatusCode))),
+		HTTPVersion: response.Proto,
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(response.Header),
+		RedirectURL: response.Header.Get("Location"),
+		Content: harContent{
+			MimeType: response.Header.Get("Content-Type"),
+			Size:     len(response.Body),
+		},
+		HeadersSize: harNotApplicable,
+		BodySize:    len(response.Body),
+	}
+
+	for _, cookie := range (&http.Response{Header: response.Header}).Cookies() {
+		result.Cookies = append(result.Cookies, harNameValue{Name: cookie.Name, Value: cookie.Value})
+	}
+
+	if utf8.Valid(response.Body) {
+		result.Content.Text = string(response.Body)
+	} else {
+		result.Content.Text = base64.StdEncoding.EncodeToString(response.Body)
+		result.Content.Encoding = "base64"
+	}
+
+	return result
+}
+
+// harTimingsFor returns the HAR timings for the timings of a recorded response.
+func harTimingsFor(timings HARTimings) harTimings {
+	// Phases that may not happen, send, wait and receive always do
+	optional := func(d time.Duration) float64 {
+		if d == 0 {
+			return harNotApplicable
+		}
+
+		return harMilliseconds(d)
+	}
+
+	return harTimings{
+		Blocked: optional(timings.Blocked),
+		DNS:     optional(timings.DNS),
+		Connect: optional(timings.Connect),
+		SSL:     optional(timings.TLS),
+		Send:    harMilliseconds(timings.Send),
+		Wait:    harMilliseconds(timings.Wait),
+		Receive: harMilliseconds(timings.Receive),
+	}
+}
+
+// harMilliseconds returns d as a number of milliseconds, as HAR timings are given.
+func harMilliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// harHeaders returns header as a list of HAR headers, sorted by key.
+func harHeaders(header http.Header) []harNameValue {
+	headers := make([]harNameValue, 0, len(header))
+
+	for _, key := range slices.Sorted(maps.Keys(header)) {
+		for _, value := range header[key] {
+			headers = append(headers, harNameValue{Name: key, Value: value})
+		}
+	}
+
+	return headers
+}
+
+// harHTTPVersion returns the HTTP version recorded in a HAR document as a .http file HTTP
+// version, browsers record these in different ways e.g. 'h2' or 'http/2.0'.
+//
+// HTTP/1.1 is the default so is left out, as are versions that cannot be written in a .http file.
+func harHTTPVersion(version string) string {
+	switch strings.ToLower(version) {
+	case "http/1.0":
+		return "HTTP/1.0"
+	case "h2", "http/2", "http/2.0":
+		return "HTTP/2"
+	case "h3", "http/3", "http/3.0":
+		return "HTTP/3"
+	default:
+		return ""
+	}
+}
+
+// harParams returns the parameters of a form body as a URL encoded body, and whether it
+// could, bodies with files can only be sent as multipart form data.
+func harParams(params []harParam) (string, bool) {
+	values := make([]string, 0, len(params))
+
+	for _, param := range params {
+		if param.FileName != "" {
+			return "", false
+		}
+
+		values = append(values, url.QueryEscape(param.Name)+"="+url.QueryEscape(param.Value))
+	}
+
+	return strings.Join(values, "&"), true
+}
@@ -0,0 +1,293 @@
+package format_test
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.followtheprocess.codes/snapshot"
+	"go.followtheprocess.codes/test"
+	"go.followtheprocess.codes/zap/internal/format"
+	"go.followtheprocess.codes/zap/internal/spec"
+)
+
+func TestHARExporter(t *testing.T) {
+	tests := []struct {
+		responses map[string]format.HARResponse // Recorded responses by request name
+		name      string                        // Name of the test case
+		file      spec.File                     // The HTTP file
+	}{
+		{
+			name: "simple",
+			file: spec.File{
+				Name: "simple",
+				Requests: []spec.Request{
+					{
+						Name:   "items",
+						Method: http.MethodGet,
+						URL:    spec.Text("https://api.nowhere.com/v1/items/1234"),
+					},
+				},
+			},
+		},
+		{
+			name: "with headers and query",
+			file: spec.File{
+				Name: "with headers and query",
+				Requests: []spec.Request{
+					{
+						Name:        "search",
+						Comment:     "Search the items",
+						Method:      http.MethodGet,
+						URL:         spec.Text("https://api.nowhere.com/v1/items?q=zap&page=2&q=http"),
+						HTTPVersion: "HTTP/2",
+						Headers: spec.Headers{
+							"Accept": {spec.Text("application/json"), spec.Text("application/xml")},
+							"Cookie": {spec.Text("session=abc123; theme=dark")},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "with bodies",
+			file: spec.File{
+				Name: "with bodies",
+				Requests: []spec.Request{
+					{
+						Name:   "create",
+						Method: http.MethodPost,
+						URL:    spec.Text("https://api.nowhere.com/v1/items"),
+						Headers: spec.Headers{
+							"Content-Type": {spec.Text("application/json")},
+						},
+						Body: spec.Text(`{"id": 1}`),
+					},
+					{
+						Name:     "upload",
+						Method:   http.MethodPut,
+						URL:      spec.Text("https://api.nowhere.com/v1/files"),
+						BodyFile: spec.Text("./data.bin"),
+					},
+				},
+			},
+		},
+		{
+			name: "with responses",
+			file: spec.File{
+				Name: "with responses",
+				Requests: []spec.Request{
+					{
+						Name:   "items",
+						Method: http.MethodGet,
+						URL:    spec.Text("https://api.nowhere.com/v1/items"),
+					},
+					{
+						Name:   "logo",
+						Method: http.MethodGet,
+						URL:    spec.Text("https://api.nowhere.com/logo.png"),
+					},
+					{
+						Name:   "not-sent",
+						Method: http.MethodGet,
+						URL:    spec.Text("https://api.nowhere.com/v1/other"),
+					},
+				},
+			},
+			responses: map[string]format.HARResponse{
+				"items": {
+					Started: time.Date(2026, time.March, 1, 10, 0, 0, 0, time.UTC),
+					Header: http.Header{
+						"Content-Type": []string{"application/json"},
+						"Set-Cookie":   []string{"session=abc123; Path=/"},
+					},
+					Status:     "200 OK",
+					Proto:      "HTTP/1.1",
+					Body:       []byte(`{"items": []}`),
+					StatusCode: http.StatusOK,
+					Timings: format.HARTimings{
+						DNS:     2 * time.Millisecond,
+						Connect: 5 * time.Millisecond,
+						TLS:     3 * time.Millisecond,
+						Send:    250 * time.Microsecond,
+						Wait:    40 * time.Millisecond,
+						Receive: 1500 * time.Microsecond,
+					},
+				},
+				"logo": {
+					Started: time.Date(2026, time.March, 1, 10, 0, 1, 0, time.UTC),
+					Header: http.Header{
+						"Content-Type": []string{"image/png"},
+					},
+					Status:     "200 OK",
+					Proto:      "HTTP/1.1",
+					Body:       []byte{0x89, 'P', 'N', 'G', 0xff},
+					StatusCode: http.StatusOK,
+					Timings: format.HARTimings{
+						Blocked: time.Millisecond,
+						Wait:    10 * time.Millisecond,
+						Receive: 2 * time.Millisecond,
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snap := snapshot.New(
+				t,
+				snapshot.Update(*update),
+				snapshot.Clean(*clean),
+				snapshot.Color(os.Getenv("CI") == ""),
+			)
+			exporter := format.HARExporter{Responses: tt.responses, Version: "test"}
+			buf := &bytes.Buffer{}
+			test.Ok(t, exporter.Export(buf, tt.file))
+
+			snap.Snap(buf.String())
+		})
+	}
+}
+
+func TestHARImporter(t *testing.T) {
+	pattern := filepath.Join("testdata", "har", "*.har")
+	files, err := filepath.Glob(pattern)
+	test.Ok(t, err)
+
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".har")
+		t.Run(name, func(t *testing.T) {
+			snap := snapshot.New(
+				t,
+				snapshot.Update(*update),
+				snapshot.Clean(*clean),
+				snapshot.Color(os.Getenv("CI") == ""),
+			)
+
+			f, err := os.Open(file)
+			test.Ok(t, err)
+			defer f.Close()
+
+			var warnings []string
+
+			importer := format.HARImporter{
+				Warn: func(msg string) {
+					warnings = append(warnings, msg)
+				},
+			}
+
+			imported, err := importer.Import(f)
+			test.Ok(t, err)
+
+			snap.Snap(imported.String() + "\n# Warnings\n\n" + strings.Join(warnings, "\n") + "\n")
+		})
+	}
+}
+
+func TestHARImporterDedupe(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "har", "devtools.har"))
+	test.Ok(t, err)
+	defer f.Close()
+
+	imported, err := format.HARImporter{Dedupe: true}.Import(f)
+	test.Ok(t, err)
+
+	urls := make([]string, 0, len(imported.Requests))
+	for _, request := range imported.Requests {
+		urls = append(urls, request.Method+" "+request.URL.String())
+	}
+
+	want := []string{
+		"GET https://shop.nowhere.com/",
+		"GET https://shop.nowhere.com/static/app.js",
+		"GET https://shop.nowhere.com/static/logo.png",
+		"POST https://shop.nowhere.com/api/cart",
+		"POST https://shop.nowhere.com/login",
+		"POST https://shop.nowhere.com/api/avatar",
+	}
+
+	test.Diff(t, strings.Join(urls, "\n"), strings.Join(want, "\n"))
+}
+
+func TestHARImporterInvalid(t *testing.T) {
+	tests := []struct {
+		name   string // Name of the test case
+		src    string // The document to import
+		errMsg string // The expected error message
+	}{
+		{
+			name:   "not JSON",
+			src:    "nope",
+			errMsg: "could not decode HAR archive: invalid character 'o' in literal null (expecting 'u')",
+		},
+		{
+			name:   "no log",
+			src:    `{"info": {}}`,
+			errMsg: `not a HAR archive, no "log" found`,
+		},
+		{
+			name:   "no method",
+			src:    `{"log": {"version": "1.2", "entries": [{"request": {"url": "https://api.nowhere.com"}}]}}`,
+			errMsg: "entry 1: request has no method",
+		},
+		{
+			name:   "no url",
+			src:    `{"log": {"version": "1.2", "entries": [{"request": {"method": "GET"}}]}}`,
+			errMsg: "entry 1: request has no url",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := format.HARImporter{}.Import(strings.NewReader(tt.src))
+			test.Err(t, err)
+			test.Equal(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestHARRoundTrip(t *testing.T) {
+	file := spec.File{
+		Name: "round-trip",
+		Requests: []spec.Request{
+			{
+				Name:    "items",
+				Comment: "List the items",
+				Method:  http.MethodGet,
+				URL:     spec.Text("https://api.nowhere.com/v1/items?page=1"),
+				Headers: spec.Headers{
+					"Accept": {spec.Text("application/json")},
+				},
+			},
+			{
+				Name:        "create",
+				Method:      http.MethodPost,
+				URL:         spec.Text("https://api.nowhere.com/v1/items"),
+				HTTPVersion: "HTTP/2",
+				Headers: spec.Headers{
+					"Content-Type": {spec.Text("application/json")},
+				},
+				Body: spec.Text(`{"id": 1}`),
+			},
+			{
+				Name:     "upload",
+				Method:   http.MethodPut,
+				URL:      spec.Text("https://api.nowhere.com/v1/files"),
+				BodyFile: spec.Text("./data.bin"),
+			},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	test.Ok(t, format.HARExporter{}.Export(buf, file))
+
+	imported, err := format.HARImporter{}.Import(buf)
+	test.Ok(t, err)
+
+	test.Diff(t, imported.String(), file.String())
+}
@@ -20,6 +20,12 @@ func (j JSONExporter) Export(w io.Writer, file spec.File) error {
 	return encoder.Encode(file)
 }
 
+// Builtin implements [BuiltinExporter] for [JSONExporter], dynamic builtins are exported
+// as they are written in the .http file.
+func (j JSONExporter) Builtin(name string, args ...string) (string, bool) {
+	return zapBuiltin(name, args...), true
+}
+
 // JSONImporter is an [Importer] that transforms JSON representations of
 // .http files into the equivalent [spec.File].
 type JSONImporter struct{}
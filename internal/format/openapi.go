@@ -0,0 +1,1029 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.followtheprocess.codes/zap/internal/spec"
+	"go.yaml.in/yaml/v4"
+)
+
+const (
+	// openapiDefaultTag is the tag of operations that have none, when splitting by tag.
+	openapiDefaultTag = "default"
+
+	// openapiMaxDepth is how deeply nested a generated example body may be, so that
+	// recursive schemas e.g. a tree of nodes come to an end.
+	openapiMaxDepth = 8
+
+	// openapiDefaultBase is the base URL used when a document has no servers.
+	openapiDefaultBase = "http://localhost"
+)
+
+// openapiPathParameter matches a parameter in an OpenAPI path template e.g. '{id}'.
+//
+//nolint:gochecknoglobals // Compiled once
+var openapiPathParameter = regexp.MustCompile(`{([^{}]+)}`)
+
+// OpenAPIImporter is an [Importer] that generates a .http file from an OpenAPI 3 document,
+// in YAML or JSON.
+//
+// Each operation becomes a request named from its operationId, with the URL of the first
+// server as the '@base' variable. Path parameters, and required query and header parameters,
+// become request variables set to their example if they have one or prompts if not. Request
+// bodies are the example given in the document, or one generated from the body's schema.
+//
+// Security schemes become an Authorization header (or API key) set from a secret prompt.
+type OpenAPIImporter struct {
+	// Warn, if not nil, is called with a description of everything in the document that
+	// could not be imported.
+	Warn func(msg string)
+}
+
+// Import implements [Importer] for [OpenAPIImporter].
+func (o OpenAPIImporter) Import(r io.Reader) (spec.File, error) {
+	imported, err := o.load(r)
+	if err != nil {
+		return spec.File{}, err
+	}
+
+	return imported.file(imported.name, imported.requests), nil
+}
+
+// ImportByTag is like Import, but splits the operations into a [spec.File] for each tag, by
+// tag. Operations with more than one tag go in the file for the first, those without any in
+// one for the "default" tag.
+//
+// Each file is named after its tag, unique amongst the files, and has the same global variables
+// and only the prompts its requests use.
+func (o OpenAPIImporter) ImportByTag(r io.Reader) (map[string]spec.File, error) {
+	imported, err := o.load(r)
+	if err != nil {
+		return nil, err
+	}
+
+	tagged := make(map[string][]openapiRequest)
+	for _, request := range imported.requests {
+		tagged[request.tag] = append(tagged[request.tag], request)
+	}
+
+	files := make(map[string]spec.File, len(tagged))
+	names := make(map[string]bool, len(tagged))
+
+	// Sorted so tags with the same name e.g. 'Pets' and 'pets' are numbered consistently
+	for _, tag := range slices.Sorted(maps.Keys(tagged)) {
+		base := identifier(tag)
+		if base == "" || keyword(base) {
+			base = openapiDefaultTag
+		}
+
+		name := base
+		for i := 2; names[name]; i++ {
+			name = base + "-" + strconv.Itoa(i)
+		}
+
+		names[name] = true
+		files[tag] = imported.file(name, tagged[tag])
+	}
+
+	return files, nil
+}
+
+// load decodes the OpenAPI document in r and imports its operations.
+func (o OpenAPIImporter) load(r io.Reader) (*openapiImport, error) {
+	var document openapiDocument
+	if err := yaml.NewDecoder(r).Decode(&document); err != nil {
+		return nil, fmt.Errorf("could not decode OpenAPI document: %w", err)
+	}
+
+	switch {
+	case document.Swagger != "":
+		return nil, fmt.Errorf("only OpenAPI 3 documents can be imported, got Swagger %s", document.Swagger)
+	case document.OpenAPI == "":
+		return nil, fmt.Errorf("not an OpenAPI document, no %q version found", "openapi")
+	case !strings.HasPrefix(document.OpenAPI, "3."):
+		return nil, fmt.Errorf("only OpenAPI 3 documents can be imported, got OpenAPI %s", document.OpenAPI)
+	}
+
+	imported := &openapiImport{
+		warn:       o.Warn,
+		components: document.Components,
+		names:      make(map[string]bool),
+		prompts:    make(map[string]spec.Prompt),
+		base:       openapiBase(document.Servers),
+	}
+
+	name := identifier(document.Info.Title)
+	if !keyword(name) {
+		imported.name = name
+	}
+
+	if len(document.Servers) == 0 {
+		imported.warnf("no servers, using %s as the base URL", openapiDefaultBase)
+	}
+
+	for _, path := range document.Paths {
+		for _, operation := range path.item.operations() {
+			imported.operation(path.path, path.item, operation, document.Security)
+		}
+	}
+
+	return imported, nil
+}
+
+// openapiImport is the state of an import from an OpenAPI document.
+type openapiImport struct {
+	warn       func(msg string)       // Called with anything that could not be imported
+	prompts    map[string]spec.Prompt // The global prompts for security schemes, by name
+	names      map[string]bool        // The names of the requests imported so far
+	components openapiComponents      // The reusable parts of the document
+	name       string                 // The name of the document
+	base       string                 // The base URL of the API
+	requests   []openapiRequest       // The imported requests
+}
+
+// openapiRequest is a request imported from an operation.
+type openapiRequest struct {
+	tag     string       // The first tag of the operation
+	prompts []string     // The names of the global prompts the request uses
+	request spec.Request // The request
+}
+
+// openapiBase returns the base URL of the API from the first of servers, with any server
+// variables set to their default.
+func openapiBase(servers []openapiServer) string {
+	if len(servers) == 0 {
+		return openapiDefaultBase
+	}
+
+	server := servers[0]
+
+	base := openapiPathParameter.ReplaceAllStringFunc(server.URL, func(match string) string {
+		variable, ok := server.Variables[strings.Trim(match, "{}")]
+		if !ok {
+			return match
+		}
+
+		return variable.Default
+	})
+
+	return strings.TrimSuffix(base, "/")
+}
+
+// file returns a [spec.File] named name with the given requests.
+func (o *openapiImport) file(name string, requests []openapiRequest) spec.File {
+	file := spec.File{
+		Name: name,
+		Vars: map[string]spec.Template{
+			"base": spec.Text(o.base),
+		},
+		Requests: make([]spec.Request, 0, len(requests)),
+	}
+
+	for _, request := range requests {
+		for _, prompt := range request.prompts {
+			if file.Prompts == nil {
+				file.Prompts = make(map[string]spec.Prompt)
+			}
+
+			file.Prompts[prompt] = o.prompts[prompt]
+		}
+
+		file.Requests = append(file.Requests, request.request)
+	}
+
+	return file
+}
+
+// warnf calls o.warn with a formatted message, if it's set.
+func (o *openapiImport) warnf(format string, a ...any) {
+	if o.warn != nil {
+		o.warn(fmt.Sprintf(format, a...))
+	}
+}
+
+// operation imports a single operation on path, with the security requirements of the
+// document if the operation doesn't have its own.
+func (o *openapiImport) operation(path string, item openapiPathItem, operation openapiOperation, security []map[string][]string) {
+	title := operation.method + " " + path
+
+	request := spec.Request{
+		Name:    o.requestName(operation.OperationID, title),
+		Comment: operation.Summary,
+		Method:  operation.method,
+		Headers: make(spec.Headers),
+		Vars:    make(map[string]spec.Template),
+	}
+
+	if request.Comment == "" {
+		request.Comment, _, _ = strings.Cut(strings.TrimSpace(operation.Description), "\n")
+	}
+
+	// Parameters of the operation override those of the path with the same name and location
+	parameters := make(map[string]openapiParameter)
+	var order []string
+
+	for _, ref := range slices.Concat(item.Parameters, operation.Parameters) {
+		parameter, ok := o.parameter(title, ref)
+		if !ok {
+			continue
+		}
+
+		key := parameter.In + " " + parameter.Name
+		if _, seen := parameters[key]; !seen {
+			order = append(order, key)
+		}
+
+		parameters[key] = parameter
+	}
+
+	replacements := make(map[string]string)
+	var query []string
+
+	for _, key := range order {
+		parameter := parameters[key]
+
+		if !parameter.Required && parameter.In != "path" && parameter.example() == nil {
+			// Optional parameters are only included when there's an example to show
+			continue
+		}
+
+		placeholder := "{{" + o.placeholder(&request, parameter) + "}}"
+
+		switch parameter.In {
+		case "path":
+			replacements[parameter.Name] = placeholder
+		case "query":
+			query = append(query, url.QueryEscape(parameter.Name)+"="+placeholder)
+		case "header":
+			request.Headers.Add(parameter.Name, spec.Text(placeholder))
+		case "cookie":
+			request.Headers.Add("Cookie", spec.Text(parameter.Name+"="+placeholder))
+		default:
+			o.warnf("%s: parameter %s in %q is not supported and was ignored", title, parameter.Name, parameter.In)
+		}
+	}
+
+	target := "{{base}}" + openapiPathParameter.ReplaceAllStringFunc(path, func(match string) string {
+		if replacement, ok := replacements[strings.Trim(match, "{}")]; ok {
+			return replacement
+		}
+
+		return match
+	})
+
+	if len(query) != 0 {
+		target += "?" + strings.Join(query, "&")
+	}
+
+	request.URL = spec.Text(target)
+
+	if operation.Security != nil {
+		security = *operation.Security
+	}
+
+	prompts := o.security(title, &request, security)
+
+	if operation.RequestBody != nil {
+		o.body(title, &request, *operation.RequestBody)
+	}
+
+	if len(request.Headers) == 0 {
+		request.Headers = nil
+	}
+
+	if len(request.Vars) == 0 {
+		request.Vars = nil
+	}
+
+	tag := openapiDefaultTag
+	if len(operation.Tags) != 0 {
+		tag = operation.Tags[0]
+	}
+
+	o.requests = append(o.requests, openapiRequest{tag: tag, prompts: prompts, request: request})
+}
+
+// requestName returns a unique name for the request made by the operation with the given
+// operationId, or from its title if it has none e.g. 'get-users-id' for 'GET /users/{id}'.
+func (o *openapiImport) requestName(operationID, title string) string {
+	base := operationID
+	if !identifierPattern.MatchString(base) || keyword(base) {
+		base = identifier(base)
+	}
+
+	if base == "" || keyword(base) {
+		base = identifier(title)
+	}
+
+	unique := base
+	for i := 2; o.names[unique]; i++ {
+		unique = base + "-" + strconv.Itoa(i)
+	}
+
+	o.names[unique] = true
+
+	return unique
+}
+
+// placeholder adds a request variable set to the parameter's example to request, or a
+// prompt for it if it has none, and returns its name.
+func (o *openapiImport) placeholder(request *spec.Request, parameter openapiParameter) string {
+	name := parameter.Name
+	if !identifierPattern.MatchString(name) {
+		name = identifier(name)
+	}
+
+	if name == "" || keyword(name) {
+		name = identifier(parameter.In + "-" + parameter.Name)
+	}
+
+	if example := parameter.example(); example != nil {
+		request.Vars[name] = spec.Text(openapiText(example))
+		return name
+	}
+
+	prompt := spec.Prompt{
+		Name:        name,
+		Description: strings.Join(strings.Fields(parameter.Description), " "),
+	}
+
+	if schema := o.schema(parameter.Schema, nil); schema != nil {
+		prompt.Type = schema.promptType()
+
+		for _, choice := range schema.Enum {
+			prompt.Choices = append(prompt.Choices, openapiText(choice))
+		}
+
+		if schema.Default != nil {
+			prompt.Default = openapiText(schema.Default)
+		}
+	}
+
+	if request.Prompts == nil {
+		request.Prompts = make(map[string]spec.Prompt)
+	}
+
+	request.Prompts[name] = prompt
+
+	return name
+}
+
+// security sets the credentials required by the first of the security requirements on
+// request, returning the names of the global prompts they come from.
+func (o *openapiImport) security(title string, request *spec.Request, security []map[string][]string) []string {
+	if len(security) == 0 {
+		return nil
+	}
+
+	var prompts []string
+
+	// Any one of the requirements will do, so the first is used
+	for _, name := range slices.Sorted(maps.Keys(security[0])) {
+		scheme, ok := o.components.SecuritySchemes[name]
+		if !ok {
+			o.warnf("%s: security scheme %s is not defined and was ignored", title, name)
+			continue
+		}
+
+		switch {
+		case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic"):
+			request.Headers.Set("Authorization", spec.Text(`Basic {{ $base64(username + ":" + password) }}`))
+
+			prompts = append(prompts, o.prompt("username", "", false), o.prompt("password", "", true))
+		case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "bearer"),
+			scheme.Type == "oauth2",
+			scheme.Type == "openIdConnect":
+			request.Headers.Set("Authorization", spec.Text("Bearer {{token}}"))
+
+			prompts = append(prompts, o.prompt("token", scheme.Description, true))
+		case scheme.Type == "apiKey":
+			variable := identifier(name)
+			if variable == "" || keyword(variable) {
+				variable = "api-key"
+			}
+
+			switch scheme.In {
+			case "header":
+				request.Headers.Set(scheme.Name, spec.Text("{{"+variable+"}}"))
+			case "query":
+				separator := "?"
+				if strings.Contains(request.URL.String(), "?") {
+					separator = "&"
+				}
+
+				request.URL = request.URL.Concat(spec.Text(separator + url.QueryEscape(scheme.Name) + "={{" + variable + "}}"))
+			case "cookie":
+				request.Headers.Add("Cookie", spec.Text(scheme.Name+"={{"+variable+"}}"))
+			default:
+				o.warnf("%s: API key in %q is not supported and was ignored", title, scheme.In)
+				continue
+			}
+
+			prompts = append(prompts, o.prompt(variable, scheme.Description, true))
+		default:
+			o.warnf("%s: security scheme %s of type %s is not supported and was ignored", title, name, scheme.Type)
+		}
+	}
+
+	return prompts
+}
+
+// prompt adds a global prompt for a credential called name, returning its name.
+func (o *openapiImport) prompt(name, description string, secret bool) string {
+	if _, ok := o.prompts[name]; !ok {
+		o.prompts[name] = spec.Prompt{
+			Name:        name,
+			Description: strings.Join(strings.Fields(description), " "),
+			Secret:      secret,
+		}
+	}
+
+	return name
+}
+
+// body sets the body of request to an example of the given request body, preferring JSON
+// if there's a choice of media types.
+func (o *openapiImport) body(title string, request *spec.Request, ref openapiRequestBody) {
+	body, ok := o.requestBody(title, ref)
+	if !ok || len(body.Content) == 0 {
+		return
+	}
+
+	mediaType := openapiMediaType(slices.Sorted(maps.Keys(body.Content)))
+	media := body.Content[mediaType]
+
+	example := o.mediaExample(title, media)
+	if example == nil {
+		o.warnf("%s: %s body has no example or schema and was left out", title, mediaType)
+		return
+	}
+
+	switch {
+	case openapiJSON(mediaType):
+		if text, ok := example.(string); ok && json.Valid([]byte(text)) {
+			request.Body = spec.Text(text)
+			break
+		}
+
+		buf := &bytes.Buffer{}
+		encoder := json.NewEncoder(buf)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+
+		if err := encoder.Encode(example); err != nil {
+			o.warnf("%s: could not generate a %s body: %v", title, mediaType, err)
+			return
+		}
+
+		request.Body = spec.Text(strings.TrimSpace(buf.String()))
+	case mediaType == "application/x-www-form-urlencoded":
+		fields, ok := example.(openapiObject)
+		if !ok {
+			o.warnf("%s: could not generate a %s body from a non object example", title, mediaType)
+			return
+		}
+
+		values := make([]string, 0, len(fields))
+		for _, field := range fields {
+			values = append(values, url.QueryEscape(field.key)+"="+url.QueryEscape(openapiText(field.value)))
+		}
+
+		request.Body = spec.Text(strings.Join(values, "&"))
+	default:
+		text, ok := example.(string)
+		if !ok {
+			o.warnf("%s: %s bodies are not supported and were left out", title, mediaType)
+			return
+		}
+
+		request.Body = spec.Text(text)
+	}
+
+	request.Headers.Set("Content-Type", spec.Text(mediaType))
+}
+
+// requestBody resolves a request body that may be a reference to a reusable one.
+func (o *openapiImport) requestBody(title string, body openapiRequestBody) (openapiRequestBody, bool) {
+	if body.Ref == "" {
+		return body, true
+	}
+
+	resolved, ok := o.components.RequestBodies[strings.TrimPrefix(body.Ref, "#/components/requestBodies/")]
+	if !ok {
+		o.warnf("%s: request body %s could not be resolved", title, body.Ref)
+		return openapiRequestBody{}, false
+	}
+
+	return resolved, true
+}
+
+// parameter resolves a parameter that may be a reference to a reusable one.
+func (o *openapiImport) parameter(title string, parameter openapiParameter) (openapiParameter, bool) {
+	if parameter.Ref == "" {
+		return parameter, true
+	}
+
+	resolved, ok := o.components.Parameters[strings.TrimPrefix(parameter.Ref, "#/components/parameters/")]
+	if !ok {
+		o.warnf("%s: parameter %s could not be resolved and was ignored", title, parameter.Ref)
+		return openapiParameter{}, false
+	}
+
+	return resolved, true
+}
+
+// schema resolves a schema that may be a reference to one in the document's components,
+// returning nil if it can't be or if it's one of those in seen, which are the references
+// already being resolved.
+func (o *openapiImport) schema(schema *openapiSchema, seen []string) *openapiSchema {
+	if schema == nil || schema.Ref == "" {
+		return schema
+	}
+
+	if slices.Contains(seen, schema.Ref) {
+		// Recursive
+		return nil
+	}
+
+	resolved, ok := o.components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	if !ok {
+		o.warnf("schema %s could not be resolved", schema.Ref)
+		return nil
+	}
+
+	return o.schema(resolved, append(seen, schema.Ref))
+}
+
+// mediaExample returns the example of a request body of the given media, the first of its
+// examples, or one generated from its schema.
+func (o *openapiImport) mediaExample(title string, media openapiMedia) any {
+	if media.Example != nil {
+		return media.Example
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(media.Examples)) {
+		example := media.Examples[name]
+		if example.Ref != "" {
+			resolved, ok := o.components.Examples[strings.TrimPrefix(example.Ref, "#/components/examples/")]
+			if !ok {
+				o.warnf("%s: example %s could not be resolved", title, example.Ref)
+				continue
+			}
+
+			example = resolved
+		}
+
+		if example.Value != nil {
+			return example.Value
+		}
+	}
+
+	return o.sample(media.Schema, nil)
+}
+
+// sample generates an example value of the given schema, preferring any example, default or
+// enum it has. Strings with a well known format use the equivalent builtin e.g. '$uuid'.
+//
+// seen are the references already being resolved, to stop recursive schemas.
+func (o *openapiImport) sample(ref *openapiSchema, seen []string) any {
+	schema := o.schema(ref, seen)
+	if schema == nil || len(seen) > openapiMaxDepth {
+		return nil
+	}
+
+	if ref.Ref != "" {
+		seen = append(seen, ref.Ref)
+	}
+
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case len(schema.Examples) != 0:
+		return schema.Examples[0]
+	case schema.Const != nil:
+		return schema.Const
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) != 0:
+		return schema.Enum[0]
+	case len(schema.AllOf) != 0:
+		var merged openapiObject
+
+		for _, part := range schema.AllOf {
+			if object, ok := o.sample(part, seen).(openapiObject); ok {
+				merged = append(merged, object...)
+			}
+		}
+
+		return merged
+	case len(schema.OneOf) != 0:
+		return o.sample(schema.OneOf[0], seen)
+	case len(schema.AnyOf) != 0:
+		return o.sample(schema.AnyOf[0], seen)
+	}
+
+	switch schema.Type {
+	case "object", "":
+		if schema.Type == "" && len(schema.Properties) == 0 {
+			return nil
+		}
+
+		object := make(openapiObject, 0, len(schema.Properties))
+		for _, property := range schema.Properties {
+			object = append(object, openapiField{key: property.name, value: o.sample(property.schema, seen)})
+		}
+
+		return object
+	case "array":
+		item := o.sample(schema.Items, seen)
+		if item == nil {
+			return []any{}
+		}
+
+		return []any{item}
+	case "integer", "number":
+		if schema.Minimum != nil {
+			return *schema.Minimum
+		}
+
+		return 0
+	case "boolean":
+		return true
+	case "string":
+		switch schema.Format {
+		case "uuid":
+			return "{{ $uuid }}"
+		case "email":
+			return "{{ $random.email }}"
+		case "date-time":
+			return "{{ $isoTimestamp }}"
+		case "date":
+			return "2024-01-01"
+		case "uri", "url":
+			return "https://example.com"
+		default:
+			return "string"
+		}
+	default:
+		return nil
+	}
+}
+
+// openapiText returns a scalar value from an OpenAPI document as text.
+func openapiText(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case nil:
+		return ""
+	default:
+		text, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+
+		return string(text)
+	}
+}
+
+// openapiJSON reports whether mediaType is JSON e.g. 'application/json' or
+// 'application/problem+json'.
+func openapiJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// openapiMediaType returns the media type to use for a request body from the sorted types
+// it may be, preferring JSON.
+func openapiMediaType(mediaTypes []string) string {
+	if slices.Contains(mediaTypes, "application/json") {
+		return "application/json"
+	}
+
+	for _, mediaType := range mediaTypes {
+		if openapiJSON(mediaType) {
+			return mediaType
+		}
+	}
+
+	if slices.Contains(mediaTypes, "application/x-www-form-urlencoded") {
+		return "application/x-www-form-urlencoded"
+	}
+
+	return mediaTypes[0]
+}
+
+// openapiObject is an example object generated from a schema, its fields are kept in the
+// order of the schema's properties.
+type openapiObject []openapiField
+
+// openapiField is a field in an [openapiObject].
+type openapiField struct {
+	value any    // The field's value
+	key   string // The field's name
+}
+
+// MarshalJSON implements [json.Marshaler] for an [openapiObject], keeping its fields in order.
+func (o openapiObject) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+
+	for i, field := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(field.key)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// openapiDocument is an OpenAPI 3 document.
+//
+// See https://spec.openapis.org/oas/v3.1.0.
+type openapiDocument struct {
+	Components openapiComponents     `yaml:"components"`
+	Info       openapiInfo           `yaml:"info"`
+	OpenAPI    string                `yaml:"openapi"`
+	Swagger    string                `yaml:"swagger"` // Set in Swagger (OpenAPI 2) documents
+	Servers    []openapiServer       `yaml:"servers"`
+	Paths      openapiPaths          `yaml:"paths"`
+	Security   []map[string][]string `yaml:"security"`
+}
+
+// openapiInfo is the metadata of an OpenAPI document.
+type openapiInfo struct {
+	Title string `yaml:"title"`
+}
+
+// openapiServer is a server hosting the API described by an OpenAPI document.
+type openapiServer struct {
+	Variables map[string]openapiServerVariable `yaml:"variables"`
+	URL       string                           `yaml:"url"`
+}
+
+// openapiServerVariable is a variable in a server's URL e.g. '{region}'.
+type openapiServerVariable struct {
+	Default string `yaml:"default"`
+}
+
+// openapiComponents are the reusable parts of an OpenAPI document, by name.
+type openapiComponents struct {
+	Schemas         map[string]*openapiSchema        `yaml:"schemas"`
+	Parameters      map[string]openapiParameter      `yaml:"parameters"`
+	RequestBodies   map[string]openapiRequestBody    `yaml:"requestBodies"`
+	Examples        map[string]openapiExample        `yaml:"examples"`
+	SecuritySchemes map[string]openapiSecurityScheme `yaml:"securitySchemes"`
+}
+
+// openapiPaths are the paths in an OpenAPI document, kept in the order they're written.
+type openapiPaths []openapiPath
+
+// openapiPath is a path and the operations on it.
+type openapiPath struct {
+	path string          // The path e.g. '/users/{id}'
+	item openapiPathItem // The operations on the path
+}
+
+// UnmarshalYAML implements [yaml.Unmarshaler] for [openapiPaths].
+func (o *openapiPaths) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return errors.New("paths must be an object")
+	}
+
+	const pair = 2 // Mapping nodes alternate between keys and values
+
+	for i := 0; i+1 < len(node.Content); i += pair {
+		var item openapiPathItem
+		if err := node.Content[i+1].Decode(&item); err != nil {
+			return err
+		}
+
+		*o = append(*o, openapiPath{path: node.Content[i].Value, item: item})
+	}
+
+	return nil
+}
+
+// openapiPathItem is the operations on a path in an OpenAPI document.
+type openapiPathItem struct {
+	Get        *openapiOperation  `yaml:"get"`
+	Put        *openapiOperation  `yaml:"put"`
+	Post       *openapiOperation  `yaml:"post"`
+	Delete     *openapiOperation  `yaml:"delete"`
+	Options    *openapiOperation  `yaml:"options"`
+	Head       *openapiOperation  `yaml:"head"`
+	Patch      *openapiOperation  `yaml:"patch"`
+	Trace      *openapiOperation  `yaml:"trace"`
+	Parameters []openapiParameter `yaml:"parameters"` // Parameters of every operation on the path
+}
+
+// operations returns the operations on the path in the order they're listed in the
+// specification, with their method set.
+func (o openapiPathItem) operations() []openapiOperation {
+	var operations []openapiOperation
+
+	for _, operation := range []struct {
+		operation *openapiOperation
+		method    string
+	}{
+		{operation: o.Get, method: http.MethodGet},
+		{operation: o.Put, method: http.MethodPut},
+		{operation: o.Post, method: http.MethodPost},
+		{operation: o.Delete, method: http.MethodDelete},
+		{operation: o.Options, method: http.MethodOptions},
+		{operation: o.Head, method: http.MethodHead},
+		{operation: o.Patch, method: http.MethodPatch},
+		{operation: o.Trace, method: http.MethodTrace},
+	} {
+		if operation.operation != nil {
+			op := *operation.operation
+			op.method = operation.method
+			operations = append(operations, op)
+		}
+	}
+
+	return operations
+}
+
+// openapiOperation is a single operation on a path in an OpenAPI document.
+type openapiOperation struct {
+	RequestBody *openapiRequestBody    `yaml:"requestBody"`
+	Security    *[]map[string][]string `yaml:"security"` // nil means the document's, empty means none
+	OperationID string                 `yaml:"operationId"`
+	Summary     string                 `yaml:"summary"`
+	Description string                 `yaml:"description"`
+	method      string                 // The HTTP method of the operation
+	Tags        []string               `yaml:"tags"`
+	Parameters  []openapiParameter     `yaml:"parameters"`
+}
+
+// openapiParameter is a parameter of an operation, or a reference to one.
+type openapiParameter struct {
+	Schema      *openapiSchema            `yaml:"schema"`
+	Example     any                       `yaml:"example"`
+	Examples    map[string]openapiExample `yaml:"examples"`
+	Ref         string                    `yaml:"$ref"`
+	Name        string                    `yaml:"name"`
+	In          string                    `yaml:"in"` // One of path, query, header or cookie
+	Description string                    `yaml:"description"`
+	Required    bool                      `yaml:"required"`
+}
+
+// example returns the example value of the parameter, if it has one.
+func (o openapiParameter) example() any {
+	if o.Example != nil {
+		return o.Example
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(o.Examples)) {
+		if value := o.Examples[name].Value; value != nil {
+			return value
+		}
+	}
+
+	if o.Schema != nil && o.Schema.Example != nil {
+		return o.Schema.Example
+	}
+
+	return nil
+}
+
+// openapiRequestBody is the body of an operation, or a reference to one.
+type openapiRequestBody struct {
+	Content map[string]openapiMedia `yaml:"content"` // By media type
+	Ref     string                  `yaml:"$ref"`
+}
+
+// openapiMedia is a body of a particular media type.
+type openapiMedia struct {
+	Schema   *openapiSchema            `yaml:"schema"`
+	Example  any                       `yaml:"example"`
+	Examples map[string]openapiExample `yaml:"examples"`
+}
+
+// openapiExample is a named example, or a reference to one.
+type openapiExample struct {
+	Value any    `yaml:"value"`
+	Ref   string `yaml:"$ref"`
+}
+
+// openapiSecurityScheme is a way of authenticating with the API.
+type openapiSecurityScheme struct {
+	Type        string `yaml:"type"`   // E.g. http or apiKey
+	Scheme      string `yaml:"scheme"` // The HTTP auth scheme e.g. bearer
+	Name        string `yaml:"name"`   // Name of the API key header, query parameter or cookie
+	In          string `yaml:"in"`     // Where the API key goes
+	Description string `yaml:"description"`
+}
+
+// openapiSchema is a JSON schema, or a reference to one.
+type openapiSchema struct {
+	Items      *openapiSchema    `yaml:"items"`
+	Minimum    *float64          `yaml:"minimum"`
+	Example    any               `yaml:"example"`
+	Const      any               `yaml:"const"`
+	Default    any               `yaml:"default"`
+	Ref        string            `yaml:"$ref"`
+	Type       openapiType       `yaml:"type"`
+	Format     string            `yaml:"format"`
+	Properties openapiProperties `yaml:"properties"`
+	Examples   []any             `yaml:"examples"`
+	Enum       []any             `yaml:"enum"`
+	AllOf      []*openapiSchema  `yaml:"allOf"`
+	OneOf      []*openapiSchema  `yaml:"oneOf"`
+	AnyOf      []*openapiSchema  `yaml:"anyOf"`
+}
+
+// promptType returns the type of prompt for a value of the schema, empty for any string.
+func (o *openapiSchema) promptType() string {
+	switch {
+	case o.Type == "integer":
+		return "int"
+	case o.Type == "number":
+		return "float"
+	case o.Type == "boolean":
+		return "bool"
+	case o.Format == "uuid":
+		return "uuid"
+	case o.Format == "uri" || o.Format == "url":
+		return "url"
+	default:
+		return ""
+	}
+}
+
+// openapiType is the type of a schema, which since OpenAPI 3.1 may be a list of types
+// e.g. '[string, "null"]' in which case the first that isn't null is used.
+type openapiType string
+
+// UnmarshalYAML implements [yaml.Unmarshaler] for [openapiType].
+func (o *openapiType) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.SequenceNode {
+		*o = openapiType(node.Value)
+		return nil
+	}
+
+	for _, item := range node.Content {
+		if item.Value != "null" {
+			*o = openapiType(item.Value)
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// openapiProperties are the properties of an object schema, kept in the order they're written.
+type openapiProperties []openapiProperty
+
+// openapiProperty is a named property of an object schema.
+type openapiProperty struct {
+	schema *openapiSchema // The schema of the property's value
+	name   string         // The name of the property
+}
+
+// UnmarshalYAML implements [yaml.Unmarshaler] for [openapiProperties].
+func (o *openapiProperties) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return errors.New("properties must be an object")
+	}
+
+	const pair = 2 // Mapping nodes alternate between keys and values
+
+	for i := 0; i+1 < len(node.Content); i += pair {
+		schema := &openapiSchema{}
+		if err := node.Content[i+1].Decode(schema); err != nil {
+			return err
+		}
+
+		*o = append(*o, openapiProperty{schema: schema, name: node.Content[i].Value})
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package format_test
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.followtheprocess.codes/snapshot"
+	"go.followtheprocess.codes/test"
+	"go.followtheprocess.codes/zap/internal/format"
+)
+
+func TestOpenAPIImporter(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "openapi", "*"))
+	test.Ok(t, err)
+
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		t.Run(name, func(t *testing.T) {
+			snap := snapshot.New(
+				t,
+				snapshot.Update(*update),
+				snapshot.Clean(*clean),
+				snapshot.Color(os.Getenv("CI") == ""),
+			)
+
+			f, err := os.Open(file)
+			test.Ok(t, err)
+			defer f.Close()
+
+			var warnings []string
+
+			importer := format.OpenAPIImporter{
+				Warn: func(msg string) {
+					warnings = append(warnings, msg)
+				},
+			}
+
+			imported, err := importer.Import(f)
+			test.Ok(t, err)
+
+			snap.Snap(imported.String() + "\n# Warnings\n\n" + strings.Join(warnings, "\n") + "\n")
+		})
+	}
+}
+
+func TestOpenAPIImporterByTag(t *testing.T) {
+	snap := snapshot.New(
+		t,
+		snapshot.Update(*update),
+		snapshot.Clean(*clean),
+		snapshot.Color(os.Getenv("CI") == ""),
+	)
+
+	f, err := os.Open(filepath.Join("testdata", "openapi", "petstore.yaml"))
+	test.Ok(t, err)
+	defer f.Close()
+
+	files, err := format.OpenAPIImporter{}.ImportByTag(f)
+	test.Ok(t, err)
+
+	builder := &strings.Builder{}
+	for _, tag := range slices.Sorted(maps.Keys(files)) {
+		builder.WriteString("# Tag: " + tag + "\n\n" + files[tag].String() + "\n")
+	}
+
+	snap.Snap(builder.String())
+}
+
+func TestOpenAPIImporterInvalid(t *testing.T) {
+	tests := []struct {
+		name   string // Name of the test case
+		src    string // The document to import
+		errMsg string // The expected error message
+	}{
+		{
+			name:   "not YAML",
+			src:    "paths: [",
+			errMsg: "could not decode OpenAPI document: yaml: while parsing a flow node at line 1: did not find expected node content",
+		},
+		{
+			name:   "no version",
+			src:    "info:\n  title: API\n",
+			errMsg: `not an OpenAPI document, no "openapi" version found`,
+		},
+		{
+			name:   "swagger",
+			src:    `{"swagger": "2.0", "paths": {}}`,
+			errMsg: "only OpenAPI 3 documents can be imported, got Swagger 2.0",
+		},
+		{
+			name:   "future version",
+			src:    "openapi: 4.0.0\n",
+			errMsg: "only OpenAPI 3 documents can be imported, got OpenAPI 4.0.0",
+		},
+		{
+			name:   "bad paths",
+			src:    "openapi: 3.0.0\npaths: [1, 2]\n",
+			errMsg: "could not decode OpenAPI document: yaml: construct errors:\n  line 2: paths must be an object",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := format.OpenAPIImporter{}.Import(strings.NewReader(tt.src))
+			test.Err(t, err)
+			test.Equal(t, err.Error(), tt.errMsg)
+		})
+	}
+}
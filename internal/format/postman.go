@@ -0,0 +1,962 @@
+package format
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.followtheprocess.codes/zap/internal/spec"
+)
+
+const (
+	// postmanSchema is the schema of a Postman Collection v2.1 document.
+	postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+	// postmanSchemaPrefix is the prefix of the schemas of every version of Postman collection.
+	postmanSchemaPrefix = "https://schema.getpostman.com/json/collection/"
+)
+
+// postmanVariable matches interpolations of a plain variable e.g. '{{ token }}', which are
+// written without the spaces in Postman e.g. '{{token}}'.
+//
+//nolint:gochecknoglobals // Compiled once
+var postmanVariable = regexp.MustCompile(`{{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*}}`)
+
+// postmanDynamicVariable matches a use of one of Postman's dynamic variables e.g. '{{$guid}}'.
+//
+//nolint:gochecknoglobals // Compiled once
+var postmanDynamicVariable = regexp.MustCompile(`{{\s*\$([A-Za-z]+)\s*}}`)
+
+// postmanBuiltins are Postman's dynamic variables equivalent to the '$random' builtin,
+// by its generator e.g. '$random.email' is '{{$randomEmail}}'.
+//
+//nolint:gochecknoglobals // This has to be here
+var postmanBuiltins = map[string]string{
+	"uuid":                  "{{$randomUUID}}",
+	"email":                 "{{$randomEmail}}",
+	"name.firstName":        "{{$randomFirstName}}",
+	"name.lastName":         "{{$randomLastName}}",
+	"name.fullName":         "{{$randomFullName}}",
+	"name.username":         "{{$randomUserName}}",
+	"address.streetAddress": "{{$randomStreetAddress}}",
+	"address.city":          "{{$randomCity}}",
+	"address.country":       "{{$randomCountry}}",
+}
+
+// PostmanExporter is an [Exporter] that transforms .http files into Postman Collection v2.1
+// documents.
+//
+// Each request becomes an item in the collection and the global variables become collection
+// variables. Postman has no timeout settings in a collection so the timeouts are kept in the
+// 'protocolProfileBehavior' along with whether redirects are followed, where Postman ignores
+// them.
+type PostmanExporter struct{}
+
+// Export implements [Exporter] for [PostmanExporter] and exports the given file as a complete
+// Postman collection.
+func (p PostmanExporter) Export(w io.Writer, file spec.File) error {
+	collection := postmanCollection{
+		Info: postmanInfo{
+			Name:   file.Name,
+			Schema: postmanSchema,
+		},
+		Item:     make([]postmanItem, 0, len(file.Requests)),
+		Variable: postmanVariables(file.Vars),
+		ProtocolProfileBehavior: postmanBehaviour(
+			file.Timeout,
+			file.ConnectionTimeout,
+			file.NoRedirect,
+		),
+	}
+
+	for i, request := range file.Requests {
+		collection.Item = append(collection.Item, postmanRequestItem(i, request))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(collection)
+}
+
+// Builtin implements [BuiltinExporter] for [PostmanExporter], dynamic builtins are exported
+// as the equivalent Postman dynamic variable e.g. '{{$guid}}' for '$uuid'.
+//
+// Postman's dynamic variables take no arguments, so builtins called with any are not exported
+// as one unless there is an exact equivalent.
+func (p PostmanExporter) Builtin(name string, args ...string) (string, bool) {
+	switch name {
+	case "uuid":
+		return "{{$guid}}", true
+	case "timestamp":
+		return "{{$timestamp}}", len(args) == 0
+	case "isoTimestamp":
+		return "{{$isoTimestamp}}", len(args) == 0
+	case "random":
+		equivalent, ok := postmanBuiltins[strings.Join(args, ".")]
+		return equivalent, ok
+	default:
+		return "", false
+	}
+}
+
+// PostmanImporter is an [Importer] that transforms Postman Collection v2.1 (or v2.0) documents
+// into a [spec.File].
+//
+// Collection variables become global variables and each request in the collection a request,
+// those in folders are commented with the folder they were in e.g. '### Users / Get user'.
+// References to variables e.g. '{{token}}' are left as they are, Postman's dynamic variables
+// e.g. '{{$guid}}' become the equivalent builtin.
+//
+// Anything zap has no equivalent of, such as pre-request and test scripts, is left out.
+type PostmanImporter struct {
+	// Warn, if not nil, is called with a description of everything in the collection that
+	// could not be imported.
+	Warn func(msg string)
+}
+
+// Import implements [Importer] for [PostmanImporter].
+func (p PostmanImporter) Import(r io.Reader) (spec.File, error) {
+	var collection postmanCollection
+	if err := json.NewDecoder(r).Decode(&collection); err != nil {
+		return spec.File{}, fmt.Errorf("could not decode Postman collection: %w", err)
+	}
+
+	if !strings.HasPrefix(collection.Info.Schema, postmanSchemaPrefix) {
+		return spec.File{}, fmt.Errorf("not a Postman collection, unexpected schema %q", collection.Info.Schema)
+	}
+
+	importer := &postmanImport{
+		warn:  p.Warn,
+		names: make(map[string]bool),
+	}
+
+	file := spec.File{
+		Name: identifier(collection.Info.Name),
+		Vars: importer.variables("collection", nil, collection.Variable),
+	}
+
+	if behaviour := collection.ProtocolProfileBehavior; behaviour != nil {
+		file.Timeout, file.ConnectionTimeout, file.NoRedirect = behaviour.settings()
+	}
+
+	importer.events("collection", collection.Event)
+	importer.items(&file, collection.Item, nil, collection.Auth, nil)
+
+	return file, nil
+}
+
+// postmanCollection is a Postman Collection v2.1 document.
+type postmanCollection struct {
+	ProtocolProfileBehavior *postmanProtocolProfileBehavior `json:"protocolProfileBehavior,omitempty"`
+	Auth                    *postmanAuth                    `json:"auth,omitempty"`
+	Info                    postmanInfo                     `json:"info"`
+	Item                    []postmanItem                   `json:"item"`
+	Event                   []postmanEvent                  `json:"event,omitempty"`
+	Variable                []postmanKeyValue               `json:"variable,omitempty"`
+}
+
+// postmanInfo is the information about a Postman collection.
+type postmanInfo struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+// postmanItem is a single request in a Postman collection, or a folder of them if it has
+// items of its own.
+type postmanItem struct {
+	ProtocolProfileBehavior *postmanProtocolProfileBehavior `json:"protocolProfileBehavior,omitempty"`
+	Auth                    *postmanAuth                    `json:"auth,omitempty"`
+	Name                    string                          `json:"name"`
+	Variable                []postmanKeyValue               `json:"variable,omitempty"`
+	Item                    []postmanItem                   `json:"item,omitempty"`
+	Event                   []postmanEvent                  `json:"event,omitempty"`
+	Request                 postmanRequest                  `json:"request"`
+}
+
+// postmanRequest is the HTTP request of a Postman item.
+type postmanRequest struct {
+	Body        *postmanBody       `json:"body,omitempty"`
+	Auth        *postmanAuth       `json:"auth,omitempty"`
+	Method      string             `json:"method"`
+	Description postmanDescription `json:"description,omitempty"`
+	URL         postmanURL         `json:"url"`
+	Header      []postmanKeyValue  `json:"header"`
+}
+
+// UnmarshalJSON implements [json.Unmarshaler] for a [postmanRequest], which may also be
+// given as just its URL.
+func (p *postmanRequest) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		*p = postmanRequest{Method: "GET", URL: postmanURL{Raw: raw}}
+		return nil
+	}
+
+	type plain postmanRequest // Without the UnmarshalJSON method
+
+	return json.Unmarshal(data, (*plain)(p))
+}
+
+// postmanURL is the URL of a Postman request, the raw URL along with its parts.
+type postmanURL struct {
+	Raw      string            `json:"raw"`
+	Protocol string            `json:"protocol,omitempty"`
+	Port     string            `json:"port,omitempty"`
+	Host     []string          `json:"host,omitempty"`
+	Path     []string          `json:"path,omitempty"`
+	Query    []postmanKeyValue `json:"query,omitempty"`
+	Variable []postmanKeyValue `json:"variable,omitempty"`
+}
+
+// UnmarshalJSON implements [json.Unmarshaler] for a [postmanURL], which may also be given
+// as just the raw URL.
+func (p *postmanURL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		*p = postmanURL{Raw: raw}
+		return nil
+	}
+
+	type plain postmanURL // Without the UnmarshalJSON method
+
+	return json.Unmarshal(data, (*plain)(p))
+}
+
+// postmanBody is the body of a Postman request, given either inline, as a file or as
+// form fields.
+type postmanBody struct {
+	File       *postmanFile        `json:"file,omitempty"`
+	Options    *postmanBodyOptions `json:"options,omitempty"`
+	GraphQL    *postmanGraphQL     `json:"graphql,omitempty"`
+	Mode       string              `json:"mode"`
+	Raw        string              `json:"raw,omitempty"`
+	URLEncoded []postmanKeyValue   `json:"urlencoded,omitempty"`
+	Disabled   bool                `json:"disabled,omitempty"`
+}
+
+// postmanGraphQL is a GraphQL query used as the body of a Postman request, its variables
+// are a JSON object as a string.
+type postmanGraphQL struct {
+	Query     string `json:"query"`
+	Variables string `json:"variables,omitempty"`
+}
+
+// postmanFile is a file used as the body of a Postman request.
+type postmanFile struct {
+	Src string `json:"src"`
+}
+
+// postmanBodyOptions are the options of a raw Postman body, the language it is written in.
+type postmanBodyOptions struct {
+	Raw postmanRawOptions `json:"raw"`
+}
+
+// postmanRawOptions are the options of a raw Postman body.
+type postmanRawOptions struct {
+	Language string `json:"language"`
+}
+
+// postmanKeyValue is a key value pair in Postman, used for variables, headers and queries.
+type postmanKeyValue struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+// UnmarshalJSON implements [json.Unmarshaler] for a [postmanKeyValue], whose value may be
+// any JSON scalar e.g. a variable of type number.
+func (p *postmanKeyValue) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Key      string          `json:"key"`
+		Value    json.RawMessage `json:"value"`
+		Disabled bool            `json:"disabled"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*p = postmanKeyValue{Key: raw.Key, Disabled: raw.Disabled}
+
+	if err := json.Unmarshal(raw.Value, &p.Value); err != nil && string(raw.Value) != "null" {
+		p.Value = string(raw.Value)
+	}
+
+	return nil
+}
+
+// postmanDescription is the description of a Postman request, which may be given as a
+// string or an object with the description as its content.
+type postmanDescription string
+
+// UnmarshalJSON implements [json.Unmarshaler] for a [postmanDescription].
+func (p *postmanDescription) UnmarshalJSON(data []byte) error {
+	var description struct {
+		Content string `json:"content"`
+	}
+
+	if err := json.Unmarshal(data, &description); err == nil {
+		*p = postmanDescription(description.Content)
+		return nil
+	}
+
+	return json.Unmarshal(data, (*string)(p))
+}
+
+// postmanAuth is the authentication of a Postman request, folder or collection.
+type postmanAuth struct {
+	// params are the parameters of the type of authentication e.g. 'token' for bearer.
+	params map[string]string
+
+	// kind is the type of authentication e.g. 'bearer'.
+	kind string
+}
+
+// UnmarshalJSON implements [json.Unmarshaler] for a [postmanAuth], whose parameters are a
+// list of key value pairs in v2.1 collections and an object in v2.0.
+func (p *postmanAuth) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*p = postmanAuth{params: make(map[string]string)}
+
+	if err := json.Unmarshal(raw["type"], &p.kind); err != nil {
+		return fmt.Errorf("invalid auth type: %w", err)
+	}
+
+	params, ok := raw[p.kind]
+	if !ok {
+		return nil
+	}
+
+	var list []postmanKeyValue
+	if err := json.Unmarshal(params, &list); err == nil {
+		for _, param := range list {
+			p.params[param.Key] = param.Value
+		}
+
+		return nil
+	}
+
+	var object map[string]any
+	if err := json.Unmarshal(params, &object); err != nil {
+		return fmt.Errorf("invalid %s auth: %w", p.kind, err)
+	}
+
+	for key, value := range object {
+		p.params[key] = fmt.Sprint(value)
+	}
+
+	return nil
+}
+
+// postmanEvent is a script run by Postman before or after a request.
+type postmanEvent struct {
+	Listen string `json:"listen"`
+	Script struct {
+		Exec json.RawMessage `json:"exec"`
+	} `json:"script"`
+}
+
+// empty reports whether the event has no script to run.
+func (p postmanEvent) empty() bool {
+	var lines []string
+	if err := json.Unmarshal(p.Script.Exec, &lines); err == nil {
+		return strings.TrimSpace(strings.Join(lines, "")) == ""
+	}
+
+	var line string
+	if err := json.Unmarshal(p.Script.Exec, &line); err == nil {
+		return strings.TrimSpace(line) == ""
+	}
+
+	return len(p.Script.Exec) == 0 || string(p.Script.Exec) == "null"
+}
+
+// postmanProtocolProfileBehavior is the behaviour of the HTTP client sending a request.
+//
+// Only followRedirects is understood by Postman, the timeouts are in milliseconds.
+type postmanProtocolProfileBehavior struct {
+	FollowRedirects   *bool `json:"followRedirects,omitempty"`
+	Timeout           int64 `json:"timeout,omitempty"`
+	ConnectionTimeout int64 `json:"connectionTimeout,omitempty"`
+}
+
+// settings returns the timeouts and whether redirects are not followed given by the
+// behaviour, the inverse of [postmanBehaviour].
+func (p postmanProtocolProfileBehavior) settings() (timeout, connectionTimeout time.Duration, noRedirect bool) {
+	timeout = time.Duration(p.Timeout) * time.Millisecond
+	connectionTimeout = time.Duration(p.ConnectionTimeout) * time.Millisecond
+	noRedirect = p.FollowRedirects != nil && !*p.FollowRedirects
+
+	return timeout, connectionTimeout, noRedirect
+}
+
+// postmanRequestItem returns the Postman item for request, the i'th in the file.
+func postmanRequestItem(i int, request spec.Request) postmanItem {
+	name := request.Name
+	if name == "" {
+		name = "#" + strconv.Itoa(i+1)
+	}
+
+	item := postmanItem{
+		Name:     name,
+		Variable: postmanVariables(request.Vars),
+		Request: postmanRequest{
+			Method:      request.Method,
+			Description: postmanDescription(request.Comment),
+			URL:         postmanRequestURL(postmanText(request.URL.String())),
+			Header:      []postmanKeyValue{},
+		},
+		ProtocolProfileBehavior: postmanBehaviour(
+			request.Timeout,
+			request.ConnectionTimeout,
+			request.NoRedirect,
+		),
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(request.Headers)) {
+		for _, value := range request.Headers[key] {
+			item.Request.Header = append(item.Request.Header, postmanKeyValue{Key: key, Value: postmanText(value.String())})
+		}
+	}
+
+	switch {
+	case len(request.BodyFile) != 0:
+		item.Request.Body = &postmanBody{
+			Mode: "file",
+			File: &postmanFile{Src: postmanText(request.BodyFile.String())},
+		}
+	case len(request.Body) != 0:
+		item.Request.Body = &postmanBody{
+			Mode: "raw",
+			Raw:  postmanText(request.Body.String()),
+		}
+
+		if language := postmanLanguage(request.Headers.Get("Content-Type").String()); language != "" {
+			item.Request.Body.Options = &postmanBodyOptions{Raw: postmanRawOptions{Language: language}}
+		}
+	}
+
+	return item
+}
+
+// postmanRequestURL returns the Postman URL for raw, split into its parts if it can be parsed.
+//
+// A URL whose host is a variable e.g. '{{base}}/items' cannot, so it is left for Postman
+// to split.
+func postmanRequestURL(raw string) postmanURL {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return postmanURL{Raw: raw}
+	}
+
+	postman := postmanURL{
+		Raw:      raw,
+		Protocol: parsed.Scheme,
+		Port:     parsed.Port(),
+		Host:     strings.Split(parsed.Hostname(), "."),
+	}
+
+	if path := strings.Trim(parsed.EscapedPath(), "/"); path != "" {
+		postman.Path = strings.Split(path, "/")
+	}
+
+	if parsed.RawQuery != "" {
+		for pair := range strings.SplitSeq(parsed.RawQuery, "&") {
+			key, value, _ := strings.Cut(pair, "=")
+			postman.Query = append(postman.Query, postmanKeyValue{Key: key, Value: value})
+		}
+	}
+
+	return postman
+}
+
+// postmanVariables returns vars as a list of Postman variables, sorted by name.
+//
+// A variable whose value is itself e.g. a secret prompt left as '{{ token }}' is exported
+// without a value, for it to be given one in Postman.
+func postmanVariables(vars map[string]spec.Template) []postmanKeyValue {
+	variables := make([]postmanKeyValue, 0, len(vars))
+	for _, key := range slices.Sorted(maps.Keys(vars)) {
+		value := postmanText(vars[key].String())
+		if value == "{{"+key+"}}" {
+			value = ""
+		}
+
+		variables = append(variables, postmanKeyValue{Key: key, Value: value})
+	}
+
+	return variables
+}
+
+// postmanBehaviour returns the Postman protocol profile behaviour for the given settings, or
+// nil if they are all the default.
+func postmanBehaviour(timeout, connectionTimeout time.Duration, noRedirect bool) *postmanProtocolProfileBehavior {
+	if timeout == 0 && connectionTimeout == 0 && !noRedirect {
+		return nil
+	}
+
+	behaviour := &postmanProtocolProfileBehavior{
+		Timeout:           timeout.Milliseconds(),
+		ConnectionTimeout: connectionTimeout.Milliseconds(),
+	}
+
+	if noRedirect {
+		follow := false
+		behaviour.FollowRedirects = &follow
+	}
+
+	return behaviour
+}
+
+// postmanLanguage returns the Postman language of a raw body with the given content type,
+// or "" if Postman has none for it.
+func postmanLanguage(contentType string) string {
+	switch {
+	case strings.Contains(contentType, "json"):
+		return "json"
+	case strings.Contains(contentType, "xml"):
+		return "xml"
+	case strings.Contains(contentType, "html"):
+		return "html"
+	case strings.Contains(contentType, "javascript"):
+		return "javascript"
+	case strings.HasPrefix(contentType, "text/"):
+		return "text"
+	default:
+		return ""
+	}
+}
+
+// postmanText returns text with interpolations of plain variables written in the Postman
+// style e.g. '{{ token }}' becomes '{{token}}'.
+func postmanText(text string) string {
+	return postmanVariable.ReplaceAllString(text, "{{$1}}")
+}
+
+// postmanImport is the state of a single Postman collection being imported.
+type postmanImport struct {
+	// warn is called with everything that could not be imported, may be nil.
+	warn func(msg string)
+
+	// names are the names given to the requests imported so far, so each is unique.
+	names map[string]bool
+}
+
+// warnf calls warn with a formatted message, if there is one.
+func (p *postmanImport) warnf(format string, a ...any) {
+	if p.warn != nil {
+		p.warn(fmt.Sprintf(format, a...))
+	}
+}
+
+// items imports the requests in items, recursing into folders, into file.
+//
+// folders are the names of the folders the items are in, auth the authentication they inherit
+// and vars the variables of those folders.
+func (p *postmanImport) items(
+	file *spec.File,
+	items []postmanItem,
+	folders []string,
+	auth *postmanAuth,
+	vars map[string]spec.Template,
+) {
+	for _, item := range items {
+		path := slices.Concat(folders, []string{item.Name})
+		title := strings.Join(path, " / ")
+
+		inherited := auth
+		if item.Auth != nil && item.Auth.kind != "inherit" {
+			inherited = item.Auth
+		}
+
+		scoped := p.variables(title, vars, item.Variable)
+
+		p.events(title, item.Event)
+
+		if item.Item != nil {
+			p.items(file, item.Item, path, inherited, scoped)
+			continue
+		}
+
+		file.Requests = append(file.Requests, p.request(item, title, inherited, scoped))
+	}
+}
+
+// request imports a single Postman request item, titled with the folders it is in.
+func (p *postmanImport) request(
+	item postmanItem,
+	title string,
+	auth *postmanAuth,
+	vars map[string]spec.Template,
+) spec.Request {
+	in := item.Request
+
+	request := spec.Request{
+		Name:    p.name(item.Name),
+		Method:  strings.ToUpper(in.Method),
+		URL:     spec.Text(p.text(title, p.url(title, in.URL, vars))),
+		Headers: make(spec.Headers),
+		Vars:    vars,
+	}
+
+	if request.Method == "" {
+		request.Method = http.MethodGet
+	}
+
+	if description, _, _ := strings.Cut(strings.TrimSpace(string(in.Description)), "\n"); description != "" {
+		request.Comment = description
+	} else if title != request.Name {
+		request.Comment = title
+	}
+
+	for _, header := range in.Header {
+		if !header.Disabled {
+			request.Headers.Add(header.Key, spec.Text(p.text(title, header.Value)))
+		}
+	}
+
+	if in.Auth != nil && in.Auth.kind != "inherit" {
+		auth = in.Auth
+	}
+
+	p.auth(&request, title, auth)
+	p.body(&request, title, in.Body)
+
+	if behaviour := item.ProtocolProfileBehavior; behaviour != nil {
+		request.Timeout, request.ConnectionTimeout, request.NoRedirect = behaviour.settings()
+	}
+
+	if len(request.Headers) == 0 {
+		request.Headers = nil
+	}
+
+	if len(request.Vars) == 0 {
+		request.Vars = nil
+	}
+
+	return request
+}
+
+// url returns the URL of a Postman request, path variables e.g. '/users/:id' are replaced
+// with variables set to their value in vars.
+func (p *postmanImport) url(title string, in postmanURL, vars map[string]spec.Template) string {
+	raw := in.Raw
+	if raw == "" {
+		raw = strings.Join(in.Host, ".")
+		if in.Protocol != "" {
+			raw = in.Protocol + "://" + raw
+		}
+
+		if in.Port != "" {
+			raw += ":" + in.Port
+		}
+
+		if len(in.Path) != 0 {
+			raw += "/" + strings.Join(in.Path, "/")
+		}
+
+		var query []string
+
+		for _, param := range in.Query {
+			if !param.Disabled {
+				query = append(query, param.Key+"="+param.Value)
+			}
+		}
+
+		if len(query) != 0 {
+			raw += "?" + strings.Join(query, "&")
+		}
+	}
+
+	if len(in.Variable) == 0 {
+		return raw
+	}
+
+	path, rest, _ := strings.Cut(raw, "?")
+	segments := strings.Split(path, "/")
+
+	for _, variable := range in.Variable {
+		// Path variables that can't be variables in a .http file are left in the path as is
+		if !p.variable(title, variable) {
+			continue
+		}
+
+		for i, segment := range segments {
+			if segment == ":"+variable.Key {
+				segments[i] = "{{" + variable.Key + "}}"
+			}
+		}
+
+		if variable.Value != "" {
+			vars[variable.Key] = spec.Text(p.text(title, variable.Value))
+		}
+	}
+
+	joined := strings.Join(segments, "/")
+	if rest != "" {
+		joined += "?" + rest
+	}
+
+	return joined
+}
+
+// auth adds the Postman authentication to request as the equivalent header, or query
+// parameter for an API key given in the query.
+func (p *postmanImport) auth(request *spec.Request, title string, auth *postmanAuth) {
+	if auth == nil || len(request.Headers.Get("Authorization")) != 0 {
+		return
+	}
+
+	switch auth.kind {
+	case "noauth", "inherit":
+		return
+	case "bearer":
+		request.Headers.Set("Authorization", spec.Text("Bearer "+p.text(title, auth.params["token"])))
+	case "basic":
+		username, password := auth.params["username"], auth.params["password"]
+		if !strings.Contains(username+password, "{{") {
+			credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+			request.Headers.Set("Authorization", spec.Text("Basic "+credentials))
+
+			return
+		}
+
+		user, userOK := postmanOperand(username)
+		pass, passOK := postmanOperand(password)
+
+		if !userOK || !passOK {
+			p.warnf("%s: basic auth mixing variables and text is not supported and was left out", title)
+			return
+		}
+
+		request.Headers.Set("Authorization", spec.Text("Basic {{ $base64("+user+` + ":" + `+pass+") }}"))
+	case "apikey":
+		key, value := auth.params["key"], p.text(title, auth.params["value"])
+		if auth.params["in"] != "query" {
+			request.Headers.Set(key, spec.Text(value))
+			return
+		}
+
+		separator := "?"
+		if strings.Contains(request.URL.String(), "?") {
+			separator = "&"
+		}
+
+		request.URL = request.URL.Concat(spec.Text(separator + key + "=" + value))
+	default:
+		p.warnf("%s: %s auth is not supported and was left out", title, auth.kind)
+	}
+}
+
+// body sets the body of request from the Postman body, adding the Content-Type header
+// Postman would if it is not set.
+func (p *postmanImport) body(request *spec.Request, title string, body *postmanBody) {
+	if body == nil || body.Disabled {
+		return
+	}
+
+	contentType := ""
+
+	switch body.Mode {
+	case "", "raw":
+		request.Body = spec.Text(p.text(title, body.Raw))
+
+		if body.Options != nil {
+			contentType = postmanContentType(body.Options.Raw.Language)
+		}
+	case "file":
+		if body.File == nil || body.File.Src == "" {
+			p.warnf("%s: file body without a file was left out", title)
+			return
+		}
+
+		request.BodyFile = spec.Text(body.File.Src)
+	case "urlencoded":
+		var fields []string
+
+		for _, field := range body.URLEncoded {
+			if !field.Disabled {
+				fields = append(fields, field.Key+"="+field.Value)
+			}
+		}
+
+		request.Body = spec.Text(p.text(title, strings.Join(fields, "&")))
+		contentType = "application/x-www-form-urlencoded"
+	case "graphql":
+		if body.GraphQL == nil {
+			return
+		}
+
+		query := map[string]any{"query": body.GraphQL.Query}
+		if variables := strings.TrimSpace(body.GraphQL.Variables); variables != "" {
+			query["variables"] = json.RawMessage(variables)
+		}
+
+		encoded, err := json.Marshal(query)
+		if err != nil {
+			p.warnf("%s: graphql body has invalid variables and was left out: %v", title, err)
+			return
+		}
+
+		request.Body = spec.Text(p.text(title, string(encoded)))
+		contentType = "application/json"
+	default:
+		p.warnf("%s: %s body is not supported and was left out", title, body.Mode)
+		return
+	}
+
+	if contentType != "" && len(request.Body) != 0 && len(request.Headers.Get("Content-Type")) == 0 {
+		request.Headers.Set("Content-Type", spec.Text(contentType))
+	}
+}
+
+// variables returns the Postman variables merged over inherited, those that cannot be
+// variables in a .http file are left out.
+func (p *postmanImport) variables(
+	title string,
+	inherited map[string]spec.Template,
+	variables []postmanKeyValue,
+) map[string]spec.Template {
+	vars := maps.Clone(inherited)
+	if vars == nil {
+		vars = make(map[string]spec.Template, len(variables))
+	}
+
+	for _, variable := range variables {
+		if variable.Disabled || !p.variable(title, variable) {
+			continue
+		}
+
+		vars[variable.Key] = spec.Text(p.text(title, variable.Value))
+	}
+
+	return vars
+}
+
+// variable reports whether a Postman variable can be a variable in a .http file, warning
+// why not if it can't.
+func (p *postmanImport) variable(title string, variable postmanKeyValue) bool {
+	switch {
+	case !identifierPattern.MatchString(variable.Key):
+		p.warnf("%s: variable %q is not a valid name and was left out", title, variable.Key)
+	case keyword(variable.Key):
+		p.warnf("%s: variable %s is a reserved name and was left out", title, variable.Key)
+	case strings.ContainsAny(variable.Value, "\r\n"):
+		p.warnf("%s: variable %s spans multiple lines and was left out", title, variable.Key)
+	default:
+		return true
+	}
+
+	return false
+}
+
+// events warns about any scripts in events, which cannot be imported.
+func (p *postmanImport) events(title string, events []postmanEvent) {
+	for _, event := range events {
+		if event.empty() {
+			continue
+		}
+
+		kind := event.Listen
+		if kind == "prerequest" {
+			kind = "pre-request"
+		}
+
+		p.warnf("%s: %s script is not supported and was left out", title, kind)
+	}
+}
+
+// name returns a unique request name for a Postman item called name.
+func (p *postmanImport) name(name string) string {
+	base := identifier(name)
+	if base == "" {
+		base = "request"
+	}
+
+	unique := base
+	for i := 2; p.names[unique]; i++ {
+		unique = base + "-" + strconv.Itoa(i)
+	}
+
+	p.names[unique] = true
+
+	return unique
+}
+
+// text returns text with Postman's dynamic variables replaced by the equivalent builtins,
+// warning about those without one.
+func (p *postmanImport) text(title, text string) string {
+	return postmanDynamicVariable.ReplaceAllStringFunc(text, func(match string) string {
+		name := postmanDynamicVariable.FindStringSubmatch(match)[1]
+
+		switch name {
+		case "guid", "randomUUID":
+			return "{{ $uuid }}"
+		case "timestamp":
+			return "{{ $timestamp }}"
+		case "isoTimestamp":
+			return "{{ $isoTimestamp }}"
+		case "randomInt":
+			return "{{ $randomInt 0 1001 }}"
+		}
+
+		for generator, equivalent := range postmanBuiltins {
+			if equivalent == "{{$"+name+"}}" {
+				return "{{ $random." + generator + " }}"
+			}
+		}
+
+		p.warnf("%s: dynamic variable %s has no equivalent and was left as it is", title, match)
+
+		return match
+	})
+}
+
+// postmanOperand returns the expression for a Postman value in a builtin call, a variable
+// for a reference to one e.g. '{{user}}' and a quoted string for plain text, and whether it
+// is either.
+func postmanOperand(value string) (string, bool) {
+	if match := postmanVariable.FindStringSubmatch(value); match != nil && match[0] == value {
+		return match[1], true
+	}
+
+	if strings.Contains(value, "{{") {
+		return "", false
+	}
+
+	return strconv.Quote(value), true
+}
+
+// postmanContentType returns the content type of a raw Postman body in language, the
+// inverse of [postmanLanguage].
+func postmanContentType(language string) string {
+	switch language {
+	case "json":
+		return "application/json"
+	case "xml":
+		return "application/xml"
+	case "html":
+		return "text/html"
+	case "javascript":
+		return "application/javascript"
+	case "text":
+		return "text/plain"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,370 @@
+package format_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.followtheprocess.codes/snapshot"
+	"go.followtheprocess.codes/test"
+	"go.followtheprocess.codes/zap/internal/format"
+	"go.followtheprocess.codes/zap/internal/spec"
+)
+
+func TestPostmanExporter(t *testing.T) {
+	tests := []struct {
+		name string    // Name of the test case
+		file spec.File // The HTTP file
+	}{
+		{
+			name: "simple",
+			file: spec.File{
+				Name: "simple",
+				Requests: []spec.Request{
+					{
+						Method: http.MethodGet,
+						URL:    spec.Text("https://api.nowhere.com/v1/items/1234"),
+					},
+				},
+			},
+		},
+		{
+			name: "no redirect",
+			file: spec.File{
+				Name: "no redirect",
+				Requests: []spec.Request{
+					{
+						Method:     http.MethodGet,
+						URL:        spec.Text("https://api.nowhere.com/v1/items/1234"),
+						NoRedirect: true,
+					},
+				},
+			},
+		},
+		{
+			name: "with headers",
+			file: spec.File{
+				Name: "with headers",
+				Requests: []spec.Request{
+					{
+						Method: http.MethodGet,
+						URL:    spec.Text("https://jsonplaceholder.typicode.com/todos/1"),
+						Headers: spec.Headers{
+							"Content-Type":    {spec.Text("application/json")},
+							"Accept":          {spec.Text("application/json"), spec.Text("application/xml")},
+							"User-Agent":      {spec.Text("go.followtheprocess.codes/zap test")},
+							"X-Custom-Header": {spec.Text("yes"), spec.Text("multiple"), spec.Text("things")},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "with timeouts",
+			file: spec.File{
+				Name: "with timeouts",
+				Requests: []spec.Request{
+					{
+						Method:            http.MethodDelete,
+						URL:               spec.Text("https://somewhere.org/api"),
+						ConnectionTimeout: 1 * time.Second,
+						Timeout:           15 * time.Second,
+					},
+				},
+			},
+		},
+		{
+			name: "with body",
+			file: spec.File{
+				Name: "with body",
+				Requests: []spec.Request{
+					{
+						Method: http.MethodPost,
+						URL:    spec.Text("https://somewhere.org/api/items/1"),
+						Body:   spec.Text(`{"stuff":"here"}`),
+					},
+				},
+			},
+		},
+		{
+			name: "with large body",
+			file: spec.File{
+				Name: "with body",
+				Requests: []spec.Request{
+					{
+						Method: http.MethodPost,
+						URL:    spec.Text("https://somewhere.org/api/items/1"),
+						Body:   spec.Text(largeBody),
+					},
+				},
+			},
+		},
+		{
+			name: "with body file",
+			file: spec.File{
+				Name: "with body file",
+				Requests: []spec.Request{
+					{
+						Method:   http.MethodPost,
+						URL:      spec.Text("https://somewhere.org/api/items/1"),
+						BodyFile: spec.Text("a/file.txt"),
+					},
+				},
+			},
+		},
+		{
+			name: "with response file",
+			file: spec.File{
+				Name: "with response file",
+				Requests: []spec.Request{
+					{
+						Method:       http.MethodGet,
+						URL:          spec.Text("https://api.elsehwere.new/users/1"),
+						ResponseFile: spec.Text("response.200.json"),
+					},
+				},
+			},
+		},
+		{
+			name: "with multiple",
+			file: spec.File{
+				Name: "with multiple",
+				Requests: []spec.Request{
+					{
+						Method: http.MethodGet,
+						URL:    spec.Text("https://api.nowhere.com/v1/items/1234"),
+					},
+					{
+						Method:     http.MethodGet,
+						URL:        spec.Text("https://api.nowhere.com/v1/items/1234"),
+						NoRedirect: true,
+					},
+					{
+						Method:       http.MethodGet,
+						URL:          spec.Text("https://api.elsehwere.new/users/1"),
+						ResponseFile: spec.Text("response.200.json"),
+					},
+					{
+						Method: http.MethodGet,
+						URL:    spec.Text("https://jsonplaceholder.typicode.com/todos/1"),
+						Headers: spec.Headers{
+							"Content-Type":    {spec.Text("application/json")},
+							"Accept":          {spec.Text("application/json"), spec.Text("application/xml")},
+							"User-Agent":      {spec.Text("go.followtheprocess.codes/zap test")},
+							"X-Custom-Header": {spec.Text("yes"), spec.Text("multiple"), spec.Text("things")},
+						},
+					},
+					{
+						Method:            http.MethodDelete,
+						URL:               spec.Text("https://somewhere.org/api"),
+						ConnectionTimeout: 1 * time.Second,
+						Timeout:           15 * time.Second,
+					},
+					{
+						Method: http.MethodPost,
+						URL:    spec.Text("https://somewhere.org/api/items/1"),
+						Body:   spec.Text(`{"stuff":"here"}`),
+					},
+					{
+						Method:   http.MethodPost,
+						URL:      spec.Text("https://somewhere.org/api/items/1"),
+						BodyFile: spec.Text("a/file.txt"),
+					},
+					{
+						Method:       http.MethodGet,
+						URL:          spec.Text("https://api.elsehwere.new/users/1"),
+						ResponseFile: spec.Text("response.200.json"),
+					},
+				},
+			},
+		},
+		{
+			name: "with vars",
+			file: spec.File{
+				Name: "with vars",
+				Vars: map[string]spec.Template{
+					"base":  spec.Text("https://api.nowhere.com/v1"),
+					"token": spec.Text("{{ token }}"),
+				},
+				Timeout:    30 * time.Second,
+				NoRedirect: true,
+				Requests: []spec.Request{
+					{
+						Name:    "items",
+						Comment: "List the items",
+						Method:  http.MethodPost,
+						URL:     spec.Text("{{ base }}/items?page=1&size=10"),
+						Vars: map[string]spec.Template{
+							"id": spec.Text("{{$guid}}"),
+						},
+						Headers: spec.Headers{
+							"Authorization": {spec.Text("Bearer {{ token }}")},
+							"Content-Type":  {spec.Text("application/json")},
+						},
+						Body: spec.Text(`{"id": "{{ id }}"}`),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snap := snapshot.New(
+				t,
+				snapshot.Update(*update),
+				snapshot.Clean(*clean),
+				snapshot.Color(os.Getenv("CI") == ""),
+			)
+			exporter := format.PostmanExporter{}
+			buf := &bytes.Buffer{}
+			test.Ok(t, exporter.Export(buf, tt.file))
+
+			test.True(t, json.Valid(buf.Bytes()), test.Context("invalid JSON:\n%s", buf.String()))
+
+			snap.Snap(buf.String())
+		})
+	}
+}
+
+func TestPostmanBuiltin(t *testing.T) {
+	tests := []struct {
+		name string   // Name of the builtin
+		want string   // Expected equivalent
+		args []string // Arguments to the builtin
+		ok   bool     // Whether Postman has an equivalent
+	}{
+		{name: "uuid", want: "{{$guid}}", ok: true},
+		{name: "timestamp", want: "{{$timestamp}}", ok: true},
+		{name: "timestamp", args: []string{"-1", "d"}, ok: false},
+		{name: "isoTimestamp", want: "{{$isoTimestamp}}", ok: true},
+		{name: "random", args: []string{"email"}, want: "{{$randomEmail}}", ok: true},
+		{name: "random", args: []string{"name", "fullName"}, want: "{{$randomFullName}}", ok: true},
+		{name: "random", args: []string{"integer", "1", "10"}, ok: false},
+		{name: "datetime", args: []string{"iso8601"}, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := format.PostmanExporter{}.Builtin(tt.name, tt.args...)
+			test.Equal(t, ok, tt.ok)
+
+			if ok {
+				test.Equal(t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostmanImporter(t *testing.T) {
+	pattern := filepath.Join("testdata", "postman", "*.json")
+	files, err := filepath.Glob(pattern)
+	test.Ok(t, err)
+
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".json")
+		t.Run(name, func(t *testing.T) {
+			snap := snapshot.New(
+				t,
+				snapshot.Update(*update),
+				snapshot.Clean(*clean),
+				snapshot.Color(os.Getenv("CI") == ""),
+			)
+
+			f, err := os.Open(file)
+			test.Ok(t, err)
+			defer f.Close()
+
+			var warnings []string
+
+			importer := format.PostmanImporter{
+				Warn: func(msg string) {
+					warnings = append(warnings, msg)
+				},
+			}
+
+			imported, err := importer.Import(f)
+			test.Ok(t, err)
+
+			snap.Snap(imported.String() + "\n# Warnings\n\n" + strings.Join(warnings, "\n") + "\n")
+		})
+	}
+}
+
+func TestPostmanImporterInvalid(t *testing.T) {
+	tests := []struct {
+		name   string // Name of the test case
+		src    string // The document to import
+		errMsg string // The expected error message
+	}{
+		{
+			name:   "not JSON",
+			src:    "nope",
+			errMsg: "could not decode Postman collection: invalid character 'o' in literal null (expecting 'u')",
+		},
+		{
+			name:   "not a collection",
+			src:    `{"info": {"name": "zap"}, "item": []}`,
+			errMsg: `not a Postman collection, unexpected schema ""`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := format.PostmanImporter{}.Import(strings.NewReader(tt.src))
+			test.Err(t, err)
+			test.Equal(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestPostmanRoundTrip(t *testing.T) {
+	file := spec.File{
+		Name: "round-trip",
+		Vars: map[string]spec.Template{
+			"base": spec.Text("https://api.nowhere.com/v1"),
+		},
+		Timeout: 30 * time.Second,
+		Requests: []spec.Request{
+			{
+				Name:    "items",
+				Comment: "List the items",
+				Method:  http.MethodGet,
+				URL:     spec.Text("{{base}}/items?page=1"),
+				Headers: spec.Headers{
+					"Accept": {spec.Text("application/json")},
+				},
+				NoRedirect: true,
+			},
+			{
+				Name:   "create",
+				Method: http.MethodPost,
+				URL:    spec.Text("{{base}}/items"),
+				Headers: spec.Headers{
+					"Content-Type": {spec.Text("application/json")},
+				},
+				Body:              spec.Text(`{"id": 1}`),
+				ConnectionTimeout: 2 * time.Second,
+			},
+			{
+				Name:     "upload",
+				Method:   http.MethodPut,
+				URL:      spec.Text("{{base}}/files"),
+				BodyFile: spec.Text("./data.bin"),
+			},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	test.Ok(t, format.PostmanExporter{}.Export(buf, file))
+
+	imported, err := format.PostmanImporter{}.Import(buf)
+	test.Ok(t, err)
+
+	test.Diff(t, imported.String(), file.String())
+}
@@ -0,0 +1,10 @@
+curl -u admin:hunter2 --max-time 2.5 --connect-timeout 1 -L \
+  -A "zap test" -o items.json --http1.1 https://api.nowhere.com/v1/items
+
+curl -X PUT --data @./payload.json -H 'Content-Type: application/json' https://api.nowhere.com/v1/items/1
+
+curl -G -d page=2 --data-urlencode 'q=hello world' https://api.nowhere.com/v1/search && echo done
+
+curl --json '{"id": 1}' api.nowhere.com/v1/items; curl -I --url=https://api.nowhere.com/v1/health
+
+curl -sXPOST -d one -d two --data-binary three https://api.nowhere.com/v1/form
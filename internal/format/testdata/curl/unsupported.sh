@@ -0,0 +1,5 @@
+export TOKEN=abc
+curl -F 'file=@photo.jpg' -x http://proxy:8080 -b cookies.txt -H 'no-colon' \
+  https://api.nowhere.com/v1/upload https://api.nowhere.com/v1/other
+
+curl -d @one.json -d two https://api.nowhere.com/v1/items
@@ -20,6 +20,12 @@ func (t TOMLExporter) Export(w io.Writer, file spec.File) error {
 	return encoder.Encode(file)
 }
 
+// Builtin implements [BuiltinExporter] for [TOMLExporter], dynamic builtins are exported
+// as they are written in the .http file.
+func (t TOMLExporter) Builtin(name string, args ...string) (string, bool) {
+	return zapBuiltin(name, args...), true
+}
+
 // TOMLImporter is an [Importer] that transforms valid TOML documents representing
 // a .http file into a [spec.File].
 type TOMLImporter struct{}
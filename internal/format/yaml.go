@@ -22,6 +22,12 @@ func (y YAMLExporter) Export(w io.Writer, file spec.File) error {
 	return encoder.Encode(file)
 }
 
+// Builtin implements [BuiltinExporter] for [YAMLExporter], dynamic builtins are exported
+// as they are written in the .http file.
+func (y YAMLExporter) Builtin(name string, args ...string) (string, bool) {
+	return zapBuiltin(name, args...), true
+}
+
 // YAMLImporter is an [Importer] that transforms valid YAML representations of .http files
 // into a [spec.File].
 type YAMLImporter struct{}
@@ -26,7 +26,7 @@ func TestYAML(t *testing.T) {
 				Requests: []spec.Request{
 					{
 						Method: http.MethodGet,
-						URL:    "https://api.nowhere.com/v1/items/1234",
+						URL:    spec.Text("https://api.nowhere.com/v1/items/1234"),
 					},
 				},
 			},
@@ -38,7 +38,7 @@ func TestYAML(t *testing.T) {
 				Requests: []spec.Request{
 					{
 						Method:     http.MethodGet,
-						URL:        "https://api.nowhere.com/v1/items/1234",
+						URL:        spec.Text("https://api.nowhere.com/v1/items/1234"),
 						NoRedirect: true,
 					},
 				},
@@ -51,12 +51,12 @@ func TestYAML(t *testing.T) {
 				Requests: []spec.Request{
 					{
 						Method: http.MethodGet,
-						URL:    "https://jsonplaceholder.typicode.com/todos/1",
-						Headers: http.Header{
-							"Content-Type":    []string{"application/json"},
-							"Accept":          []string{"application/json"},
-							"User-Agent":      []string{"go.followtheprocess.codes/zap test"},
-							"X-Custom-Header": []string{"yes"},
+						URL:    spec.Text("https://jsonplaceholder.typicode.com/todos/1"),
+						Headers: spec.Headers{
+							"Content-Type":    {spec.Text("application/json")},
+							"Accept":          {spec.Text("application/json")},
+							"User-Agent":      {spec.Text("go.followtheprocess.codes/zap test")},
+							"X-Custom-Header": {spec.Text("yes")},
 						},
 					},
 				},
@@ -69,7 +69,7 @@ func TestYAML(t *testing.T) {
 				Requests: []spec.Request{
 					{
 						Method:            http.MethodDelete,
-						URL:               "https://somewhere.org/api",
+						URL:               spec.Text("https://somewhere.org/api"),
 						ConnectionTimeout: 1 * time.Second,
 						Timeout:           15 * time.Second,
 					},
@@ -83,8 +83,8 @@ func TestYAML(t *testing.T) {
 				Requests: []spec.Request{
 					{
 						Method: http.MethodPost,
-						URL:    "https://somewhere.org/api/items/1",
-						Body:   `{"stuff":"here"}`,
+						URL:    spec.Text("https://somewhere.org/api/items/1"),
+						Body:   spec.Text(`{"stuff":"here"}`),
 					},
 				},
 			},
@@ -96,8 +96,8 @@ func TestYAML(t *testing.T) {
 				Requests: []spec.Request{
 					{
 						Method:   http.MethodPost,
-						URL:      "https://somewhere.org/api/items/1",
-						BodyFile: "a/file.txt",
+						URL:      spec.Text("https://somewhere.org/api/items/1"),
+						BodyFile: spec.Text("a/file.txt"),
 					},
 				},
 			},
@@ -109,8 +109,8 @@ func TestYAML(t *testing.T) {
 				Requests: []spec.Request{
 					{
 						Method:       http.MethodGet,
-						URL:          "https://api.elsehwere.new/users/1",
-						ResponseFile: "response.200.json",
+						URL:          spec.Text("https://api.elsehwere.new/users/1"),
+						ResponseFile: spec.Text("response.200.json"),
 					},
 				},
 			},
@@ -122,48 +122,48 @@ func TestYAML(t *testing.T) {
 				Requests: []spec.Request{
 					{
 						Method: http.MethodGet,
-						URL:    "https://api.nowhere.com/v1/items/1234",
+						URL:    spec.Text("https://api.nowhere.com/v1/items/1234"),
 					},
 					{
 						Method:     http.MethodGet,
-						URL:        "https://api.nowhere.com/v1/items/1234",
+						URL:        spec.Text("https://api.nowhere.com/v1/items/1234"),
 						NoRedirect: true,
 					},
 					{
 						Method:       http.MethodGet,
-						URL:          "https://api.elsehwere.new/users/1",
-						ResponseFile: "response.200.json",
+						URL:          spec.Text("https://api.elsehwere.new/users/1"),
+						ResponseFile: spec.Text("response.200.json"),
 					},
 					{
 						Method: http.MethodGet,
-						URL:    "https://jsonplaceholder.typicode.com/todos/1",
-						Headers: http.Header{
-							"Content-Type":    []string{"application/json"},
-							"Accept":          []string{"application/json"},
-							"User-Agent":      []string{"go.followtheprocess.codes/zap test"},
-							"X-Custom-Header": []string{"yes"},
+						URL:    spec.Text("https://jsonplaceholder.typicode.com/todos/1"),
+						Headers: spec.Headers{
+							"Content-Type":    {spec.Text("application/json")},
+							"Accept":          {spec.Text("application/json")},
+							"User-Agent":      {spec.Text("go.followtheprocess.codes/zap test")},
+							"X-Custom-Header": {spec.Text("yes")},
 						},
 					},
 					{
 						Method:            http.MethodDelete,
-						URL:               "https://somewhere.org/api",
+						URL:               spec.Text("https://somewhere.org/api"),
 						ConnectionTimeout: 1 * time.Second,
 						Timeout:           15 * time.Second,
 					},
 					{
 						Method: http.MethodPost,
-						URL:    "https://somewhere.org/api/items/1",
-						Body:   `{"stuff":"here"}`,
+						URL:    spec.Text("https://somewhere.org/api/items/1"),
+						Body:   spec.Text(`{"stuff":"here"}`),
 					},
 					{
 						Method:   http.MethodPost,
-						URL:      "https://somewhere.org/api/items/1",
-						BodyFile: "a/file.txt",
+						URL:      spec.Text("https://somewhere.org/api/items/1"),
+						BodyFile: spec.Text("a/file.txt"),
 					},
 					{
 						Method:       http.MethodGet,
-						URL:          "https://api.elsehwere.new/users/1",
-						ResponseFile: "response.200.json",
+						URL:          spec.Text("https://api.elsehwere.new/users/1"),
+						ResponseFile: spec.Text("response.200.json"),
 					},
 				},
 			},
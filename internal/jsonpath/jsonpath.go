@@ -0,0 +1,360 @@
+// Package jsonpath implements a small, dependency free subset of JSONPath for selecting
+// values out of decoded JSON documents.
+//
+// The supported syntax is:
+//
+//   - '$' the root of the document, every path must start with this
+//   - '.key' or "['key']" member access on an object
+//   - '[n]' index into an array, negative indices count back from the end
+//   - '.*' or '[*]' wildcard, every member of an object or element of an array
+//   - '..key' recursive descent, 'key' at any depth below the current node
+//
+// Documents are the values produced by [encoding/json.Unmarshal] into an any, i.e.
+// map[string]any, []any, string, float64, bool and nil.
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// kind is the kind of a single path segment.
+type kind int
+
+const (
+	kindKey      kind = iota // Object member access e.g. '.key'
+	kindIndex                // Array index e.g. '[0]'
+	kindWildcard             // All children e.g. '.*'
+	kindDescend              // Recursive descent e.g. '..key'
+)
+
+// segment is a single step in a [Path].
+type segment struct {
+	key   string // The member name for key and descend segments
+	index int    // The array index for index segments
+	kind  kind   // The kind of segment
+}
+
+// Path is a compiled JSONPath expression.
+type Path struct {
+	raw      string    // The original expression
+	segments []segment // The compiled steps
+}
+
+// Parse compiles a JSONPath expression into a [Path].
+func Parse(expr string) (Path, error) {
+	rest, ok := strings.CutPrefix(expr, "$")
+	if !ok {
+		return Path{}, fmt.Errorf("invalid JSONPath %q: must start with '$'", expr)
+	}
+
+	path := Path{raw: expr}
+
+	for rest != "" {
+		var (
+			seg segment
+			err error
+		)
+
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			seg, rest, err = parseDescend(rest[2:])
+		case rest[0] == '.':
+			seg, rest, err = parseMember(rest[1:])
+		case rest[0] == '[':
+			seg, rest, err = parseBracket(rest[1:])
+		default:
+			err = fmt.Errorf("unexpected character %q", rest[0])
+		}
+
+		if err != nil {
+			return Path{}, fmt.Errorf("invalid JSONPath %q: %w", expr, err)
+		}
+
+		path.segments = append(path.segments, seg)
+	}
+
+	return path, nil
+}
+
+// String implements [fmt.Stringer] for a [Path], returning the original expression.
+func (p Path) String() string {
+	return p.raw
+}
+
+// Select returns every value in document matched by the path, in document order.
+//
+// Object members are visited in sorted key order so the result is deterministic. If
+// nothing matches, the returned slice is empty.
+func (p Path) Select(document any) []any {
+	nodes := []any{document}
+
+	for _, seg := range p.segments {
+		var next []any
+
+		for _, node := range nodes {
+			next = seg.apply(node, next)
+		}
+
+		nodes = next
+	}
+
+	return nodes
+}
+
+// Delete removes every value matched by the path from document, returning
+// the modified document.
+//
+// Objects are modified in place, arrays are rebuilt without the deleted elements
+// so callers must always use the returned document. Deleting the root ('$') returns nil.
+func (p Path) Delete(document any) any {
+	if len(p.segments) == 0 {
+		return nil
+	}
+
+	return remove(document, p.segments)
+}
+
+// remove deletes the values matched by segments from node, returning the modified node.
+func remove(node any, segments []segment) any {
+	seg, rest := segments[0], segments[1:]
+	last := len(rest) == 0
+
+	switch seg.kind {
+	case kindKey:
+		if object, ok := node.(map[string]any); ok {
+			node = removeKey(object, seg.key, rest)
+		}
+	case kindIndex:
+		array, ok := node.([]any)
+		if !ok {
+			return node
+		}
+
+		index := seg.index
+		if index < 0 {
+			index += len(array)
+		}
+
+		if index < 0 || index >= len(array) {
+			return node
+		}
+
+		if last {
+			return slices.Delete(slices.Clone(array), index, index+1)
+		}
+
+		array[index] = remove(array[index], rest)
+	case kindWildcard:
+		node = removeAll(node, rest)
+	case kindDescend:
+		switch value := node.(type) {
+		case map[string]any:
+			if seg.key == "*" {
+				return removeAll(value, rest)
+			}
+
+			removeKey(value, seg.key, rest)
+
+			for key, child := range value {
+				value[key] = remove(child, segments)
+			}
+		case []any:
+			if seg.key == "*" {
+				return removeAll(value, rest)
+			}
+
+			for i, child := range value {
+				value[i] = remove(child, segments)
+			}
+		}
+	}
+
+	return node
+}
+
+// removeKey deletes the member key from object if rest is empty, otherwise it removes
+// rest from the member's value.
+func removeKey(object map[string]any, key string, rest []segment) map[string]any {
+	child, exists := object[key]
+	if !exists {
+		return object
+	}
+
+	if len(rest) == 0 {
+		delete(object, key)
+	} else {
+		object[key] = remove(child, rest)
+	}
+
+	return object
+}
+
+// removeAll deletes every child of an object or array node if rest is empty, otherwise
+// it removes rest from every child.
+func removeAll(node any, rest []segment) any {
+	switch value := node.(type) {
+	case map[string]any:
+		for key, child := range value {
+			if len(rest) == 0 {
+				delete(value, key)
+			} else {
+				value[key] = remove(child, rest)
+			}
+		}
+
+		return value
+	case []any:
+		if len(rest) == 0 {
+			return []any{}
+		}
+
+		for i, child := range value {
+			value[i] = remove(child, rest)
+		}
+
+		return value
+	default:
+		return node
+	}
+}
+
+// apply appends every child of node matched by the segment to matches.
+func (s segment) apply(node any, matches []any) []any {
+	switch s.kind {
+	case kindKey:
+		if object, ok := node.(map[string]any); ok {
+			if value, exists := object[s.key]; exists {
+				matches = append(matches, value)
+			}
+		}
+	case kindIndex:
+		if array, ok := node.([]any); ok {
+			index := s.index
+			if index < 0 {
+				index += len(array)
+			}
+
+			if index >= 0 && index < len(array) {
+				matches = append(matches, array[index])
+			}
+		}
+	case kindWildcard:
+		matches = append(matches, children(node)...)
+	case kindDescend:
+		if object, ok := node.(map[string]any); ok {
+			if s.key == "*" {
+				matches = append(matches, children(object)...)
+			} else if value, exists := object[s.key]; exists {
+				matches = append(matches, value)
+			}
+		}
+
+		if array, ok := node.([]any); ok && s.key == "*" {
+			matches = append(matches, array...)
+		}
+
+		for _, child := range children(node) {
+			matches = s.apply(child, matches)
+		}
+	}
+
+	return matches
+}
+
+// children returns the direct children of an object or array node, objects
+// are visited in sorted key order.
+func children(node any) []any {
+	switch value := node.(type) {
+	case map[string]any:
+		result := make([]any, 0, len(value))
+		for _, key := range slices.Sorted(maps.Keys(value)) {
+			result = append(result, value[key])
+		}
+
+		return result
+	case []any:
+		return value
+	default:
+		return nil
+	}
+}
+
+// parseMember parses a dot member access, the leading '.' has already been consumed.
+func parseMember(rest string) (segment, string, error) {
+	name, rest := cutName(rest)
+	if name == "" {
+		return segment{}, rest, errors.New("empty member name")
+	}
+
+	if name == "*" {
+		return segment{kind: kindWildcard}, rest, nil
+	}
+
+	return segment{kind: kindKey, key: name}, rest, nil
+}
+
+// parseDescend parses a recursive descent, the leading '..' has already been consumed.
+func parseDescend(rest string) (segment, string, error) {
+	name, rest := cutName(rest)
+	if name == "" {
+		return segment{}, rest, errors.New("recursive descent requires a member name or '*'")
+	}
+
+	return segment{kind: kindDescend, key: name}, rest, nil
+}
+
+// parseBracket parses a bracketed selector, the leading '[' has already been consumed.
+func parseBracket(rest string) (segment, string, error) {
+	if rest != "" && (rest[0] == '\'' || rest[0] == '"') {
+		quote := rest[0]
+
+		end := strings.IndexByte(rest[1:], quote)
+		if end == -1 {
+			return segment{}, rest, errors.New("unterminated quoted member name")
+		}
+
+		key := rest[1 : end+1]
+		rest = rest[end+2:]
+
+		after, ok := strings.CutPrefix(rest, "]")
+		if !ok {
+			return segment{}, rest, errors.New("expected ']' after quoted member name")
+		}
+
+		return segment{kind: kindKey, key: key}, after, nil
+	}
+
+	inner, after, ok := strings.Cut(rest, "]")
+	if !ok {
+		return segment{}, rest, errors.New("unterminated '['")
+	}
+
+	inner = strings.TrimSpace(inner)
+
+	if inner == "*" {
+		return segment{kind: kindWildcard}, after, nil
+	}
+
+	index, err := strconv.Atoi(inner)
+	if err != nil {
+		return segment{}, rest, fmt.Errorf("invalid array index %q", inner)
+	}
+
+	return segment{kind: kindIndex, index: index}, after, nil
+}
+
+// cutName splits a member name off the front of rest, the name ends at the
+// next '.' or '['.
+func cutName(rest string) (name, remainder string) {
+	end := strings.IndexAny(rest, ".[")
+	if end == -1 {
+		return rest, ""
+	}
+
+	return rest[:end], rest[end:]
+}
@@ -0,0 +1,171 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go.followtheprocess.codes/test"
+	"go.followtheprocess.codes/zap/internal/jsonpath"
+)
+
+const document = `{
+  "name": "zap",
+  "count": 2,
+  "items": [
+    {"id": 1, "tags": ["a", "b"]},
+    {"id": 2, "tags": []}
+  ],
+  "nested": {"id": "deep", "with.dot": true}
+}`
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name string // Name of the test case
+		expr string // JSONPath expression under test
+		want string // Expected matches, encoded as JSON
+	}{
+		{name: "root", expr: "$", want: `[` + compact(document) + `]`},
+		{name: "member", expr: "$.name", want: `["zap"]`},
+		{name: "missing member", expr: "$.missing", want: `null`},
+		{name: "index", expr: "$.items[0].id", want: `[1]`},
+		{name: "negative index", expr: "$.items[-1].id", want: `[2]`},
+		{name: "index out of range", expr: "$.items[5]", want: `null`},
+		{name: "bracket member", expr: "$['nested']['with.dot']", want: `[true]`},
+		{name: "double quoted member", expr: `$["name"]`, want: `["zap"]`},
+		{name: "wildcard array", expr: "$.items[*].id", want: `[1,2]`},
+		{name: "wildcard object", expr: "$.nested.*", want: `["deep",true]`},
+		{name: "recursive descent", expr: "$..id", want: `[1,2,"deep"]`},
+		{name: "recursive wildcard", expr: "$.items[0]..*", want: `[1,["a","b"],"a","b"]`},
+		{name: "index on object", expr: "$.nested[0]", want: `null`},
+		{name: "member on array", expr: "$.items.id", want: `null`},
+	}
+
+	var doc any
+
+	test.Ok(t, json.Unmarshal([]byte(document), &doc))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := jsonpath.Parse(tt.expr)
+			test.Ok(t, err)
+
+			test.Equal(t, path.String(), tt.expr)
+
+			got, err := json.Marshal(path.Select(doc))
+			test.Ok(t, err)
+
+			test.Equal(t, string(got), tt.want)
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	tests := []struct {
+		name string // Name of the test case
+		expr string // JSONPath expression under test
+		want string // Expected document after deletion, encoded as JSON
+	}{
+		{name: "root", expr: "$", want: `null`},
+		{
+			name: "member",
+			expr: "$.name",
+			want: `{"count":2,"items":[{"id":1,"tags":["a","b"]},{"id":2,"tags":[]}],"nested":{"id":"deep","with.dot":true}}`,
+		},
+		{name: "missing member", expr: "$.missing", want: compact(document)},
+		{
+			name: "index",
+			expr: "$.items[0]",
+			want: `{"count":2,"items":[{"id":2,"tags":[]}],"name":"zap","nested":{"id":"deep","with.dot":true}}`,
+		},
+		{
+			name: "nested index",
+			expr: "$.items[0].tags[-1]",
+			want: `{"count":2,"items":[{"id":1,"tags":["a"]},{"id":2,"tags":[]}],"name":"zap","nested":{"id":"deep","with.dot":true}}`,
+		},
+		{
+			name: "wildcard member",
+			expr: "$.items[*].id",
+			want: `{"count":2,"items":[{"tags":["a","b"]},{"tags":[]}],"name":"zap","nested":{"id":"deep","with.dot":true}}`,
+		},
+		{
+			name: "wildcard last",
+			expr: "$.items[*]",
+			want: `{"count":2,"items":[],"name":"zap","nested":{"id":"deep","with.dot":true}}`,
+		},
+		{
+			name: "recursive descent",
+			expr: "$..id",
+			want: `{"count":2,"items":[{"tags":["a","b"]},{"tags":[]}],"name":"zap","nested":{"with.dot":true}}`,
+		},
+		{
+			name: "recursive wildcard",
+			expr: "$.nested..*",
+			want: `{"count":2,"items":[{"id":1,"tags":["a","b"]},{"id":2,"tags":[]}],"name":"zap","nested":{}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc any
+
+			test.Ok(t, json.Unmarshal([]byte(document), &doc))
+
+			path, err := jsonpath.Parse(tt.expr)
+			test.Ok(t, err)
+
+			got, err := json.Marshal(path.Delete(doc))
+			test.Ok(t, err)
+
+			test.Equal(t, string(got), tt.want)
+		})
+	}
+}
+
+// compact returns the JSON document with all insignificant whitespace removed, keys
+// are sorted as [json.Marshal] would.
+func compact(document string) string {
+	var doc any
+	if err := json.Unmarshal([]byte(document), &doc); err != nil {
+		panic(err)
+	}
+
+	out, err := json.Marshal(doc)
+	if err != nil {
+		panic(err)
+	}
+
+	return string(out)
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string // Name of the test case
+		expr string // JSONPath expression under test
+		want string // Expected error message
+	}{
+		{name: "empty", expr: "", want: `invalid JSONPath "": must start with '$'`},
+		{name: "no root", expr: "items[0]", want: `invalid JSONPath "items[0]": must start with '$'`},
+		{name: "empty member", expr: "$.", want: `invalid JSONPath "$.": empty member name`},
+		{name: "bad index", expr: "$.items[one]", want: `invalid JSONPath "$.items[one]": invalid array index "one"`},
+		{name: "unterminated bracket", expr: "$.items[0", want: `invalid JSONPath "$.items[0": unterminated '['`},
+		{
+			name: "unterminated quote",
+			expr: "$['name]",
+			want: `invalid JSONPath "$['name]": unterminated quoted member name`,
+		},
+		{
+			name: "bare descent",
+			expr: "$..",
+			want: `invalid JSONPath "$..": recursive descent requires a member name or '*'`,
+		},
+		{name: "junk", expr: "$items", want: `invalid JSONPath "$items": unexpected character 'i'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jsonpath.Parse(tt.expr)
+			test.Err(t, err)
+			test.Equal(t, err.Error(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package spec
+
+import (
+	"strings"
+
+	"go.followtheprocess.codes/zap/internal/syntax"
+)
+
+// Assertion is a declarative check to be made against the response to a request
+// when it is run with 'zap test'.
+type Assertion struct {
+	// Subject is the part of the response under test, one of 'status', 'body',
+	// 'header.<Name>' or a JSONPath expression into a JSON body e.g. '$.items[0].id'
+	Subject string `json:"subject,omitempty" toml:"subject,omitempty" yaml:"subject,omitempty"`
+	// Operator is the comparison to make e.g. '==', 'contains' or 'exists'
+	Operator string `json:"operator,omitempty" toml:"operator,omitempty" yaml:"operator,omitempty"`
+	// Value is the expected value, empty for operators that don't take one e.g. 'exists'
+	Value Template `json:"value,omitempty" toml:"value,omitempty" yaml:"value,omitempty"`
+	// Position is the position of the assertion in the .http file, used when
+	// reporting failures
+	Position syntax.Position `json:"-" toml:"-" yaml:"-"`
+}
+
+// String implements [fmt.Stringer] for an [Assertion], rendering it
+// as it would appear after '@assert' in a .http file.
+func (a Assertion) String() string {
+	if len(a.Value) == 0 {
+		return a.Subject + " " + a.Operator
+	}
+
+	return strings.Join([]string{a.Subject, a.Operator, a.Value.String()}, " ")
+}
@@ -0,0 +1,17 @@
+package spec
+
+// Capture stores part of the response to a request in a variable, for use in
+// the requests executed after it.
+type Capture struct {
+	// Name is the name of the variable the value is captured into
+	Name string `json:"name,omitempty" toml:"name,omitempty" yaml:"name,omitempty"`
+	// Source is the part of the response to capture, one of 'status', 'body', 'header.<Name>'
+	// or a JSONPath expression into the response e.g. '$.body.access_token' or '$.headers.ETag'
+	Source string `json:"source,omitempty" toml:"source,omitempty" yaml:"source,omitempty"`
+}
+
+// String implements [fmt.Stringer] for a [Capture], rendering it
+// as it would appear after '@capture' in a .http file.
+func (c Capture) String() string {
+	return c.Name + " = " + c.Source
+}
@@ -1,7 +1,8 @@
 package spec
 
 import (
-	"fmt"
+	"strconv"
+	"strings"
 )
 
 // Prompt represents a variable that requires the user to specify by responding to a prompt.
@@ -15,13 +16,59 @@ type Prompt struct {
 	// Value is the current value for the prompt variable, empty if
 	// not yet provided
 	Value string `json:"value,omitempty" toml:"value,omitempty" yaml:"value,omitempty"`
+
+	// Default is the value to use if the user does not provide one, optional
+	Default string `json:"default,omitempty" toml:"default,omitempty" yaml:"default,omitempty"`
+
+	// Type is the type the value must be e.g. "int" or "uuid", empty means any string
+	Type string `json:"type,omitempty" toml:"type,omitempty" yaml:"type,omitempty"`
+
+	// Choices are the values the user may choose from, empty means any value is allowed
+	Choices []string `json:"choices,omitempty" toml:"choices,omitempty" yaml:"choices,omitempty"`
+
+	// Secret marks the value as sensitive, it is masked when entered and
+	// redacted from logs and exports
+	Secret bool `json:"secret,omitempty" toml:"secret,omitempty" yaml:"secret,omitempty"`
 }
 
 // String implements [fmt.Stringer] for a [Prompt].
 func (p Prompt) String() string {
+	builder := &strings.Builder{}
+
+	builder.WriteString("@prompt")
+
+	if p.Secret {
+		builder.WriteString("-secret")
+	}
+
+	builder.WriteString(" " + p.Name)
+
+	if p.Type != "" {
+		builder.WriteString(":" + p.Type)
+	}
+
+	if len(p.Choices) != 0 {
+		builder.WriteString(" [" + strings.Join(p.Choices, "|") + "]")
+	}
+
+	if p.Default != "" {
+		builder.WriteString(" = " + quoteDefault(p.Default))
+	}
+
 	if p.Description != "" {
-		return fmt.Sprintf("@prompt %s %s\n", p.Name, p.Description)
+		builder.WriteString(" " + p.Description)
+	}
+
+	builder.WriteString("\n")
+
+	return builder.String()
+}
+
+// quoteDefault quotes a prompt default if it would otherwise run into the description.
+func quoteDefault(value string) string {
+	if strings.ContainsAny(value, " \t\"") {
+		return strconv.Quote(value)
 	}
 
-	return fmt.Sprintf("@prompt %s\n", p.Name)
+	return value
 }
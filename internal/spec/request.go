@@ -3,7 +3,6 @@ package spec
 import (
 	"fmt"
 	"maps"
-	"net/http"
 	"slices"
 	"strings"
 	"time"
@@ -12,19 +11,32 @@ import (
 // Request is a single HTTP request from a .http file as a canonical, fully resolved representation.
 //
 // All variable interpolations have been performed during resolving, the only thing that may be remaining
-// is prompts do not yet have values. During resolution, any prompts have had their idents declared
-// in Vars with the value of "zap::prompt::local::<ident>", to be replaced when the user is prompted
-// for the values.
+// are values only known at runtime: the answers to prompts, values captured from responses and references
+// to other requests. These are kept as deferred interpolations in a [Template], to be evaluated immediately
+// before the request is executed.
 type Request struct {
 	// Request scoped variables
-	Vars map[string]string `json:"vars,omitempty" toml:"vars,omitempty" yaml:"vars,omitempty"`
+	Vars map[string]Template `json:"vars,omitempty" toml:"vars,omitempty" yaml:"vars,omitempty"`
 
 	// Request headers.
-	Headers http.Header `json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty"`
+	Headers Headers `json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty"`
 
 	// Request scoped prompts, the user will be asked to provide values for each of these
 	// whenever this particular request is invoked.
 	Prompts map[string]Prompt `json:"prompts,omitempty" toml:"prompts,omitempty" yaml:"prompts,omitempty"`
+	// Assertions to make against the response when run as a test.
+	Assertions []Assertion `json:"assertions,omitempty" toml:"assertions,omitempty" yaml:"assertions,omitempty"`
+	// Values to capture from the response into variables for later requests.
+	Captures []Capture `json:"captures,omitempty" toml:"captures,omitempty" yaml:"captures,omitempty"`
+	// JSONPath expressions of response body fields to ignore when comparing the response
+	// to the response reference e.g. '$.createdAt', in addition to any in the file.
+	Ignore []string `json:"ignore,omitempty" toml:"ignore,omitempty" yaml:"ignore,omitempty"`
+	// Names of response headers to ignore when comparing the response to the response reference,
+	// in addition to any in the file.
+	IgnoreHeaders []string `json:"ignoreHeaders,omitempty" toml:"ignoreHeaders,omitempty" yaml:"ignoreHeaders,omitempty"`
+	// Names of the other requests this one references e.g. '{{ login.response.body.$.token }}',
+	// which must be executed before it.
+	DependsOn []string `json:"dependsOn,omitempty" toml:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
 
 	// Optional name, if empty request should be named after it's index e.g. "#1"
 	Name string `json:"name,omitempty" toml:"name,omitempty" yaml:"name,omitempty"`
@@ -36,25 +48,25 @@ type Request struct {
 	Method string `json:"method,omitempty" toml:"method,omitempty" yaml:"method,omitempty"`
 
 	// The complete URL,
-	URL string `json:"url,omitempty" toml:"url,omitempty" yaml:"url,omitempty"`
+	URL Template `json:"url,omitempty" toml:"url,omitempty" yaml:"url,omitempty"`
 
 	// Version of the HTTP protocol to use e.g. "1.2"
 	HTTPVersion string `json:"httpVersion,omitempty" toml:"httpVersion,omitempty" yaml:"httpVersion,omitempty"`
 
 	// If the body is to be populated by reading a local file, this is the path
 	// to that local file (relative to the .http file)
-	BodyFile string `json:"bodyFile,omitempty" toml:"bodyFile,omitempty" yaml:"bodyFile,omitempty"`
+	BodyFile Template `json:"bodyFile,omitempty" toml:"bodyFile,omitempty" yaml:"bodyFile,omitempty"`
 
 	// If a response redirect was provided, this is the path to the local file into
 	// which to write the response (relative to the .http file)
-	ResponseFile string `json:"responseFile,omitempty" toml:"responseFile,omitempty" yaml:"responseFile,omitempty"`
+	ResponseFile Template `json:"responseFile,omitempty" toml:"responseFile,omitempty" yaml:"responseFile,omitempty"`
 
 	// If a response reference was provided, this is the path to the local file
 	// with which to compare the current response (relative to the .http file)
-	ResponseRef string `json:"responseRef,omitempty" toml:"responseRef,omitempty" yaml:"responseRef,omitempty"`
+	ResponseRef Template `json:"responseRef,omitempty" toml:"responseRef,omitempty" yaml:"responseRef,omitempty"`
 
 	// Request body, if provided inline, all interpolations are done.
-	Body string `json:"body,omitempty" toml:"body,omitempty" yaml:"body,omitempty"`
+	Body Template `json:"body,omitempty" toml:"body,omitempty" yaml:"body,omitempty"`
 
 	// Request scoped timeout, overrides global if set
 	Timeout time.Duration `json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty"`
@@ -83,7 +95,7 @@ func (r Request) String() string {
 	}
 
 	for _, name := range slices.Sorted(maps.Keys(r.Prompts)) {
-		builder.WriteString(r.Prompts[name].String())
+		builder.WriteString("# " + r.Prompts[name].String())
 	}
 
 	for _, key := range slices.Sorted(maps.Keys(r.Vars)) {
@@ -101,7 +113,23 @@ func (r Request) String() string {
 
 	// Same with no-redirect
 	if r.NoRedirect {
-		fmt.Fprintf(builder, "# @no-redirect = %v\n", r.NoRedirect)
+		builder.WriteString("# @no-redirect\n")
+	}
+
+	for _, assertion := range r.Assertions {
+		fmt.Fprintf(builder, "# @assert %s\n", assertion)
+	}
+
+	for _, capture := range r.Captures {
+		fmt.Fprintf(builder, "# @capture %s\n", capture)
+	}
+
+	for _, path := range r.Ignore {
+		fmt.Fprintf(builder, "# @ignore %s\n", path)
+	}
+
+	for _, header := range r.IgnoreHeaders {
+		fmt.Fprintf(builder, "# @ignore-header %s\n", header)
 	}
 
 	if r.HTTPVersion != "" {
@@ -110,26 +138,30 @@ func (r Request) String() string {
 		fmt.Fprintf(builder, "%s %s\n", r.Method, r.URL)
 	}
 
-	_ = r.Headers.Write(builder) //nolint:errcheck // Not much we can do if it can't write headers
+	for _, key := range slices.Sorted(maps.Keys(r.Headers)) {
+		for _, value := range r.Headers[key] {
+			fmt.Fprintf(builder, "%s: %s\n", key, value)
+		}
+	}
 
 	// Separate the body section
-	if r.Body != "" || r.BodyFile != "" || r.ResponseFile != "" {
+	if len(r.Body) != 0 || len(r.BodyFile) != 0 || len(r.ResponseFile) != 0 {
 		builder.WriteString("\n")
 	}
 
-	if r.BodyFile != "" {
+	if len(r.BodyFile) != 0 {
 		fmt.Fprintf(builder, "< %s\n", r.BodyFile)
 	}
 
-	if r.Body != "" {
+	if len(r.Body) != 0 {
 		fmt.Fprintf(builder, "%s\n", r.Body)
 	}
 
-	if r.ResponseFile != "" {
+	if len(r.ResponseFile) != 0 {
 		fmt.Fprintf(builder, "> %s\n", r.ResponseFile)
 	}
 
-	if r.ResponseRef != "" {
+	if len(r.ResponseRef) != 0 {
 		fmt.Fprintf(builder, "<> %s\n", r.ResponseRef)
 	}
 
@@ -23,15 +23,14 @@ import (
 // Interpolation has been performed during resolution so this is a concrete representation
 // ready to use.
 //
-// The only exception are global prompts which do not have values yet. During resolution,
-// any prompts have had their idents declared in Vars with the value of "zap::prompt::local::<ident>",
-// to be replaced when the user is prompted for the values.
+// The only exception are values only known at runtime, such as the answers to prompts. These
+// are kept as deferred interpolations in a [Template], to be evaluated when the file is executed.
 type File struct {
 	// Name of the file (or @name in global scope if given)
 	Name string `json:"name,omitempty" toml:"name,omitempty" yaml:"name,omitempty"`
 
 	// Global variables
-	Vars map[string]string `json:"vars,omitempty" toml:"vars,omitempty" yaml:"vars,omitempty"`
+	Vars map[string]Template `json:"vars,omitempty" toml:"vars,omitempty" yaml:"vars,omitempty"`
 
 	// Global prompts, the user will be asked to provide values for each of these each time the
 	// file is parsed.
@@ -46,6 +45,11 @@ type File struct {
 	// Global connection timeout for all requests.
 	ConnectionTimeout time.Duration `json:"connectionTimeout,omitempty" toml:"connectionTimeout,omitempty" yaml:"connectionTimeout,omitempty"`
 
+	// JSONPath expressions of response body fields to ignore when comparing any response
+	// to its response reference e.g. '$.createdAt'.
+	Ignore []string `json:"ignore,omitempty" toml:"ignore,omitempty" yaml:"ignore,omitempty"`
+	// Names of response headers to ignore when comparing any response to its response reference.
+	IgnoreHeaders []string `json:"ignoreHeaders,omitempty" toml:"ignoreHeaders,omitempty" yaml:"ignoreHeaders,omitempty"`
 	// Disable following redirects globally across all requests.
 	NoRedirect bool `json:"noRedirect,omitempty" toml:"noRedirect,omitempty" yaml:"noRedirect,omitempty"`
 }
@@ -78,13 +82,26 @@ func (f File) String() string {
 
 	// Same with no-redirect
 	if f.NoRedirect {
-		fmt.Fprintf(builder, "@no-redirect = %v\n", f.NoRedirect)
+		builder.WriteString("@no-redirect\n")
+	}
+
+	for _, path := range f.Ignore {
+		fmt.Fprintf(builder, "@ignore %s\n", path)
+	}
+
+	for _, header := range f.IgnoreHeaders {
+		fmt.Fprintf(builder, "@ignore-header %s\n", header)
 	}
 
 	// Separate the request start from the globals by a newline
 	builder.WriteByte('\n')
 
-	for _, request := range f.Requests {
+	for i, request := range f.Requests {
+		// Separate each request from the one before by a newline
+		if i > 0 {
+			builder.WriteByte('\n')
+		}
+
 		builder.WriteString(request.String())
 	}
 
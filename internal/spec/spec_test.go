@@ -3,10 +3,12 @@ package spec_test
 import (
 	"flag"
 	"net/http"
+	"slices"
 	"testing"
 	"time"
 
 	"go.followtheprocess.codes/snapshot"
+	"go.followtheprocess.codes/test"
 	"go.followtheprocess.codes/zap/internal/spec"
 )
 
@@ -34,9 +36,9 @@ func TestFormat(t *testing.T) {
 			name: "name and vars",
 			file: spec.File{
 				Name: "SomeVars",
-				Vars: map[string]string{
-					"base":  "https://url.com/api/v1",
-					"hello": "world",
+				Vars: map[string]spec.Template{
+					"base":  spec.Text("https://url.com/api/v1"),
+					"hello": spec.Text("world"),
 				},
 			},
 		},
@@ -67,19 +69,46 @@ func TestFormat(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "typed prompts",
+			file: spec.File{
+				Name: "PromptMe",
+				Prompts: map[string]spec.Prompt{
+					"env": {
+						Name:        "env",
+						Description: "The environment",
+						Default:     "dev",
+						Choices:     []string{"dev", "staging", "prod"},
+					},
+					"greeting": {
+						Name:    "greeting",
+						Default: "hello there",
+					},
+					"page": {
+						Name:    "page",
+						Type:    "int",
+						Default: "1",
+					},
+					"password": {
+						Name:   "password",
+						Secret: true,
+					},
+				},
+			},
+		},
 		{
 			name: "with simple request",
 			file: spec.File{
 				Name: "Requests",
-				Vars: map[string]string{
-					"base": "https://api.com/v1",
+				Vars: map[string]spec.Template{
+					"base": spec.Text("https://api.com/v1"),
 				},
 				Requests: []spec.Request{
 					{
 						Name:    "GetItem",
 						Comment: "A simple request",
 						Method:  http.MethodGet,
-						URL:     "https://api.com/v1/items/123",
+						URL:     spec.Text("https://api.com/v1/items/123"),
 					},
 				},
 			},
@@ -88,18 +117,18 @@ func TestFormat(t *testing.T) {
 			name: "request with variables",
 			file: spec.File{
 				Name: "Requests",
-				Vars: map[string]string{
-					"base": "https://api.com/v1",
+				Vars: map[string]spec.Template{
+					"base": spec.Text("https://api.com/v1"),
 				},
 				Requests: []spec.Request{
 					{
 						Name: "GetItem",
-						Vars: map[string]string{
-							"test": "yes",
+						Vars: map[string]spec.Template{
+							"test": spec.Text("yes"),
 						},
 						Comment: "A simple request",
 						Method:  http.MethodGet,
-						URL:     "https://api.com/v1/items/123",
+						URL:     spec.Text("https://api.com/v1/items/123"),
 					},
 				},
 			},
@@ -108,8 +137,8 @@ func TestFormat(t *testing.T) {
 			name: "with http version",
 			file: spec.File{
 				Name: "Requests",
-				Vars: map[string]string{
-					"base": "https://api.com/v1",
+				Vars: map[string]spec.Template{
+					"base": spec.Text("https://api.com/v1"),
 				},
 				Requests: []spec.Request{
 					{
@@ -117,7 +146,7 @@ func TestFormat(t *testing.T) {
 						Comment:     "A simple request",
 						Method:      http.MethodGet,
 						HTTPVersion: "HTTP/1.2",
-						URL:         "https://api.com/v1/items/123",
+						URL:         spec.Text("https://api.com/v1/items/123"),
 					},
 				},
 			},
@@ -126,18 +155,18 @@ func TestFormat(t *testing.T) {
 			name: "request headers",
 			file: spec.File{
 				Name: "Requests",
-				Vars: map[string]string{
-					"base": "https://api.com/v1",
+				Vars: map[string]spec.Template{
+					"base": spec.Text("https://api.com/v1"),
 				},
 				Requests: []spec.Request{
 					{
 						Name:   "Another Request",
 						Method: http.MethodPost,
-						URL:    "https://api.com/v1/items/123",
-						Headers: http.Header{
-							"Accept":        []string{"application/json"},
-							"Content-Type":  []string{"application/json"},
-							"Authorization": []string{"Bearer xxxxx"},
+						URL:    spec.Text("https://api.com/v1/items/123"),
+						Headers: spec.Headers{
+							"Accept":        {spec.Text("application/json")},
+							"Content-Type":  {spec.Text("application/json")},
+							"Authorization": {spec.Text("Bearer xxxxx")},
 						},
 					},
 				},
@@ -147,14 +176,14 @@ func TestFormat(t *testing.T) {
 			name: "request with timeouts",
 			file: spec.File{
 				Name: "Requests",
-				Vars: map[string]string{
-					"base": "https://api.com/v1",
+				Vars: map[string]spec.Template{
+					"base": spec.Text("https://api.com/v1"),
 				},
 				Requests: []spec.Request{
 					{
 						Name:              "Another Request",
 						Method:            http.MethodPost,
-						URL:               "https://api.com/v1/items/123",
+						URL:               spec.Text("https://api.com/v1/items/123"),
 						Timeout:           3 * time.Second,
 						ConnectionTimeout: 500 * time.Millisecond,
 						NoRedirect:        true,
@@ -166,15 +195,15 @@ func TestFormat(t *testing.T) {
 			name: "request with body file",
 			file: spec.File{
 				Name: "Requests",
-				Vars: map[string]string{
-					"base": "https://api.com/v1",
+				Vars: map[string]spec.Template{
+					"base": spec.Text("https://api.com/v1"),
 				},
 				Requests: []spec.Request{
 					{
 						Name:     "Another Request",
 						Method:   http.MethodPost,
-						URL:      "https://api.com/v1/items/123",
-						BodyFile: "./body.json",
+						URL:      spec.Text("https://api.com/v1/items/123"),
+						BodyFile: spec.Text("./body.json"),
 					},
 				},
 			},
@@ -183,15 +212,15 @@ func TestFormat(t *testing.T) {
 			name: "request with body",
 			file: spec.File{
 				Name: "Requests",
-				Vars: map[string]string{
-					"base": "https://api.com/v1",
+				Vars: map[string]spec.Template{
+					"base": spec.Text("https://api.com/v1"),
 				},
 				Requests: []spec.Request{
 					{
 						Name:   "Another Request",
 						Method: http.MethodPost,
-						URL:    "https://api.com/v1/items/123",
-						Body:   `{"some": "json", "here": "yes"}`,
+						URL:    spec.Text("https://api.com/v1/items/123"),
+						Body:   spec.Text(`{"some": "json", "here": "yes"}`),
 					},
 				},
 			},
@@ -200,15 +229,15 @@ func TestFormat(t *testing.T) {
 			name: "request with response file",
 			file: spec.File{
 				Name: "Requests",
-				Vars: map[string]string{
-					"base": "https://api.com/v1",
+				Vars: map[string]spec.Template{
+					"base": spec.Text("https://api.com/v1"),
 				},
 				Requests: []spec.Request{
 					{
 						Name:         "Another Request",
 						Method:       http.MethodPost,
-						URL:          "https://api.com/v1/items/123",
-						ResponseFile: "./response.json",
+						URL:          spec.Text("https://api.com/v1/items/123"),
+						ResponseFile: spec.Text("./response.json"),
 					},
 				},
 			},
@@ -217,15 +246,15 @@ func TestFormat(t *testing.T) {
 			name: "request with response ref",
 			file: spec.File{
 				Name: "Requests",
-				Vars: map[string]string{
-					"base": "https://api.com/v1",
+				Vars: map[string]spec.Template{
+					"base": spec.Text("https://api.com/v1"),
 				},
 				Requests: []spec.Request{
 					{
 						Name:        "Another Request",
 						Method:      http.MethodPost,
-						URL:         "https://api.com/v1/items/123",
-						ResponseRef: "./response.200.json",
+						URL:         spec.Text("https://api.com/v1/items/123"),
+						ResponseRef: spec.Text("./response.200.json"),
 					},
 				},
 			},
@@ -234,8 +263,8 @@ func TestFormat(t *testing.T) {
 			name: "request with prompt",
 			file: spec.File{
 				Name: "Requests",
-				Vars: map[string]string{
-					"base": "https://api.com/v1",
+				Vars: map[string]spec.Template{
+					"base": spec.Text("https://api.com/v1"),
 				},
 				Requests: []spec.Request{
 					{
@@ -250,8 +279,80 @@ func TestFormat(t *testing.T) {
 							},
 						},
 						Method:       http.MethodPost,
-						URL:          "https://api.com/v1/items/123",
-						ResponseFile: "./response.json",
+						URL:          spec.Text("https://api.com/v1/items/123"),
+						ResponseFile: spec.Text("./response.json"),
+					},
+				},
+			},
+		},
+		{
+			name: "request with assertions",
+			file: spec.File{
+				Name: "Requests",
+				Requests: []spec.Request{
+					{
+						Name:   "Create",
+						Method: http.MethodPost,
+						URL:    spec.Text("https://api.com/v1/items"),
+						Assertions: []spec.Assertion{
+							{Subject: "status", Operator: "==", Value: spec.Text("201")},
+							{Subject: "header.Content-Type", Operator: "contains", Value: spec.Text("json")},
+							{Subject: "$.items[0].id", Operator: "exists"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "request with captures",
+			file: spec.File{
+				Name: "Captures",
+				Requests: []spec.Request{
+					{
+						Name:   "Login",
+						Method: http.MethodPost,
+						URL:    spec.Text("https://api.com/v1/login"),
+						Captures: []spec.Capture{
+							{Name: "token", Source: "$.body.access_token"},
+							{Name: "etag", Source: "header.ETag"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "ignored fields",
+			file: spec.File{
+				Name:          "Ignores",
+				Ignore:        []string{"$..id"},
+				IgnoreHeaders: []string{"Date"},
+				Requests: []spec.Request{
+					{
+						Name:          "List",
+						Method:        http.MethodGet,
+						URL:           spec.Text("https://api.com/v1/items"),
+						ResponseRef:   spec.Text("items.json"),
+						Ignore:        []string{"$.createdAt", "$.items[*].updatedAt"},
+						IgnoreHeaders: []string{"X-Request-Id"},
+					},
+				},
+			},
+		},
+		{
+			name: "deferred",
+			file: spec.File{
+				Name: "Deferred",
+				Vars: map[string]spec.Template{
+					"literal": spec.Text("{{ not-a-variable }}"),
+				},
+				Requests: []spec.Request{
+					{
+						Name:   "Item",
+						Method: http.MethodGet,
+						URL:    spec.Template{{Text: "https://api.com/v1/items/"}, {Expr: "id"}},
+						Headers: spec.Headers{
+							"Authorization": {spec.Template{{Text: "Bearer "}, {Expr: "login.response.body.$.token"}}},
+						},
 					},
 				},
 			},
@@ -265,3 +366,14 @@ func TestFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestTemplateConcat(t *testing.T) {
+	got := spec.Text("Bearer ").Concat(spec.Expr("token")).Concat(spec.Text("")).Concat(spec.Text(" ")).Concat(spec.Text("!"))
+
+	want := spec.Template{{Text: "Bearer "}, {Expr: "token"}, {Text: " !"}}
+
+	test.EqualFunc(t, got, want, slices.Equal)
+	test.Equal(t, got.String(), "Bearer {{ token }} !")
+	test.True(t, got.Deferred())
+	test.False(t, spec.Text("{{ token }}").Deferred())
+}
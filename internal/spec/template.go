@@ -0,0 +1,147 @@
+package spec
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Template is a value that may contain interpolations only evaluated at runtime, the answers
+// to prompts, values captured from responses and references to other requests.
+//
+// Everything else was interpolated when the file was resolved and is literal text, even if
+// it looks like an interpolation e.g. the contents of a file read by '$file'.
+type Template []Segment
+
+// Segment is part of a [Template], either literal text or an interpolation deferred
+// until runtime.
+type Segment struct {
+	// Text is literal text, empty if the segment is an interpolation
+	Text string
+
+	// Expr is the source of the expression inside a deferred interpolation e.g. 'token'
+	// for '{{ token }}', empty if the segment is text
+	Expr string
+}
+
+// Text returns a [Template] of literal text.
+func Text(text string) Template {
+	if text == "" {
+		return nil
+	}
+
+	return Template{{Text: text}}
+}
+
+// Expr returns a [Template] of a single interpolation of expression, deferred until runtime.
+func Expr(expression string) Template {
+	return Template{{Expr: expression}}
+}
+
+// Deferred reports whether the template contains any interpolations deferred until runtime.
+func (t Template) Deferred() bool {
+	for _, segment := range t {
+		if segment.Expr != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Concat returns the template followed by other, joining adjacent text.
+func (t Template) Concat(other Template) Template {
+	result := make(Template, 0, len(t)+len(other))
+
+	for _, segment := range slices.Concat(t, other) {
+		if segment == (Segment{}) {
+			continue
+		}
+
+		last := len(result) - 1
+		if last >= 0 && segment.Expr == "" && result[last].Expr == "" {
+			result[last].Text += segment.Text
+			continue
+		}
+
+		result = append(result, segment)
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+
+	return result
+}
+
+// String implements [fmt.Stringer] for a [Template], rendering it as it would be written
+// in a .http file, deferred interpolations as e.g. '{{ token }}'.
+func (t Template) String() string {
+	builder := &strings.Builder{}
+
+	for _, segment := range t {
+		if segment.Expr != "" {
+			builder.WriteString("{{ " + segment.Expr + " }}")
+		} else {
+			builder.WriteString(segment.Text)
+		}
+	}
+
+	return builder.String()
+}
+
+// MarshalText implements [encoding.TextMarshaler] for a [Template], it is exported as
+// it is written, see [Template.String].
+func (t Template) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler] for a [Template], the text is
+// taken literally.
+func (t *Template) UnmarshalText(text []byte) error {
+	*t = Text(string(text))
+	return nil
+}
+
+// Headers are the headers of a [Request], like [http.Header] but the values are
+// templates. Keys are canonicalised by [http.CanonicalHeaderKey].
+type Headers map[string][]Template
+
+// Add adds value to the values of the header called key.
+func (h Headers) Add(key string, value Template) {
+	key = http.CanonicalHeaderKey(key)
+	h[key] = append(h[key], value)
+}
+
+// Set sets the value of the header called key, replacing any it already had.
+func (h Headers) Set(key string, value Template) {
+	h[http.CanonicalHeaderKey(key)] = []Template{value}
+}
+
+// Get returns the first value of the header called key, or nil if it has none.
+func (h Headers) Get(key string) Template {
+	values := h[http.CanonicalHeaderKey(key)]
+	if len(values) == 0 {
+		return nil
+	}
+
+	return values[0]
+}
+
+// Header returns the headers as a [http.Header], each value as it is written,
+// see [Template.String].
+func (h Headers) Header() http.Header {
+	if h == nil {
+		return nil
+	}
+
+	header := make(http.Header, len(h))
+
+	for key, values := range h {
+		for _, value := range values {
+			header[key] = append(header[key], value.String())
+		}
+	}
+
+	return header
+}
@@ -68,6 +68,23 @@ func TestNode(t *testing.T) {
 			end:   token.Token{Kind: token.Ident, Start: 1, End: 4},
 			kind:  ast.KindBuiltin,
 		},
+		{
+			name: "builtin with args",
+			// $timestamp -1 d
+			node: ast.Builtin{
+				Args: []ast.TextLiteral{
+					{Value: "-1", Token: token.Token{Kind: token.Text, Start: 11, End: 13}, Type: ast.KindTextLiteral},
+					{Value: "d", Token: token.Token{Kind: token.Ident, Start: 14, End: 15}, Type: ast.KindTextLiteral},
+				},
+				Name:   "timestamp",
+				Dollar: token.Token{Kind: token.Dollar, Start: 0, End: 1},
+				Token:  token.Token{Kind: token.Ident, Start: 1, End: 10},
+				Type:   ast.KindBuiltin,
+			},
+			start: token.Token{Kind: token.Dollar, Start: 0, End: 1},
+			end:   token.Token{Kind: token.Ident, Start: 14, End: 15},
+			kind:  ast.KindBuiltin,
+		},
 		{
 			name: "var statement",
 			// @variable = sometext
@@ -163,6 +180,58 @@ func TestNode(t *testing.T) {
 			end:   token.Token{Kind: token.Ident, Start: 8, End: 10}, // End returns the ident
 			kind:  ast.KindPrompt,
 		},
+		{
+			name: "prompt choices",
+			node: ast.PromptStatement{
+				Ident: ast.Ident{
+					Name:  "env",
+					Token: token.Token{Kind: token.Ident, Start: 8, End: 11},
+					Type:  ast.KindIdent,
+				},
+				Choices: []ast.TextLiteral{
+					{
+						Value: "dev",
+						Token: token.Token{Kind: token.Text, Start: 13, End: 16},
+						Type:  ast.KindTextLiteral,
+					},
+					{
+						Value: "prod",
+						Token: token.Token{Kind: token.Text, Start: 17, End: 21},
+						Type:  ast.KindTextLiteral,
+					},
+				},
+				At:   token.Token{Kind: token.At, Start: 0, End: 1},
+				Type: ast.KindPrompt,
+			},
+			start: token.Token{Kind: token.At, Start: 0, End: 1},
+			end:   token.Token{Kind: token.Text, Start: 17, End: 21}, // End returns the last choice
+			kind:  ast.KindPrompt,
+		},
+		{
+			name: "prompt default",
+			node: ast.PromptStatement{
+				Ident: ast.Ident{
+					Name:  "page",
+					Token: token.Token{Kind: token.Ident, Start: 8, End: 12},
+					Type:  ast.KindIdent,
+				},
+				ValueType: ast.Ident{
+					Name:  "int",
+					Token: token.Token{Kind: token.Ident, Start: 13, End: 16},
+					Type:  ast.KindIdent,
+				},
+				Default: ast.TextLiteral{
+					Value: "1",
+					Token: token.Token{Kind: token.Text, Start: 19, End: 20},
+					Type:  ast.KindTextLiteral,
+				},
+				At:   token.Token{Kind: token.At, Start: 0, End: 1},
+				Type: ast.KindPrompt,
+			},
+			start: token.Token{Kind: token.At, Start: 0, End: 1},
+			end:   token.Token{Kind: token.Text, Start: 19, End: 20}, // End returns the default
+			kind:  ast.KindPrompt,
+		},
 		{
 			name: "comment",
 			node: ast.Comment{
@@ -490,6 +559,60 @@ func TestNode(t *testing.T) {
 			end:   token.Token{Kind: token.CloseInterp, Start: 9, End: 11},
 			kind:  ast.KindInterpolatedExpression,
 		},
+		{
+			// $random.alphanumeric(8)
+			name: "call",
+			node: ast.CallExpression{
+				Func: ast.SelectorExpression{
+					Expr: ast.Builtin{
+						Name:   "random",
+						Dollar: token.Token{Kind: token.Dollar, Start: 0, End: 1},
+						Token:  token.Token{Kind: token.Ident, Start: 1, End: 7},
+						Type:   ast.KindBuiltin,
+					},
+					Selector: ast.Ident{
+						Name:  "alphanumeric",
+						Token: token.Token{Kind: token.Ident, Start: 8, End: 20},
+						Type:  ast.KindIdent,
+					},
+					Type: ast.KindSelector,
+				},
+				Args: []ast.Expression{
+					ast.TextLiteral{
+						Value: "8",
+						Token: token.Token{Kind: token.Text, Start: 21, End: 22},
+						Type:  ast.KindTextLiteral,
+					},
+				},
+				Open:  token.Token{Kind: token.LeftParen, Start: 20, End: 21},
+				Close: token.Token{Kind: token.RightParen, Start: 22, End: 23},
+				Type:  ast.KindCall,
+			},
+			start: token.Token{Kind: token.Dollar, Start: 0, End: 1},
+			end:   token.Token{Kind: token.RightParen, Start: 22, End: 23},
+			kind:  ast.KindCall,
+		},
+		{
+			// user + ":"
+			name: "binary",
+			node: ast.BinaryExpression{
+				Left: ast.Ident{
+					Name:  "user",
+					Token: token.Token{Kind: token.Ident, Start: 0, End: 4},
+					Type:  ast.KindIdent,
+				},
+				Right: ast.TextLiteral{
+					Value: ":",
+					Token: token.Token{Kind: token.Text, Start: 7, End: 10},
+					Type:  ast.KindTextLiteral,
+				},
+				Op:   token.Token{Kind: token.Plus, Start: 5, End: 6},
+				Type: ast.KindBinary,
+			},
+			start: token.Token{Kind: token.Ident, Start: 0, End: 4},
+			end:   token.Token{Kind: token.Text, Start: 7, End: 10},
+			kind:  ast.KindBinary,
+		},
 		{
 			// {{ $env.SOME_VAR }}
 			name: "selector",
@@ -528,6 +651,37 @@ func TestNode(t *testing.T) {
 			end:   token.Token{Kind: token.Ident, Start: 9, End: 17},
 			kind:  ast.KindSelector,
 		},
+		{
+			// {{ login.response.body.$.token }}
+			name: "selector query",
+			node: ast.SelectorExpression{
+				Expr: ast.Ident{
+					Name:  "body",
+					Token: token.Token{Kind: token.Ident, Start: 18, End: 22},
+					Type:  ast.KindIdent,
+				},
+				Selector: ast.Query{
+					Value: "$.token",
+					Token: token.Token{Kind: token.Query, Start: 23, End: 30},
+					Type:  ast.KindQuery,
+				},
+				Type: ast.KindSelector,
+			},
+			start: token.Token{Kind: token.Ident, Start: 18, End: 22},
+			end:   token.Token{Kind: token.Query, Start: 23, End: 30},
+			kind:  ast.KindSelector,
+		},
+		{
+			name: "query",
+			node: ast.Query{
+				Value: "//book/@title",
+				Token: token.Token{Kind: token.Query, Start: 23, End: 36},
+				Type:  ast.KindQuery,
+			},
+			start: token.Token{Kind: token.Query, Start: 23, End: 36},
+			end:   token.Token{Kind: token.Query, Start: 23, End: 36},
+			kind:  ast.KindQuery,
+		},
 		{
 			name: "body",
 			node: ast.Body{
@@ -627,6 +781,68 @@ func TestNode(t *testing.T) {
 			end:   token.Token{Kind: token.HTTPVersion, Start: 1, End: 6},
 			kind:  ast.KindHTTPVersion,
 		},
+		{
+			name: "assert",
+			// # @assert status == 200
+			node: ast.AssertStatement{
+				Value: ast.TextLiteral{
+					Value: "200",
+					Token: token.Token{Kind: token.Text, Start: 22, End: 25},
+					Type:  ast.KindTextLiteral,
+				},
+				Subject: ast.TextLiteral{
+					Value: "status",
+					Token: token.Token{Kind: token.Text, Start: 10, End: 16},
+					Type:  ast.KindTextLiteral,
+				},
+				Operator: "==",
+				OpToken:  token.Token{Kind: token.Operator, Start: 17, End: 19},
+				At:       token.Token{Kind: token.At, Start: 2, End: 3},
+				Type:     ast.KindAssert,
+			},
+			start: token.Token{Kind: token.At, Start: 2, End: 3},
+			end:   token.Token{Kind: token.Text, Start: 22, End: 25},
+			kind:  ast.KindAssert,
+		},
+		{
+			name: "assert no value",
+			// # @assert $.id exists
+			node: ast.AssertStatement{
+				Subject: ast.TextLiteral{
+					Value: "$.id",
+					Token: token.Token{Kind: token.Text, Start: 10, End: 14},
+					Type:  ast.KindTextLiteral,
+				},
+				Operator: "exists",
+				OpToken:  token.Token{Kind: token.Operator, Start: 15, End: 21},
+				At:       token.Token{Kind: token.At, Start: 2, End: 3},
+				Type:     ast.KindAssert,
+			},
+			start: token.Token{Kind: token.At, Start: 2, End: 3},
+			end:   token.Token{Kind: token.Operator, Start: 15, End: 21}, // End returns the operator
+			kind:  ast.KindAssert,
+		},
+		{
+			name: "capture",
+			// # @capture etag = header.ETag
+			node: ast.CaptureStatement{
+				Source: ast.TextLiteral{
+					Value: "header.ETag",
+					Token: token.Token{Kind: token.Text, Start: 18, End: 29},
+					Type:  ast.KindTextLiteral,
+				},
+				Ident: ast.Ident{
+					Name:  "etag",
+					Token: token.Token{Kind: token.Ident, Start: 11, End: 15},
+					Type:  ast.KindIdent,
+				},
+				At:   token.Token{Kind: token.At, Start: 2, End: 3},
+				Type: ast.KindCapture,
+			},
+			start: token.Token{Kind: token.At, Start: 2, End: 3},
+			end:   token.Token{Kind: token.Text, Start: 18, End: 29},
+			kind:  ast.KindCapture,
+		},
 	}
 
 	for _, tt := range tests {
@@ -43,10 +43,11 @@ func (i Ident) expressionNode() {}
 // The Builtin AST node is functionality identical to an [Ident], but must
 // be separate to allow differentiating builtins from regular idents.
 type Builtin struct {
-	Name   string      `yaml:"name"`   // Name is the name of the builtin ident.
-	Dollar token.Token `yaml:"dollar"` // Dollar is the opening [token.Dollar].
-	Token  token.Token `yaml:"token"`  // The [token.Ident] token.
-	Type   Kind        `yaml:"type"`   // Type is the kind of ast node, in this case [KindBuiltin].
+	Args   []TextLiteral `yaml:"args,omitempty"` // Args are any space separated arguments e.g. '$timestamp -1 d'.
+	Name   string        `yaml:"name"`           // Name is the name of the builtin ident.
+	Dollar token.Token   `yaml:"dollar"`         // Dollar is the opening [token.Dollar].
+	Token  token.Token   `yaml:"token"`          // The [token.Ident] token.
+	Type   Kind          `yaml:"type"`           // Type is the kind of ast node, in this case [KindBuiltin].
 }
 
 // Start returns the first token in the Builtin, which is
@@ -55,9 +56,13 @@ func (b Builtin) Start() token.Token {
 	return b.Dollar
 }
 
-// End returns the last token in the Builtin, which is
-// the [token.Ident].
+// End returns the last token in the Builtin, which is the last
+// argument or the [token.Ident] if it has none.
 func (b Builtin) End() token.Token {
+	if len(b.Args) != 0 {
+		return b.Args[len(b.Args)-1].End()
+	}
+
 	return b.Token
 }
 
@@ -205,13 +210,14 @@ func (b Body) expressionNode() {}
 
 // BodyFile is a http body from a filepath.
 type BodyFile struct {
-	Value Expression  `yaml:"value"` // Value is the expression of the filepath.
-	Token token.Token `yaml:"token"` // Token is the [token.LeftAngle] token.
-	Type  Kind        `yaml:"type"`  // Type is [KindBodyFile].
+	Value       Expression  `yaml:"value"`       // Value is the expression of the filepath.
+	Token       token.Token `yaml:"token"`       // Token is the [token.LeftAngle] or [token.LeftAngleAt] token.
+	Type        Kind        `yaml:"type"`        // Type is [KindBodyFile].
+	Interpolate bool        `yaml:"interpolate"` // Interpolate is whether the file contents are interpolated ('<@').
 }
 
 // Start returns the first token associated with the BodyFile, which
-// is the [token.LeftAngle].
+// is the [token.LeftAngle] or [token.LeftAngleAt].
 func (b BodyFile) Start() token.Token {
 	return b.Token
 }
@@ -234,10 +240,41 @@ func (b BodyFile) Kind() Kind {
 // expressionNode marks a [BodyFile] as an [Expression].
 func (b BodyFile) expressionNode() {}
 
+// Query is a JSONPath or XPath query selecting part of a request or
+// response body e.g. the '$.token' in '{{ login.response.body.$.token }}'.
+type Query struct {
+	Value string      `yaml:"value"` // The raw query
+	Token token.Token `yaml:"token"` // The [token.Query] token.
+	Type  Kind        `yaml:"type"`  // Type is [KindQuery].
+}
+
+// Start returns the first token of the Query, which is
+// the [token.Query].
+func (q Query) Start() token.Token {
+	return q.Token
+}
+
+// End returns the last token in the Query, which is also
+// the [token.Query].
+func (q Query) End() token.Token {
+	return q.Token
+}
+
+// Kind returns [KindQuery].
+func (q Query) Kind() Kind {
+	return q.Type
+}
+
+// expressionNode marks a [Query] as an [Expression].
+func (q Query) expressionNode() {}
+
 // SelectorExpression represents an expression followed by a selector.
+//
+// The selector is usually an [Ident] e.g. 'login.response' but may also
+// be a [Query] e.g. 'login.response.body.$.token'.
 type SelectorExpression struct {
 	Expr     Expression // Expr is the expression
-	Selector Ident      // Selector is the selector
+	Selector Expression // Selector is the selector, an [Ident] or a [Query]
 	Type     Kind       // Type is [KindSelector]
 }
 
@@ -252,7 +289,7 @@ func (s SelectorExpression) Start() token.Token {
 }
 
 // End returns the last token associated with the SelectorExpression, which
-// is the ident token of the selector.
+// is the last token of the selector.
 func (s SelectorExpression) End() token.Token {
 	return s.Selector.End()
 }
@@ -264,3 +301,66 @@ func (s SelectorExpression) Kind() Kind {
 
 // expressionNode marks a [SelectorExpression] as an [Expression].
 func (s SelectorExpression) expressionNode() {}
+
+// CallExpression represents a call of a builtin with arguments in parentheses
+// e.g. '$random.alphanumeric(8)'.
+type CallExpression struct {
+	Func  Expression   // Func is the builtin being called, a [Builtin] or a [SelectorExpression] on one
+	Args  []Expression // Args are the comma separated arguments
+	Open  token.Token  // Open is the opening [token.LeftParen]
+	Close token.Token  // Close is the closing [token.RightParen]
+	Type  Kind         // Type is [KindCall]
+}
+
+// Start returns the first token associated with the CallExpression, which
+// is the first token in the Func.
+func (c CallExpression) Start() token.Token {
+	return c.Func.Start()
+}
+
+// End returns the last token associated with the CallExpression, which
+// is the closing [token.RightParen].
+func (c CallExpression) End() token.Token {
+	return c.Close
+}
+
+// Kind returns [KindCall].
+func (c CallExpression) Kind() Kind {
+	return c.Type
+}
+
+// expressionNode marks a [CallExpression] as an [Expression].
+func (c CallExpression) expressionNode() {}
+
+// BinaryExpression represents two expressions joined by an operator inside an
+// interpolation: a concatenation e.g. 'user + ":" + pass', a default e.g.
+// 'region ?? "eu-west-1"' or a filter e.g. 'token | trim'.
+//
+// The right hand side of a filter is an [Ident] naming the filter, or a [CallExpression]
+// on one giving it further arguments.
+type BinaryExpression struct {
+	Left  Expression  // Left is the left hand operand
+	Right Expression  // Right is the right hand operand
+	Op    token.Token // Op is the operator, [token.Plus], [token.DoubleQuestion] or [token.Pipe]
+	Type  Kind        // Type is [KindBinary]
+}
+
+// Start returns the first token associated with the BinaryExpression, which
+// is the first token in the left hand operand.
+func (b BinaryExpression) Start() token.Token {
+	return b.Left.Start()
+}
+
+// End returns the last token associated with the BinaryExpression, which
+// is the last token in the right hand operand.
+func (b BinaryExpression) End() token.Token {
+	return b.Right.End()
+}
+
+// Kind returns [KindBinary].
+func (b BinaryExpression) Kind() Kind {
+	return b.Type
+}
+
+// expressionNode marks a [BinaryExpression] as an [Expression].
+func (b BinaryExpression) expressionNode() {}
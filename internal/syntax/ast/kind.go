@@ -26,6 +26,11 @@ const (
 	KindResponseRedirect                   // ResponseRedirect
 	KindResponseReference                  // ResponseReference
 	KindHTTPVersion                        // HTTPVersion
+	KindAssert                             // Assert
+	KindQuery                              // Query
+	KindCapture                            // Capture
+	KindCall                               // Call
+	KindBinary                             // Binary
 )
 
 // MarshalText implements [encoding.TextMarshaler] for [Kind].
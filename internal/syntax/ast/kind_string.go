@@ -27,11 +27,16 @@ func _() {
 	_ = x[KindResponseRedirect-16]
 	_ = x[KindResponseReference-17]
 	_ = x[KindHTTPVersion-18]
+	_ = x[KindAssert-19]
+	_ = x[KindQuery-20]
+	_ = x[KindCapture-21]
+	_ = x[KindCall-22]
+	_ = x[KindBinary-23]
 }
 
-const _Kind_name = "InvalidFileVarStatementIdentBuiltinTextLiteralInterpPromptRequestCommentMethodHeaderInterpolatedExpressionKindSelectorBodyBodyFileResponseRedirectResponseReferenceHTTPVersion"
+const _Kind_name = "InvalidFileVarStatementIdentBuiltinTextLiteralInterpPromptRequestCommentMethodHeaderInterpolatedExpressionKindSelectorBodyBodyFileResponseRedirectResponseReferenceHTTPVersionAssertQueryCaptureCallBinary"
 
-var _Kind_index = [...]uint8{0, 7, 11, 23, 28, 35, 46, 52, 58, 65, 72, 78, 84, 106, 118, 122, 130, 146, 163, 174}
+var _Kind_index = [...]uint8{0, 7, 11, 23, 28, 35, 46, 52, 58, 65, 72, 78, 84, 106, 118, 122, 130, 146, 163, 174, 180, 185, 192, 196, 202}
 
 func (i Kind) String() string {
 	idx := int(i) - 0
@@ -40,12 +40,16 @@ func (v VarStatement) Kind() Kind {
 // statementNode marks a [VarStatement] as an [Statement].
 func (v VarStatement) statementNode() {}
 
-// A PromptStatement is a single prompt declaration.
+// A PromptStatement is a single prompt declaration e.g. '@prompt env [dev|prod] = dev The environment'.
 type PromptStatement struct {
-	Ident       Ident       `yaml:"ident"`       // Ident is the [Ident] node representing the assignee.
-	Description TextLiteral `yaml:"description"` // Description is the [Text] node containing the prompt description.
-	At          token.Token `yaml:"at"`          // At is the '@' token declaring the prompt.
-	Type        Kind        `yaml:"type"`        // Type is the kind of the node, in this case [KindPromptStatement].
+	Ident       Ident         `yaml:"ident"`       // Ident is the [Ident] node representing the assignee.
+	ValueType   Ident         `yaml:"valueType"`   // ValueType is the optional type of the value e.g. 'int' in 'id:int'.
+	Description TextLiteral   `yaml:"description"` // Description is the [Text] node containing the prompt description.
+	Default     TextLiteral   `yaml:"default"`     // Default is the optional default value following '='.
+	Choices     []TextLiteral `yaml:"choices"`     // Choices are the optional allowed values e.g. '[dev|prod]'.
+	At          token.Token   `yaml:"at"`          // At is the '@' token declaring the prompt.
+	Type        Kind          `yaml:"type"`        // Type is the kind of the node, in this case [KindPromptStatement].
+	Secret      bool          `yaml:"secret"`      // Secret is whether the prompt was declared with '@prompt-secret'.
 }
 
 // Start returns the first token in a PromptStatement, which is
@@ -54,15 +58,21 @@ func (p PromptStatement) Start() token.Token {
 	return p.At
 }
 
-// End returns the final token in a PromptStatement which is
-// either the [TextLiteral] of the description if it's present
-// or the [Ident] if not.
+// End returns the final token in a PromptStatement, which is the token
+// of the last of its optional parts present or the [Ident] if there are none.
 func (p PromptStatement) End() token.Token {
-	if p.Description.Value != "" {
+	switch {
+	case p.Description.Value != "":
 		return p.Description.End()
+	case p.Default.Value != "":
+		return p.Default.End()
+	case len(p.Choices) != 0:
+		return p.Choices[len(p.Choices)-1].End()
+	case p.ValueType.Name != "":
+		return p.ValueType.End()
+	default:
+		return p.Ident.End()
 	}
-
-	return p.Ident.End()
 }
 
 // Kind returns [KindPromptStatement].
@@ -73,6 +83,70 @@ func (p PromptStatement) Kind() Kind {
 // statementNode marks a [PromptStatement] as an [Statement].
 func (p PromptStatement) statementNode() {}
 
+// An AssertStatement is a single response assertion e.g. '# @assert status == 200'.
+type AssertStatement struct {
+	Value    Expression  `yaml:"value"`    // Value is the optional expected value expression.
+	Subject  TextLiteral `yaml:"subject"`  // Subject is the part of the response under test e.g. 'status'.
+	Operator string      `yaml:"operator"` // Operator is the comparison operator e.g. '==' or 'contains'.
+	OpToken  token.Token `yaml:"opToken"`  // OpToken is the [token.Operator] token.
+	At       token.Token `yaml:"at"`       // At is the '@' token declaring the assertion.
+	Type     Kind        `yaml:"type"`     // Type is [KindAssert].
+}
+
+// Start returns the first token in an AssertStatement, which is
+// the opening '@'.
+func (a AssertStatement) Start() token.Token {
+	return a.At
+}
+
+// End returns the final token in an AssertStatement which is the
+// final token in the value expression if there is one, or the
+// operator if not.
+func (a AssertStatement) End() token.Token {
+	if a.Value != nil {
+		return a.Value.End()
+	}
+
+	return a.OpToken
+}
+
+// Kind returns [KindAssert].
+func (a AssertStatement) Kind() Kind {
+	return a.Type
+}
+
+// statementNode marks an [AssertStatement] as an [Statement].
+func (a AssertStatement) statementNode() {}
+
+// CaptureStatement is a request capture e.g. '# @capture token = $.body.access_token',
+// which stores part of the response in a variable for use in later requests.
+type CaptureStatement struct {
+	Source TextLiteral `yaml:"source"` // Source is the part of the response to capture e.g. 'header.ETag'.
+	Ident  Ident       `yaml:"ident"`  // Ident is the [Ident] of the variable captured into.
+	At     token.Token `yaml:"at"`     // At is the '@' token declaring the capture.
+	Type   Kind        `yaml:"type"`   // Type is [KindCapture].
+}
+
+// Start returns the first token in a CaptureStatement, which is
+// the opening '@'.
+func (c CaptureStatement) Start() token.Token {
+	return c.At
+}
+
+// End returns the final token in a CaptureStatement, which is
+// the token of the source.
+func (c CaptureStatement) End() token.Token {
+	return c.Source.End()
+}
+
+// Kind returns [KindCapture].
+func (c CaptureStatement) Kind() Kind {
+	return c.Type
+}
+
+// statementNode marks a [CaptureStatement] as an [Statement].
+func (c CaptureStatement) statementNode() {}
+
 // Comment represents a single line comment.
 type Comment struct {
 	Text  string      `yaml:"text"`  // Text is the test contained in the comment.
@@ -154,6 +228,14 @@ type Request struct {
 	// Headers are the [HeaderStatement] nodes attached to the request
 	Headers []Header `yaml:"headers"`
 
+	// Assertions are any [AssertStatement] nodes attached to the request
+	// declaring checks to make against the response.
+	Assertions []AssertStatement `yaml:"assertions"`
+
+	// Captures are any [CaptureStatement] nodes attached to the request
+	// declaring values to capture from the response.
+	Captures []CaptureStatement `yaml:"captures"`
+
 	// Method is the [Method] node.
 	Method Method `yaml:"method"`
 
@@ -15,6 +15,7 @@ import (
 	"errors"
 	"fmt"
 	"slices"
+	"strconv"
 	"strings"
 
 	"go.followtheprocess.codes/zap/internal/syntax"
@@ -42,6 +43,8 @@ type Parser struct {
 	current     token.Token         // Current token under inspection
 	next        token.Token         // Next token in the stream
 	hadErrors   bool                // Whether we encountered parse errors
+	template    bool                // Whether we're parsing a template, where body text is kept verbatim
+	interp      bool                // Whether we're inside an interpolation, where text is a literal e.g. '"quoted"'
 }
 
 // New initialises and returns a new [Parser] that parses src.
@@ -59,6 +62,57 @@ func New(name string, src []byte) *Parser {
 	return p
 }
 
+// NewTemplate initialises and returns a new [Parser] that parses src as a template,
+// free text containing '{{ }}' interpolations, see [scanner.NewTemplate].
+func NewTemplate(name string, src []byte) *Parser {
+	p := &Parser{
+		scanner:  scanner.NewTemplate(name, src),
+		name:     name,
+		src:      src,
+		template: true,
+	}
+
+	// Read 2 tokens so current and next are set
+	p.advance()
+	p.advance()
+
+	return p
+}
+
+// ParseTemplate parses a template to completion, returning the expression it represents
+// and any parsing errors.
+//
+// An empty template is an empty [ast.Body].
+func (p *Parser) ParseTemplate() (ast.Expression, error) {
+	if p == nil {
+		return nil, errors.New("ParseTemplate called on nil parser")
+	}
+
+	switch p.current.Kind {
+	case token.EOF:
+		return ast.Body{Token: p.current, Type: ast.KindBody}, nil
+	case token.Error:
+		return nil, ErrParse
+	default:
+		// Actual content to parse
+	}
+
+	expr, err := p.parseExpression(token.LowestPrecedence)
+	if err != nil {
+		return expr, err
+	}
+
+	if err := p.expect(token.EOF); err != nil {
+		return expr, err
+	}
+
+	if p.hadErrors {
+		return expr, ErrParse
+	}
+
+	return expr, nil
+}
+
 // Parse parses the file to completion returning an [ast.File] and any parsing errors.
 //
 // The returned error will simply signify whether or not there were parse errors,
@@ -269,7 +323,7 @@ func (p *Parser) synchronise() error {
 func (p *Parser) parseStatement() (ast.Statement, error) {
 	switch p.current.Kind {
 	case token.At:
-		if p.next.Is(token.Prompt) {
+		if p.next.Is(token.Prompt, token.PromptSecret) {
 			return p.parsePrompt()
 		}
 
@@ -299,7 +353,14 @@ func (p *Parser) parseVarStatement() (ast.VarStatement, error) {
 	// are structurally identical, they are all effectively a variable declaration, just their
 	// variables are "special". During resolution they get mapped into dedicated fields in the
 	// resulting spec.File.
-	if err := p.expect(token.Name, token.Timeout, token.ConnectionTimeout, token.Ident); err != nil {
+	if err := p.expect(
+		token.Name,
+		token.Timeout,
+		token.ConnectionTimeout,
+		token.Ignore,
+		token.IgnoreHeader,
+		token.Ident,
+	); err != nil {
 		return result, err
 	}
 
@@ -346,16 +407,68 @@ func (p *Parser) parsePrompt() (ast.PromptStatement, error) {
 		Type: ast.KindPrompt,
 	}
 
-	if err := p.expect(token.Prompt); err != nil {
+	if err := p.expect(token.Prompt, token.PromptSecret); err != nil {
 		return result, err
 	}
 
+	result.Secret = p.current.Is(token.PromptSecret)
+
 	if err := p.expect(token.Ident); err != nil {
 		return result, err
 	}
 
 	result.Ident = p.parseIdent()
 
+	if p.next.Is(token.Colon) {
+		p.advance()
+
+		if err := p.expect(token.Ident); err != nil {
+			return result, err
+		}
+
+		result.ValueType = p.parseIdent()
+	}
+
+	if p.next.Is(token.LeftBracket) {
+		p.advance()
+
+		for {
+			if err := p.expect(token.Text); err != nil {
+				return result, err
+			}
+
+			result.Choices = append(result.Choices, p.parseTextLiteral())
+
+			if err := p.expect(token.Pipe, token.RightBracket); err != nil {
+				return result, err
+			}
+
+			if p.current.Is(token.RightBracket) {
+				break
+			}
+		}
+	}
+
+	if p.next.Is(token.Eq) {
+		p.advance()
+
+		if err := p.expect(token.Text); err != nil {
+			return result, err
+		}
+
+		result.Default = p.parseTextLiteral()
+
+		if strings.HasPrefix(result.Default.Value, `"`) {
+			unquoted, err := strconv.Unquote(result.Default.Value)
+			if err != nil {
+				p.errorf("invalid quoted prompt default %s: %v", result.Default.Value, err)
+				return result, ErrParse
+			}
+
+			result.Default.Value = unquoted
+		}
+	}
+
 	if p.next.Is(token.Text) {
 		p.advance()
 
@@ -365,6 +478,75 @@ func (p *Parser) parsePrompt() (ast.PromptStatement, error) {
 	return result, nil
 }
 
+// parseAssert parses a response assertion.
+func (p *Parser) parseAssert() (ast.AssertStatement, error) {
+	result := ast.AssertStatement{
+		At:   p.current,
+		Type: ast.KindAssert,
+	}
+
+	if err := p.expect(token.Assert); err != nil {
+		return result, err
+	}
+
+	if err := p.expect(token.Text); err != nil {
+		return result, err
+	}
+
+	result.Subject = p.parseTextLiteral()
+
+	if err := p.expect(token.Operator); err != nil {
+		return result, err
+	}
+
+	result.Operator = p.text()
+	result.OpToken = p.current
+
+	// The value is optional, some operators like 'exists' don't take one
+	if p.next.Is(token.Text, token.OpenInterp) {
+		p.advance()
+
+		value, err := p.parseExpression(token.LowestPrecedence)
+		if err != nil {
+			return result, err
+		}
+
+		result.Value = value
+	}
+
+	return result, nil
+}
+
+// parseCapture parses a response capture.
+func (p *Parser) parseCapture() (ast.CaptureStatement, error) {
+	result := ast.CaptureStatement{
+		At:   p.current,
+		Type: ast.KindCapture,
+	}
+
+	if err := p.expect(token.Capture); err != nil {
+		return result, err
+	}
+
+	if err := p.expect(token.Ident); err != nil {
+		return result, err
+	}
+
+	result.Ident = p.parseIdent()
+
+	if err := p.expect(token.Eq); err != nil {
+		return result, err
+	}
+
+	if err := p.expect(token.Text); err != nil {
+		return result, err
+	}
+
+	result.Source = p.parseTextLiteral()
+
+	return result, nil
+}
+
 // parseComment parses a line comment.
 //
 // Comments are parsed into ast nodes so that comments above requests may
@@ -407,7 +589,7 @@ func (p *Parser) parseRequest() (ast.Request, error) {
 		// are structurally identical, they are all effectively a variable declaration, just their
 		// variables are "special". During resolution they get mapped into dedicated fields in the
 		// resulting spec.File.
-		case token.Name, token.Timeout, token.ConnectionTimeout, token.Ident:
+		case token.Name, token.Timeout, token.ConnectionTimeout, token.Ignore, token.IgnoreHeader, token.Ident:
 			varStatement, err := p.parseVarStatement()
 			if err != nil {
 				return result, err
@@ -423,13 +605,27 @@ func (p *Parser) parseRequest() (ast.Request, error) {
 			}
 
 			result.Vars = append(result.Vars, noRedirect)
-		case token.Prompt:
+		case token.Prompt, token.PromptSecret:
 			prompt, err := p.parsePrompt()
 			if err != nil {
 				return result, err
 			}
 
 			result.Prompts = append(result.Prompts, prompt)
+		case token.Assert:
+			assertion, err := p.parseAssert()
+			if err != nil {
+				return result, err
+			}
+
+			result.Assertions = append(result.Assertions, assertion)
+		case token.Capture:
+			capture, err := p.parseCapture()
+			if err != nil {
+				return result, err
+			}
+
+			result.Captures = append(result.Captures, capture)
 		default:
 			// Use expect for the free error message
 			if err := p.expect(token.Name,
@@ -438,6 +634,11 @@ func (p *Parser) parseRequest() (ast.Request, error) {
 				token.NoRedirect,
 				token.Ident,
 				token.Prompt,
+				token.PromptSecret,
+				token.Assert,
+				token.Capture,
+				token.Ignore,
+				token.IgnoreHeader,
 			); err != nil {
 				return result, err
 			}
@@ -511,7 +712,7 @@ func (p *Parser) parseRequest() (ast.Request, error) {
 		}
 
 		result.Body = body
-	case token.LeftAngle:
+	case token.LeftAngle, token.LeftAngleAt:
 		p.advance()
 
 		bodyFile, err := p.parseBodyFile()
@@ -573,7 +774,12 @@ func (p *Parser) parseExpression(precedence int) (ast.Expression, error) {
 
 	switch p.current.Kind {
 	case token.Text:
-		expr = p.parseTextLiteral()
+		if p.interp {
+			// Inside an interpolation text is a quoted or numeric literal e.g. '"abc"' or '10'
+			expr, err = p.parseArgument()
+		} else {
+			expr = p.parseTextLiteral()
+		}
 	case token.OpenInterp:
 		// If the expression begins with an open interp '{{' it's an interpolated
 		// expression with no left hand side, hence nil
@@ -616,7 +822,8 @@ func (p *Parser) parseExpression(precedence int) (ast.Expression, error) {
 	//
 	// In our case the Interp is the operator and carries the highest precedence.
 
-	for p.next.Is(token.OpenInterp, token.Dot) && precedence < p.next.Precedence() {
+	for p.next.Is(token.OpenInterp, token.Dot, token.LeftParen, token.Plus, token.DoubleQuestion, token.Pipe) &&
+		precedence < p.next.Precedence() {
 		p.advance()
 
 		switch p.current.Kind {
@@ -626,6 +833,13 @@ func (p *Parser) parseExpression(precedence int) (ast.Expression, error) {
 		case token.Dot:
 			// It's a selector expression e.g request.body
 			expr, err = p.parseSelectorExpression(expr)
+		case token.LeftParen:
+			// It's a call of a builtin e.g. $random.alphanumeric(8)
+			expr, err = p.parseCallExpression(expr)
+		case token.Plus, token.DoubleQuestion, token.Pipe:
+			// It's a concatenation e.g. user + ":" + pass, a default e.g. region ?? "eu-west-1"
+			// or a filter e.g. token | trim
+			expr, err = p.parseBinaryExpression(expr)
 		default:
 			p.errorf("parseExpression: unexpected token: %s", p.current.Kind)
 		}
@@ -675,6 +889,13 @@ func (p *Parser) parseSelectorExpression(left ast.Expression) (ast.SelectorExpre
 		Type: ast.KindSelector,
 	}
 
+	if p.next.Is(token.Query) {
+		p.advance()
+		expr.Selector = p.parseQuery()
+
+		return expr, nil
+	}
+
 	if err := p.expect(token.Ident); err != nil {
 		return expr, err
 	}
@@ -704,9 +925,10 @@ func (p *Parser) parseSelectorExpression(left ast.Expression) (ast.SelectorExpre
 // In general, we only parse the right hand side if it's the same type of expression as the left.
 func (p *Parser) shouldParseRHS(left ast.Expression) bool {
 	if left == nil {
-		// No information to tell otherwise so go ahead and
-		// parse the right hand side
-		return true
+		// No information to tell otherwise so go ahead and parse the right hand side,
+		// unless it's a body that doesn't immediately follow e.g. the body after a header
+		// whose value starts with an interp
+		return !p.next.Is(token.Body) || p.next.Start == p.current.End
 	}
 
 	switch left.Kind() {
@@ -769,7 +991,18 @@ func (p *Parser) parseIdent() ast.Ident {
 	return ident
 }
 
-// parseBuiltin parses a Builtin identifier.
+// parseQuery parses a JSONPath or XPath Query.
+func (p *Parser) parseQuery() ast.Query {
+	query := ast.Query{
+		Value: p.text(),
+		Token: p.current,
+		Type:  ast.KindQuery,
+	}
+
+	return query
+}
+
+// parseBuiltin parses a Builtin identifier and its arguments.
 func (p *Parser) parseBuiltin() (ast.Builtin, error) {
 	builtin := ast.Builtin{
 		Dollar: p.current,
@@ -784,9 +1017,106 @@ func (p *Parser) parseBuiltin() (ast.Builtin, error) {
 	builtin.Token = p.current
 	builtin.Name = p.text()
 
+	// Followed by any arguments e.g. '$timestamp -1 d', bare words are
+	// literal text not references to variables
+	for p.next.Is(token.Text, token.Ident) {
+		p.advance()
+
+		arg, err := p.parseArgument()
+		if err != nil {
+			return builtin, err
+		}
+
+		builtin.Args = append(builtin.Args, arg)
+	}
+
 	return builtin, nil
 }
 
+// parseArgument parses a literal argument to a builtin, unquoting it if quoted.
+func (p *Parser) parseArgument() (ast.TextLiteral, error) {
+	arg := p.parseTextLiteral()
+
+	if strings.HasPrefix(arg.Value, `"`) {
+		unquoted, err := strconv.Unquote(arg.Value)
+		if err != nil {
+			p.errorf("invalid quoted argument %s: %v", arg.Value, err)
+			return arg, ErrParse
+		}
+
+		arg.Value = unquoted
+	}
+
+	return arg, nil
+}
+
+// parseCallExpression parses a call of a builtin with arguments in parentheses,
+// the left paren is the current token.
+func (p *Parser) parseCallExpression(left ast.Expression) (ast.CallExpression, error) {
+	call := ast.CallExpression{
+		Func: left,
+		Open: p.current,
+		Type: ast.KindCall,
+	}
+
+	for !p.next.Is(token.RightParen) {
+		if len(call.Args) != 0 {
+			if err := p.expect(token.Comma); err != nil {
+				return call, err
+			}
+		}
+
+		if err := p.expect(token.Text, token.Ident, token.Dollar); err != nil {
+			return call, err
+		}
+
+		arg, err := p.parseExpression(token.LowestPrecedence)
+		if err != nil {
+			return call, err
+		}
+
+		call.Args = append(call.Args, arg)
+	}
+
+	p.advance()
+	call.Close = p.current
+
+	return call, nil
+}
+
+// parseBinaryExpression parses an operator joining two expressions inside an
+// interpolation e.g. 'user + ":"', the operator is the current token.
+//
+// The right hand side of a '|' is the name of a filter, optionally called with
+// further arguments e.g. 'body | hmacSHA256(secret)'.
+func (p *Parser) parseBinaryExpression(left ast.Expression) (ast.BinaryExpression, error) {
+	expr := ast.BinaryExpression{
+		Left: left,
+		Op:   p.current,
+		Type: ast.KindBinary,
+	}
+
+	precedence := p.current.Precedence()
+
+	operands := []token.Kind{token.Text, token.Ident, token.Dollar}
+	if expr.Op.Is(token.Pipe) {
+		operands = []token.Kind{token.Ident}
+	}
+
+	if err := p.expect(operands...); err != nil {
+		return expr, err
+	}
+
+	right, err := p.parseExpression(precedence)
+	if err != nil {
+		return expr, err
+	}
+
+	expr.Right = right
+
+	return expr, nil
+}
+
 // parseInterp parses an interpolation expression, i.e.
 // '{{' <expr> '}}'.
 func (p *Parser) parseInterp() (ast.Interp, error) {
@@ -795,11 +1125,14 @@ func (p *Parser) parseInterp() (ast.Interp, error) {
 		Type: ast.KindInterp,
 	}
 
-	if err := p.expect(token.Ident, token.Dollar); err != nil {
+	if err := p.expect(token.Ident, token.Dollar, token.Text); err != nil {
 		return result, err
 	}
 
+	p.interp = true
 	expr, err := p.parseExpression(token.LowestPrecedence)
+	p.interp = false
+
 	if err != nil {
 		return result, err
 	}
@@ -819,18 +1152,24 @@ func (p *Parser) parseInterp() (ast.Interp, error) {
 func (p *Parser) parseBody() (ast.Body, error) {
 	body := ast.Body{
 		Token: p.current,
-		Value: strings.TrimSpace(p.text()),
+		Value: p.text(),
 		Type:  ast.KindBody,
 	}
 
+	// Whitespace in a template is significant, it could be either side of an interp
+	if !p.template {
+		body.Value = strings.TrimSpace(body.Value)
+	}
+
 	return body, nil
 }
 
 // parseBodyFile parses a body file expression.
 func (p *Parser) parseBodyFile() (ast.BodyFile, error) {
 	bodyFile := ast.BodyFile{
-		Token: p.current,
-		Type:  ast.KindBodyFile,
+		Token:       p.current,
+		Type:        ast.KindBodyFile,
+		Interpolate: p.current.Is(token.LeftAngleAt),
 	}
 
 	if err := p.expect(token.Text, token.OpenInterp); err != nil {
@@ -61,6 +61,43 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestParseTemplate(t *testing.T) {
+	// Force colour for diffs but only locally
+	test.ColorEnabled(os.Getenv("CI") == "")
+
+	dir := filepath.Join("testdata", "template")
+
+	entries, err := os.ReadDir(dir)
+	test.Ok(t, err)
+
+	for _, entry := range entries {
+		t.Run(entry.Name(), func(t *testing.T) {
+			defer goleak.VerifyNone(t)
+
+			snap := snapshot.New(
+				t,
+				snapshot.Update(*update),
+				snapshot.Clean(*clean),
+				snapshot.Color(os.Getenv("CI") == ""),
+			)
+
+			src, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+			test.Ok(t, err)
+
+			p := parser.NewTemplate(entry.Name(), src)
+
+			parsed, err := p.ParseTemplate()
+			if err != nil {
+				t.Logf("Diagnostics: %+v\n", p.Diagnostics())
+			}
+
+			test.Ok(t, err)
+
+			snap.Snap(parsed)
+		})
+	}
+}
+
 // TestInvalid is the primary test for invalid syntax. It does much the same as TestParse
 // but instead of failing tests if a syntax error is encounter, it fails if there is not any syntax errors.
 //
@@ -5,11 +5,16 @@ package builtins
 import (
 	"errors"
 	"fmt"
+	"math/rand/v2"
 	"os"
-
-	"github.com/google/uuid"
+	"strings"
+	"time"
 )
 
+// ErrUndefined is matched by the errors of builtins with no value to give, e.g. '$env.VAR'
+// when VAR is not set, so a default may be used instead e.g. '{{ $env.VAR ?? "default" }}'.
+var ErrUndefined = errors.New("undefined")
+
 // Builtin is an implementation of a zap builtin.
 type Builtin func(args ...string) (string, error)
 
@@ -23,18 +28,104 @@ type Library interface {
 // Builtins is a [Library] containing the builtin implementations.
 type Builtins struct {
 	library map[string]Builtin
+	now     func() time.Time // The clock used by the date and time builtins
+	random  *random          // The source of randomness for $uuid and the random builtins
+}
+
+// ArgumentError is an error caused by a particular argument to a builtin, so that
+// it may be reported at that argument rather than the builtin as a whole.
+type ArgumentError struct {
+	Err   error // The underlying error
+	Index int   // The index of the bad argument
+}
+
+// Error implements the error interface for [ArgumentError].
+func (a ArgumentError) Error() string {
+	return a.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (a ArgumentError) Unwrap() error {
+	return a.Err
+}
+
+// undefinedError is an error matching [ErrUndefined].
+type undefinedError struct {
+	msg string
+}
+
+// Error implements the error interface for [undefinedError].
+func (u undefinedError) Error() string {
+	return u.msg
+}
+
+// Is reports whether target is [ErrUndefined].
+func (u undefinedError) Is(target error) bool {
+	return target == ErrUndefined
+}
+
+// Undefined returns an error with a formatted message that matches [ErrUndefined], for
+// something with no value to give.
+func Undefined(format string, a ...any) error {
+	return undefinedError{msg: fmt.Sprintf(format, a...)}
+}
+
+// argumentErrorf returns an [ArgumentError] for the argument at index with a formatted message.
+func argumentErrorf(index int, format string, a ...any) error {
+	return ArgumentError{Err: fmt.Errorf(format, a...), Index: index}
+}
+
+// Option is a functional option for configuring the builtins [Library].
+type Option func(b *Builtins)
+
+// WithClock sets the clock used by the date and time builtins, [time.Now] by default.
+//
+// '$localDatetime' uses the time in whatever location the clock returns.
+func WithClock(now func() time.Time) Option {
+	return func(b *Builtins) {
+		b.now = now
+	}
+}
+
+// WithSeed seeds the source of randomness used by '$uuid' and the random builtins, so
+// the values they generate are the same every time. By default they are randomly seeded.
+func WithSeed(seed uint64) Option {
+	return func(b *Builtins) {
+		b.random = newRandom(seed)
+	}
 }
 
 // NewLibrary returns the zap builtins library.
-func NewLibrary() (Builtins, error) {
-	library := map[string]Builtin{
-		"uuid": builtinUUID,
-		"env":  builtinEnv,
+func NewLibrary(options ...Option) (Builtins, error) {
+	builtins := Builtins{
+		now:    time.Now,
+		random: newRandom(rand.Uint64()),
+	}
+
+	for _, option := range options {
+		option(&builtins)
+	}
+
+	builtins.library = map[string]Builtin{
+		"uuid":          builtins.uuid,
+		"env":           builtinEnv,
+		"randomInt":     builtins.randomInt,
+		"random":        builtins.randomValue,
+		"timestamp":     builtins.timestamp,
+		"isoTimestamp":  builtins.isoTimestamp,
+		"datetime":      builtins.datetime,
+		"localDatetime": builtins.localDatetime,
+		"base64":        builtinBase64,
+		"urlencode":     builtinURLEncode,
+		"sha256":        builtinSHA256,
+		"hmacSHA256":    builtinHMACSHA256,
+		"jwt":           builtinJWT,
+		"trim":          builtinTrim,
+		"upper":         builtinUpper,
+		"lower":         builtinLower,
 	}
 
-	return Builtins{
-		library: library,
-	}, nil
+	return builtins, nil
 }
 
 // Get looks up a builtin by name, returning the builtin and a boolean
@@ -48,14 +139,16 @@ func (b Builtins) Get(name string) (Builtin, bool) {
 	return fn, true
 }
 
-// builtinUUID is the implementation of the '$uuid' builtin.
-func builtinUUID(args ...string) (string, error) {
-	uid, err := uuid.NewRandom()
-	if err != nil {
-		return "", fmt.Errorf("failed to generate a new uuid: %w", err)
+// Dynamic reports whether the builtin called name is dynamic, that is its value changes every
+// time it is called so it is evaluated every time a request is executed, rather than once when
+// the file is resolved.
+func Dynamic(name string) bool {
+	switch name {
+	case "uuid", "timestamp", "isoTimestamp", "datetime", "localDatetime", "randomInt", "random":
+		return true
+	default:
+		return false
 	}
-
-	return uid.String(), nil
 }
 
 // builtinEnv is the implementation of the '$env' builtin.
@@ -67,9 +160,13 @@ func builtinEnv(args ...string) (string, error) {
 		return "", errors.New("$env requires a variable name, use $env.VAR")
 	}
 
+	if len(args) > 1 {
+		return "", fmt.Errorf("$env takes a single variable name, got %s", strings.Join(args, "."))
+	}
+
 	value, ok := os.LookupEnv(args[0])
 	if !ok {
-		return "", fmt.Errorf("$env.%s: environment variable %s is not set", args[0], args[0])
+		return "", Undefined("$env.%s: environment variable %s is not set", args[0], args[0])
 	}
 
 	return value, nil
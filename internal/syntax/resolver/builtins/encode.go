@@ -0,0 +1,100 @@
+package builtins
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// builtinBase64 is the implementation of the '$base64' builtin, the standard base64
+// encoding of its argument e.g. '$base64(user + ":" + pass)' for basic auth.
+func builtinBase64(args ...string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("$base64 takes a single value to encode, got %d arguments", len(args))
+	}
+
+	return base64.StdEncoding.EncodeToString([]byte(args[0])), nil
+}
+
+// builtinURLEncode is the implementation of the '$urlencode' builtin, its argument
+// escaped for use in a URL query e.g. '?q={{ $urlencode(query) }}'.
+func builtinURLEncode(args ...string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("$urlencode takes a single value to encode, got %d arguments", len(args))
+	}
+
+	return url.QueryEscape(args[0]), nil
+}
+
+// builtinSHA256 is the implementation of the '$sha256' builtin, the hex encoded
+// SHA-256 digest of its argument.
+func builtinSHA256(args ...string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("$sha256 takes a single value to hash, got %d arguments", len(args))
+	}
+
+	digest := sha256.Sum256([]byte(args[0]))
+
+	return hex.EncodeToString(digest[:]), nil
+}
+
+// builtinHMACSHA256 is the implementation of the '$hmacSHA256' builtin, the hex encoded
+// HMAC-SHA256 of a message using a secret key e.g. '$hmacSHA256(secret, body)' to sign
+// a webhook.
+func builtinHMACSHA256(args ...string) (string, error) {
+	const parts = 2 // A secret and a message
+	if len(args) != parts {
+		return "", errors.New("$hmacSHA256 requires a secret and a message e.g. '$hmacSHA256(secret, body)'")
+	}
+
+	if args[0] == "" {
+		return "", argumentErrorf(0, "$hmacSHA256: secret must not be empty")
+	}
+
+	mac := hmac.New(sha256.New, []byte(args[0]))
+	mac.Write([]byte(args[1]))
+
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// builtinJWT is the implementation of the '$jwt' builtin, a JSON Web Token of the given
+// claims signed with a secret using HS256 e.g. '$jwt("{\"sub\": \"123\"}", secret)'.
+//
+// The claims are used as written so the token is the same every time it is generated,
+// claims such as 'iat' and 'exp' may be given using the time builtins.
+func builtinJWT(args ...string) (string, error) {
+	const parts = 2 // Claims and a secret
+	if len(args) != parts {
+		return "", errors.New(`$jwt requires claims and a secret e.g. '$jwt("{\"sub\": \"123\"}", secret)'`)
+	}
+
+	claims, secret := args[0], args[1]
+
+	var object map[string]any
+	if err := json.Unmarshal([]byte(claims), &object); err != nil {
+		return "", argumentErrorf(0, "$jwt: claims must be a JSON object, got %q", claims)
+	}
+
+	if secret == "" {
+		return "", argumentErrorf(1, "$jwt: secret must not be empty")
+	}
+
+	compact := &bytes.Buffer{}
+	if err := json.Compact(compact, []byte(claims)); err != nil {
+		return "", argumentErrorf(0, "$jwt: invalid claims: %w", err)
+	}
+
+	encoding := base64.RawURLEncoding
+	unsigned := encoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + encoding.EncodeToString(compact.Bytes())
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+
+	return unsigned + "." + encoding.EncodeToString(mac.Sum(nil)), nil
+}
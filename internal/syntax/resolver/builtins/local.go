@@ -0,0 +1,108 @@
+package builtins
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.followtheprocess.codes/zap/internal/dotenv"
+)
+
+// Dotenv is the name of the file read by the '$dotenv' builtin, in the directory of
+// the .http file.
+const Dotenv = ".env"
+
+// local is a [Library] adding the builtins that read local files to another.
+type local struct {
+	library Library // The library providing every other builtin
+	dir     string  // The directory relative paths are resolved against
+}
+
+// Local returns library along with the builtins that read local files: '$dotenv', which
+// reads a variable from the '.env' file in dir, and '$file', which reads the contents of
+// a file.
+//
+// dir is the directory containing the .http file, relative paths are resolved against it.
+func Local(library Library, dir string) Library {
+	return local{library: library, dir: dir}
+}
+
+// Get implements [Library] for the local builtins.
+func (l local) Get(name string) (Builtin, bool) {
+	switch name {
+	case "dotenv":
+		return l.dotenv, true
+	case "file":
+		return l.file, true
+	default:
+		if l.library == nil {
+			return nil, false
+		}
+
+		return l.library.Get(name)
+	}
+}
+
+// dotenv is the implementation of the '$dotenv' builtin, a variable from the '.env' file
+// next to the .http file e.g. '$dotenv API_KEY', like the VSCode REST client.
+func (l local) dotenv(args ...string) (string, error) {
+	if len(args) != 1 {
+		return "", errors.New("$dotenv requires a variable name e.g. '$dotenv API_KEY'")
+	}
+
+	key := args[0]
+	path := filepath.Join(l.dir, Dotenv)
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", Undefined("$dotenv %s: there is no %s file in %s", key, Dotenv, l.dir)
+		}
+
+		return "", fmt.Errorf("$dotenv %s: could not read %s: %w", key, path, err)
+	}
+
+	vars, err := dotenv.Parse(contents)
+	if err != nil {
+		return "", fmt.Errorf("$dotenv %s: invalid %s: %w", key, path, err)
+	}
+
+	value, ok := vars[key]
+	if !ok {
+		return "", Undefined("$dotenv %s: %s is not set in %s", key, key, path)
+	}
+
+	return value, nil
+}
+
+// file is the implementation of the '$file' builtin, the contents of a file without any
+// trailing newlines e.g. '$file("./key.pem")' or '$file.key.pem' for a file in the same
+// directory as the .http file.
+//
+// Relative paths are always resolved against the directory of the .http file, including
+// when '$file' is used in a body file interpolated with '<@' from another directory.
+func (l local) file(args ...string) (string, error) {
+	if len(args) == 0 {
+		return "", errors.New(`$file requires a path e.g. '$file("./key.pem")'`)
+	}
+
+	// Selectors are split on the '.' e.g. '$file.key.pem' is called with "key" and "pem"
+	path := strings.Join(args, ".")
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(l.dir, path)
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", Undefined("$file: %s does not exist", path)
+		}
+
+		return "", argumentErrorf(0, "$file: could not read %s: %w", path, err)
+	}
+
+	return strings.TrimRight(string(contents), "\r\n"), nil
+}
@@ -0,0 +1,307 @@
+package builtins
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Defaults for '$random.integer' and '$random.float' when no range is given.
+const (
+	defaultRandomMin = 0
+	defaultRandomMax = 1000
+)
+
+// Character sets for the random string generators.
+const (
+	alphabetic   = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	alphanumeric = alphabetic + "0123456789"
+	hexadecimal  = "0123456789abcdef"
+)
+
+// Word lists for the fake data generators.
+//
+//nolint:gochecknoglobals // Read only lookup tables
+var (
+	firstNames = []string{
+		"Alice", "Bob", "Charlie", "Diana", "Edward", "Fatima", "George", "Hannah",
+		"Ibrahim", "Julia", "Kenji", "Laura", "Mohammed", "Nina", "Oliver", "Priya",
+	}
+	lastNames = []string{
+		"Anderson", "Brown", "Chen", "Davies", "Evans", "Fernandez", "Garcia", "Hughes",
+		"Ivanova", "Jones", "Khan", "Lopez", "Murphy", "Nakamura", "Okafor", "Patel",
+	}
+	streets = []string{
+		"High Street", "Station Road", "Main Street", "Park Avenue", "Church Lane",
+		"Mill Road", "Oak Drive", "Victoria Road", "Elm Street", "Queens Way",
+	}
+	cities = []string{
+		"London", "Paris", "Berlin", "Madrid", "Lisbon", "Dublin", "Amsterdam",
+		"Toronto", "Sydney", "Tokyo", "Nairobi", "Austin", "Seattle", "Oslo",
+	}
+	countries = []string{
+		"United Kingdom", "France", "Germany", "Spain", "Portugal", "Ireland", "Netherlands",
+		"Canada", "Australia", "Japan", "Kenya", "United States", "Norway", "Brazil",
+	}
+	domains = []string{"example.com", "example.org", "example.net"}
+)
+
+// random is the source of randomness shared by the random builtins, it is safe for
+// concurrent use.
+type random struct {
+	rng *rand.Rand
+	mu  sync.Mutex
+}
+
+// newRandom returns a source of randomness seeded by seed.
+func newRandom(seed uint64) *random {
+	return &random{rng: rand.New(rand.NewPCG(seed, seed))}
+}
+
+// Read implements [io.Reader] for [random], filling p with random bytes.
+func (r *random) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range p {
+		p[i] = byte(r.rng.Uint32())
+	}
+
+	return len(p), nil
+}
+
+// intRange returns a random integer in [lower, upper).
+func (r *random) intRange(lower, upper int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// The width of the range can be more than the largest int e.g. [math.MinInt, math.MaxInt)
+	// but always fits in a uint64, and wrapping back into an int lands in the range
+	span := uint64(upper) - uint64(lower)
+
+	return lower + int(r.rng.Uint64N(span))
+}
+
+// floatRange returns a random float in [lower, upper).
+func (r *random) floatRange(lower, upper float64) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return lower + r.rng.Float64()*(upper-lower)
+}
+
+// choice returns a random element of options.
+func (r *random) choice(options []string) string {
+	return options[r.intRange(0, len(options))]
+}
+
+// text returns a random string of length n made up of the characters in charset.
+func (r *random) text(charset string, n int) string {
+	var b strings.Builder
+
+	b.Grow(n)
+
+	for range n {
+		b.WriteByte(charset[r.intRange(0, len(charset))])
+	}
+
+	return b.String()
+}
+
+// uuid is the implementation of the '$uuid' builtin.
+func (b Builtins) uuid(args ...string) (string, error) {
+	uid, err := uuid.NewRandomFromReader(b.random)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate a new uuid: %w", err)
+	}
+
+	return uid.String(), nil
+}
+
+// randomInt is the implementation of the '$randomInt' builtin, a random integer
+// between min (inclusive) and max (exclusive) e.g. '$randomInt 1 10'.
+func (b Builtins) randomInt(args ...string) (string, error) {
+	const parts = 2 // A min and a max
+	if len(args) != parts {
+		return "", errors.New("$randomInt requires a min and a max e.g. '$randomInt 1 10'")
+	}
+
+	lower, upper, err := intRange(args[0], args[1])
+	if err != nil {
+		return "", fmt.Errorf("$randomInt: %w", err)
+	}
+
+	return strconv.Itoa(b.random.intRange(lower, upper)), nil
+}
+
+// randomValue is the implementation of the '$random' builtin, which generates random
+// and fake data.
+//
+// The first argument is the generator to use and the rest its arguments, for example
+// '$random.alphanumeric(8)' is called with "alphanumeric" and "8" and '$random.name.firstName'
+// with "name" and "firstName".
+func (b Builtins) randomValue(args ...string) (string, error) {
+	if len(args) == 0 {
+		return "", errors.New("$random requires a generator e.g. $random.email")
+	}
+
+	generator, args := args[0], args[1:]
+
+	value, err := b.generate(generator, args)
+	if err != nil {
+		return "", fmt.Errorf("$random.%s: %w", generator, err)
+	}
+
+	return value, nil
+}
+
+// generate returns a value from the '$random' generator called name.
+func (b Builtins) generate(name string, args []string) (string, error) {
+	switch name {
+	case "uuid":
+		return b.uuid()
+	case "integer":
+		lower, upper := strconv.Itoa(defaultRandomMin), strconv.Itoa(defaultRandomMax)
+		if err := optionalRange(args, &lower, &upper); err != nil {
+			return "", err
+		}
+
+		from, to, err := intRange(lower, upper)
+		if err != nil {
+			return "", err
+		}
+
+		return strconv.Itoa(b.random.intRange(from, to)), nil
+	case "float":
+		lower, upper := strconv.Itoa(defaultRandomMin), strconv.Itoa(defaultRandomMax)
+		if err := optionalRange(args, &lower, &upper); err != nil {
+			return "", err
+		}
+
+		from, to, err := floatRange(lower, upper)
+		if err != nil {
+			return "", err
+		}
+
+		return strconv.FormatFloat(b.random.floatRange(from, to), 'f', -1, 64), nil
+	case "alphabetic", "alphanumeric", "hexadecimal":
+		if len(args) != 1 {
+			return "", fmt.Errorf("requires a length e.g. $random.%s(8)", name)
+		}
+
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("invalid length %q, expected a positive integer", args[0])
+		}
+
+		charsets := map[string]string{
+			"alphabetic":   alphabetic,
+			"alphanumeric": alphanumeric,
+			"hexadecimal":  hexadecimal,
+		}
+
+		return b.random.text(charsets[name], n), nil
+	case "email":
+		first, last := b.random.choice(firstNames), b.random.choice(lastNames)
+		return strings.ToLower(first+"."+last) + "@" + b.random.choice(domains), nil
+	case "name":
+		return b.fake(args, map[string]func() string{
+			"firstName": func() string { return b.random.choice(firstNames) },
+			"lastName":  func() string { return b.random.choice(lastNames) },
+			"fullName":  func() string { return b.random.choice(firstNames) + " " + b.random.choice(lastNames) },
+			"username": func() string {
+				return strings.ToLower(b.random.choice(firstNames)) + strconv.Itoa(b.random.intRange(1, 100))
+			},
+		})
+	case "address":
+		return b.fake(args, map[string]func() string{
+			"streetAddress": func() string {
+				return strconv.Itoa(b.random.intRange(1, 200)) + " " + b.random.choice(streets)
+			},
+			"city":    func() string { return b.random.choice(cities) },
+			"country": func() string { return b.random.choice(countries) },
+			"zipCode": func() string { return b.random.text("0123456789", 5) },
+		})
+	default:
+		return "", errors.New("no such generator, expected one of uuid, integer, float, alphabetic, " +
+			"alphanumeric, hexadecimal, email, name.* or address.*")
+	}
+}
+
+// fake returns a value from the fake data generator in generators named by args, which
+// must be exactly one name e.g. "firstName" in '$random.name.firstName'.
+func (b Builtins) fake(args []string, generators map[string]func() string) (string, error) {
+	names := slices.Sorted(maps.Keys(generators))
+
+	if len(args) != 1 {
+		return "", fmt.Errorf("requires one of %s", strings.Join(names, ", "))
+	}
+
+	generator, ok := generators[args[0]]
+	if !ok {
+		return "", fmt.Errorf("unknown %q, expected one of %s", args[0], strings.Join(names, ", "))
+	}
+
+	return generator(), nil
+}
+
+// optionalRange sets lower and upper from the optional '(from, to)' arguments to
+// '$random.integer' and '$random.float', leaving them as they are if there are none.
+func optionalRange(args []string, lower, upper *string) error {
+	const parts = 2 // A from and a to
+
+	switch len(args) {
+	case 0:
+		return nil
+	case parts:
+		*lower, *upper = args[0], args[1]
+		return nil
+	default:
+		return errors.New("expected no arguments or a from and a to e.g. (1, 10)")
+	}
+}
+
+// intRange parses the bounds of a range of integers, from must be less than to.
+func intRange(from, to string) (lower, upper int, err error) {
+	lower, err = strconv.Atoi(from)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid min %q, expected an integer", from)
+	}
+
+	upper, err = strconv.Atoi(to)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid max %q, expected an integer", to)
+	}
+
+	if upper <= lower {
+		return 0, 0, fmt.Errorf("max (%d) must be greater than min (%d)", upper, lower)
+	}
+
+	return lower, upper, nil
+}
+
+// floatRange parses the bounds of a range of floats, from must be less than to.
+func floatRange(from, to string) (lower, upper float64, err error) {
+	lower, err = strconv.ParseFloat(from, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid min %q, expected a number", from)
+	}
+
+	upper, err = strconv.ParseFloat(to, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid max %q, expected a number", to)
+	}
+
+	if upper <= lower {
+		return 0, 0, fmt.Errorf("max (%s) must be greater than min (%s)", to, from)
+	}
+
+	return lower, upper, nil
+}
@@ -0,0 +1,34 @@
+package builtins
+
+import (
+	"fmt"
+	"strings"
+)
+
+// builtinTrim is the implementation of the '$trim' builtin, its argument with leading
+// and trailing whitespace removed e.g. '{{ token | trim }}'.
+func builtinTrim(args ...string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("$trim takes a single value, got %d arguments", len(args))
+	}
+
+	return strings.TrimSpace(args[0]), nil
+}
+
+// builtinUpper is the implementation of the '$upper' builtin, its argument in upper case.
+func builtinUpper(args ...string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("$upper takes a single value, got %d arguments", len(args))
+	}
+
+	return strings.ToUpper(args[0]), nil
+}
+
+// builtinLower is the implementation of the '$lower' builtin, its argument in lower case.
+func builtinLower(args ...string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("$lower takes a single value, got %d arguments", len(args))
+	}
+
+	return strings.ToLower(args[0]), nil
+}
@@ -0,0 +1,234 @@
+package builtins
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Named formats accepted by '$datetime' and '$localDatetime'.
+const (
+	formatRFC1123 = "rfc1123"
+	formatISO8601 = "iso8601"
+)
+
+// rfc1123GMT is the RFC 1123 layout used by HTTP for UTC times e.g. in a Date header.
+const rfc1123GMT = "Mon, 02 Jan 2006 15:04:05 GMT"
+
+// daysPerWeek is the number of days in a week, for 'w' offsets.
+const daysPerWeek = 7
+
+// timestamp is the implementation of the '$timestamp' builtin, the current unix time
+// in seconds.
+//
+// It takes an optional offset e.g. '$timestamp -1 d' for this time yesterday.
+func (b Builtins) timestamp(args ...string) (string, error) {
+	now, err := offset(b.now(), args)
+	if err != nil {
+		return "", fmt.Errorf("$timestamp: %w", err)
+	}
+
+	return strconv.FormatInt(now.Unix(), 10), nil
+}
+
+// isoTimestamp is the implementation of the '$isoTimestamp' builtin, the current UTC
+// time in ISO 8601 format.
+//
+// It takes an optional offset e.g. '$isoTimestamp 2 h' for 2 hours from now.
+func (b Builtins) isoTimestamp(args ...string) (string, error) {
+	now, err := offset(b.now().UTC(), args)
+	if err != nil {
+		return "", fmt.Errorf("$isoTimestamp: %w", err)
+	}
+
+	return now.Format(time.RFC3339), nil
+}
+
+// datetime is the implementation of the '$datetime' builtin, the current UTC time in
+// the given format.
+//
+// The format is 'rfc1123', 'iso8601' or a quoted custom format using Day.js tokens
+// e.g. '$datetime "YYYY-MM-DD"', followed by an optional offset e.g. '$datetime iso8601 1 d'.
+func (b Builtins) datetime(args ...string) (string, error) {
+	return formatDatetime("datetime", b.now().UTC(), args)
+}
+
+// localDatetime is the implementation of the '$localDatetime' builtin, identical to
+// '$datetime' but in local time.
+func (b Builtins) localDatetime(args ...string) (string, error) {
+	return formatDatetime("localDatetime", b.now(), args)
+}
+
+// formatDatetime formats now for the date time builtin called name, given its arguments:
+// a format and an optional offset.
+func formatDatetime(name string, now time.Time, args []string) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("$%s requires a format: %s, %s or a quoted custom format", name, formatRFC1123, formatISO8601)
+	}
+
+	now, err := offset(now, args[1:])
+	if err != nil {
+		return "", fmt.Errorf("$%s: %w", name, err)
+	}
+
+	switch args[0] {
+	case formatRFC1123:
+		if now.Location() == time.UTC {
+			return now.Format(rfc1123GMT), nil
+		}
+
+		return now.Format(time.RFC1123Z), nil
+	case formatISO8601:
+		return now.Format(time.RFC3339), nil
+	default:
+		return customFormat(args[0], now), nil
+	}
+}
+
+// offset applies an offset of the form '<amount> <unit>' e.g. '-1 d' to t, args may
+// also be empty for no offset.
+//
+// The units are y (years), M (months), w (weeks), d (days), h (hours), m (minutes),
+// s (seconds) and ms (milliseconds).
+func offset(t time.Time, args []string) (time.Time, error) {
+	if len(args) == 0 {
+		return t, nil
+	}
+
+	const parts = 2 // An amount and a unit
+	if len(args) != parts {
+		return time.Time{}, fmt.Errorf("expected an offset and a unit e.g. '-1 d', got %q", strings.Join(args, " "))
+	}
+
+	amount, err := strconv.Atoi(args[0])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid offset %q, expected an integer", args[0])
+	}
+
+	switch unit := args[1]; unit {
+	case "y":
+		return t.AddDate(amount, 0, 0), nil
+	case "M":
+		return t.AddDate(0, amount, 0), nil
+	case "w":
+		return t.AddDate(0, 0, amount*daysPerWeek), nil
+	case "d":
+		return t.AddDate(0, 0, amount), nil
+	case "h":
+		return t.Add(time.Duration(amount) * time.Hour), nil
+	case "m":
+		return t.Add(time.Duration(amount) * time.Minute), nil
+	case "s":
+		return t.Add(time.Duration(amount) * time.Second), nil
+	case "ms":
+		return t.Add(time.Duration(amount) * time.Millisecond), nil
+	default:
+		return time.Time{}, fmt.Errorf("unknown offset unit %q, expected one of y, M, w, d, h, m, s or ms", unit)
+	}
+}
+
+// customFormat formats t using a Day.js style format e.g. 'YYYY-MM-DD HH:mm:ss', as
+// used by the VSCode REST client.
+//
+// Text in square brackets is escaped e.g. '[Today is] dddd', and anything that isn't
+// a recognised token is written as is.
+func customFormat(format string, t time.Time) string {
+	// Longest first, so e.g. 'YYYY' isn't taken as 'YY' twice
+	tokens := []string{
+		"YYYY", "MMMM", "dddd", "MMM", "ddd", "SSS", "YY", "MM", "DD", "HH",
+		"hh", "mm", "ss", "ZZ", "M", "D", "H", "h", "m", "s", "A", "a", "Z", "X", "x",
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < len(format); {
+		if format[i] == '[' {
+			if end := strings.IndexByte(format[i:], ']'); end != -1 {
+				b.WriteString(format[i+1 : i+end])
+				i += end + 1
+
+				continue
+			}
+		}
+
+		matched := ""
+
+		for _, token := range tokens {
+			if strings.HasPrefix(format[i:], token) {
+				matched = token
+				break
+			}
+		}
+
+		if matched == "" {
+			b.WriteByte(format[i])
+			i++
+
+			continue
+		}
+
+		b.WriteString(formatToken(matched, t))
+		i += len(matched)
+	}
+
+	return b.String()
+}
+
+// formatToken formats a single Day.js format token for t.
+func formatToken(token string, t time.Time) string {
+	switch token {
+	case "YYYY":
+		return fmt.Sprintf("%04d", t.Year())
+	case "YY":
+		return t.Format("06")
+	case "MMMM":
+		return t.Format("January")
+	case "MMM":
+		return t.Format("Jan")
+	case "MM":
+		return t.Format("01")
+	case "M":
+		return t.Format("1")
+	case "DD":
+		return t.Format("02")
+	case "D":
+		return t.Format("2")
+	case "dddd":
+		return t.Format("Monday")
+	case "ddd":
+		return t.Format("Mon")
+	case "HH":
+		return t.Format("15")
+	case "H":
+		return strconv.Itoa(t.Hour())
+	case "hh":
+		return t.Format("03")
+	case "h":
+		return t.Format("3")
+	case "mm":
+		return t.Format("04")
+	case "m":
+		return t.Format("4")
+	case "ss":
+		return t.Format("05")
+	case "s":
+		return t.Format("5")
+	case "SSS":
+		return fmt.Sprintf("%03d", t.Nanosecond()/int(time.Millisecond))
+	case "A":
+		return t.Format("PM")
+	case "a":
+		return t.Format("pm")
+	case "Z":
+		return t.Format("-07:00")
+	case "ZZ":
+		return t.Format("-0700")
+	case "X":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "x":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	default:
+		return token
+	}
+}
@@ -0,0 +1,117 @@
+package resolver
+
+import (
+	"slices"
+	"strings"
+
+	"go.followtheprocess.codes/zap/internal/jsonpath"
+	"go.followtheprocess.codes/zap/internal/spec"
+	"go.followtheprocess.codes/zap/internal/syntax/ast"
+)
+
+// resolveCaptureStatement resolves a request level @capture statement, validating the
+// source and setting the variable in the global scope so later requests may use it.
+//
+// A capture may set a variable that is already defined, a global or one captured by an
+// earlier request, later requests then use the captured value.
+func (r *Resolver) resolveCaptureStatement(env *environment, statement ast.CaptureStatement) (spec.Capture, error) {
+	capture := spec.Capture{
+		Name:   statement.Ident.Name,
+		Source: strings.TrimSpace(statement.Source.Value),
+	}
+
+	switch source := capture.Source; {
+	case source == "status", source == "body":
+		// Nothing to validate
+	case strings.HasPrefix(source, "header."):
+		if strings.TrimPrefix(source, "header.") == "" {
+			return spec.Capture{}, r.error(statement.Source, "header capture missing header name e.g. header.ETag")
+		}
+	case strings.HasPrefix(source, "$"):
+		if _, err := jsonpath.Parse(source); err != nil {
+			return spec.Capture{}, r.error(statement.Source, err.Error())
+		}
+
+		if !slices.ContainsFunc([]string{"$.status", "$.headers", "$.body"}, func(prefix string) bool {
+			rest, ok := strings.CutPrefix(source, prefix)
+			return ok && (rest == "" || rest[0] == '.' || rest[0] == '[')
+		}) {
+			return spec.Capture{}, r.errorf(
+				statement.Source,
+				"capture JSONPath %q must select from $.status, $.headers or $.body",
+				source,
+			)
+		}
+	default:
+		return spec.Capture{}, r.errorf(
+			statement.Source,
+			"invalid capture source %q, expected status, body, header.<name> or a JSONPath expression",
+			source,
+		)
+	}
+
+	// The value isn't known until the request is executed so, like prompts, defer it
+	// until runtime in the global scope. Requests are resolved in a child of it
+	env.parent.set(capture.Name, spec.Expr(capture.Name))
+
+	return capture, nil
+}
+
+// linkCaptures adds the requests that capture variables to the dependencies of the
+// requests that use them, so they are always executed first.
+//
+// A use of a variable depends on the last request before it to capture it, whether it's
+// used directly or by one of the deferred globals in globals.
+func linkCaptures(requests []spec.Request, globals map[string]spec.Template) {
+	capturedBy := make(map[string]string)
+
+	for i, request := range requests {
+		for _, name := range variables(request, globals) {
+			dependency, ok := capturedBy[name]
+			if ok && dependency != request.Name && !slices.Contains(request.DependsOn, dependency) {
+				request.DependsOn = append(request.DependsOn, dependency)
+			}
+		}
+
+		// Captures are only visible to the requests after this one
+		for _, capture := range request.Captures {
+			capturedBy[capture.Name] = request.Name
+		}
+
+		requests[i] = request
+	}
+}
+
+// variables returns the names of the variables deferred in request, along with those
+// deferred in any of globals it uses, in the order they are first used.
+func variables(request spec.Request, globals map[string]spec.Template) []string {
+	var (
+		names []string
+		walk  func(template spec.Template)
+	)
+
+	walk = func(template spec.Template) {
+		for _, name := range Variables(template) {
+			if slices.Contains(names, name) {
+				continue
+			}
+
+			names = append(names, name)
+
+			if _, local := request.Vars[name]; local {
+				// Already walked in the request's own values
+				continue
+			}
+
+			if global, ok := globals[name]; ok {
+				walk(global)
+			}
+		}
+	}
+
+	for _, value := range deferredValues(request) {
+		walk(value)
+	}
+
+	return names
+}
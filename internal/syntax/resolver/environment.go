@@ -1,23 +1,32 @@
 package resolver
 
-import "fmt"
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"go.followtheprocess.codes/zap/internal/spec"
+	"go.followtheprocess.codes/zap/internal/syntax/resolver/builtins"
+)
 
 // environment is a scoped environment for the resolver.
 type environment struct {
-	values map[string]string
+	values map[string]spec.Template
+	used   map[string]bool // Variables in this scope that have been looked up
 	parent *environment
 }
 
 // newEnvironment creates a new, empty [environment] with no parent.
 func newEnvironment() *environment {
 	return &environment{
-		values: make(map[string]string),
+		values: make(map[string]spec.Template),
+		used:   make(map[string]bool),
 		parent: nil,
 	}
 }
 
 // define defines a new variable in the innermost scope.
-func (e *environment) define(key, value string) error {
+func (e *environment) define(key string, value spec.Template) error {
 	if _, exists := e.values[key]; exists {
 		return fmt.Errorf("variable %s already defined", key)
 	}
@@ -27,10 +36,17 @@ func (e *environment) define(key, value string) error {
 	return nil
 }
 
+// set sets a variable in the innermost scope, defining it or replacing the value it
+// already has.
+func (e *environment) set(key string, value spec.Template) {
+	e.values[key] = value
+}
+
 // get walks up the scope to find a variable by name, if it reaches the outermost
 // scope without finding it, it returns an error.
-func (e *environment) get(key string) (string, error) {
+func (e *environment) get(key string) (spec.Template, error) {
 	if value, ok := e.values[key]; ok {
+		e.used[key] = true
 		return value, nil
 	}
 
@@ -38,13 +54,21 @@ func (e *environment) get(key string) (string, error) {
 		return e.parent.get(key)
 	}
 
-	return "", fmt.Errorf("use of undeclared variable %s", key)
+	return nil, builtins.Undefined("use of undeclared variable %s", key)
 }
 
 // child creates a new empty [environment] using the calling one as a parent.
 func (e *environment) child() *environment {
 	return &environment{
-		values: make(map[string]string),
+		values: make(map[string]spec.Template),
+		used:   make(map[string]bool),
 		parent: e,
 	}
 }
+
+// unused returns the variables defined in this scope that have never been looked
+// up, sorted alphabetically.
+func (e *environment) unused() []string {
+	unused := slices.Sorted(maps.Keys(e.values))
+	return slices.DeleteFunc(unused, func(key string) bool { return e.used[key] })
+}
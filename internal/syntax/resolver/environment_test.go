@@ -1,9 +1,11 @@
 package resolver //nolint:testpackage // environment is intentionally internal.
 
 import (
+	"slices"
 	"testing"
 
 	"go.followtheprocess.codes/test"
+	"go.followtheprocess.codes/zap/internal/spec"
 )
 
 func TestEnvironment(t *testing.T) {
@@ -12,59 +14,98 @@ func TestEnvironment(t *testing.T) {
 
 		got, err := env.get("anything")
 		test.Err(t, err)
-		test.Equal(t, got, "")
+		test.Equal(t, len(got), 0)
 	})
 
 	t.Run("full", func(t *testing.T) {
 		env := newEnvironment()
 
-		test.Ok(t, env.define("something", "here"))
-		test.Ok(t, env.define("other", "too"))
+		test.Ok(t, env.define("something", spec.Text("here")))
+		test.Ok(t, env.define("other", spec.Text("too")))
 
 		// Try and define "something" again in the same scope
-		test.Err(t, env.define("something", "else"))
+		test.Err(t, env.define("something", spec.Text("else")))
 
 		something, err := env.get("something")
 		test.Ok(t, err)
-		test.Equal(t, something, "here")
+		test.EqualFunc(t, something, spec.Text("here"), slices.Equal)
 
 		other, err := env.get("other")
 		test.Ok(t, err)
-		test.Equal(t, other, "too")
+		test.EqualFunc(t, other, spec.Text("too"), slices.Equal)
 	})
 
 	t.Run("parent", func(t *testing.T) {
 		env := newEnvironment()
 
 		// Define some globals
-		test.Ok(t, env.define("something", "here"))
-		test.Ok(t, env.define("other", "too"))
+		test.Ok(t, env.define("something", spec.Text("here")))
+		test.Ok(t, env.define("other", spec.Text("too")))
 
 		// Create a child scope
 		child := env.child()
 
 		// Define some locals
-		test.Ok(t, child.define("more", "here"))
-		test.Ok(t, child.define("another", "yes"))
+		test.Ok(t, child.define("more", spec.Text("here")))
+		test.Ok(t, child.define("another", spec.Text("yes")))
 
 		// Override a global with a local
-		test.Ok(t, child.define("something", "child something value"))
+		test.Ok(t, child.define("something", spec.Text("child something value")))
 
 		// Use the child to access
 		other, err := child.get("other")
 		test.Ok(t, err)
-		test.Equal(t, other, "too") // Comes from globals
+		test.EqualFunc(t, other, spec.Text("too"), slices.Equal) // Comes from globals
 
 		more, err := child.get("more")
 		test.Ok(t, err)
-		test.Equal(t, more, "here") // Comes from locals
+		test.EqualFunc(t, more, spec.Text("here"), slices.Equal) // Comes from locals
 
 		something, err := child.get("something")
 		test.Ok(t, err)
-		test.Equal(t, something, "child something value") // Prefers local scope
+		test.EqualFunc(t, something, spec.Text("child something value"), slices.Equal) // Prefers local scope
 
 		something, err = env.get("something")
 		test.Ok(t, err)
-		test.Equal(t, something, "here") // Using the global env again
+		test.EqualFunc(t, something, spec.Text("here"), slices.Equal) // Using the global env again
+	})
+
+	t.Run("set", func(t *testing.T) {
+		env := newEnvironment()
+
+		// Set defines a new variable
+		env.set("token", spec.Text("initial"))
+
+		token, err := env.get("token")
+		test.Ok(t, err)
+		test.EqualFunc(t, token, spec.Text("initial"), slices.Equal)
+
+		// And replaces one that's already defined, where define would fail
+		test.Err(t, env.define("token", spec.Text("again")))
+		env.set("token", spec.Expr("token"))
+
+		token, err = env.get("token")
+		test.Ok(t, err)
+		test.EqualFunc(t, token, spec.Expr("token"), slices.Equal)
+	})
+
+	t.Run("unused", func(t *testing.T) {
+		env := newEnvironment()
+
+		test.Ok(t, env.define("used", spec.Text("yes")))
+		test.Ok(t, env.define("unused", spec.Text("no")))
+		test.Ok(t, env.define("shadowed", spec.Text("no")))
+
+		child := env.child()
+		test.Ok(t, child.define("shadowed", spec.Text("yes")))
+
+		_, err := child.get("used")
+		test.Ok(t, err)
+
+		_, err = child.get("shadowed")
+		test.Ok(t, err)
+
+		test.EqualFunc(t, env.unused(), []string{"shadowed", "unused"}, slices.Equal)
+		test.EqualFunc(t, child.unused(), []string{}, slices.Equal)
 	})
 }
@@ -0,0 +1,103 @@
+package resolver
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.followtheprocess.codes/zap/internal/spec"
+	"go.followtheprocess.codes/zap/internal/syntax/ast"
+)
+
+// promptTypes are the valid prompt value types, mapped to the function reporting
+// whether a value is of that type.
+var promptTypes = map[string]func(value string) bool{
+	"string": func(string) bool { return true },
+	"int": func(value string) bool {
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	},
+	"float": func(value string) bool {
+		_, err := strconv.ParseFloat(value, 64)
+		return err == nil
+	},
+	"bool": func(value string) bool {
+		_, err := strconv.ParseBool(value)
+		return err == nil
+	},
+	"uuid": func(value string) bool {
+		return uuid.Validate(value) == nil
+	},
+	"url": func(value string) bool {
+		parsed, err := url.ParseRequestURI(value)
+		return err == nil && parsed.Scheme != "" && parsed.Host != ""
+	},
+}
+
+// ValidatePrompt reports whether value is an acceptable answer to prompt, that is it
+// is a valid value of the prompt's type and one of its choices if it has any.
+func ValidatePrompt(prompt spec.Prompt, value string) error {
+	if len(prompt.Choices) != 0 && !slices.Contains(prompt.Choices, value) {
+		return fmt.Errorf("%q is not one of (%s)", value, strings.Join(prompt.Choices, "|"))
+	}
+
+	if prompt.Type == "" {
+		return nil
+	}
+
+	validate, ok := promptTypes[prompt.Type]
+	if !ok {
+		return fmt.Errorf("unknown prompt type %q", prompt.Type)
+	}
+
+	if !validate(value) {
+		return fmt.Errorf("%q is not a valid %s", value, prompt.Type)
+	}
+
+	return nil
+}
+
+// resolvePrompt resolves a @prompt statement into a [spec.Prompt], validating its type
+// and that any choices and default are valid values of it.
+func (r *Resolver) resolvePrompt(statement ast.PromptStatement) (spec.Prompt, error) {
+	prompt := spec.Prompt{
+		Name:        statement.Ident.Name,
+		Description: statement.Description.Value,
+		Default:     statement.Default.Value,
+		Type:        statement.ValueType.Name,
+		Secret:      statement.Secret,
+	}
+
+	if _, ok := promptTypes[prompt.Type]; prompt.Type != "" && !ok {
+		return spec.Prompt{}, r.errorf(
+			statement.ValueType,
+			"unknown prompt type %q, expected one of (%s)",
+			prompt.Type,
+			strings.Join(slices.Sorted(maps.Keys(promptTypes)), "|"),
+		)
+	}
+
+	for _, choice := range statement.Choices {
+		if slices.Contains(prompt.Choices, choice.Value) {
+			return spec.Prompt{}, r.errorf(choice, "duplicate prompt choice %q", choice.Value)
+		}
+
+		if err := ValidatePrompt(spec.Prompt{Type: prompt.Type}, choice.Value); err != nil {
+			return spec.Prompt{}, r.errorf(choice, "invalid prompt choice: %v", err)
+		}
+
+		prompt.Choices = append(prompt.Choices, choice.Value)
+	}
+
+	if prompt.Default != "" {
+		if err := ValidatePrompt(prompt, prompt.Default); err != nil {
+			return spec.Prompt{}, r.errorf(statement.Default, "invalid prompt default: %v", err)
+		}
+	}
+
+	return prompt, nil
+}
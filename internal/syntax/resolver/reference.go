@@ -0,0 +1,240 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"go.followtheprocess.codes/zap/internal/jsonpath"
+	"go.followtheprocess.codes/zap/internal/spec"
+	"go.followtheprocess.codes/zap/internal/syntax/ast"
+	"go.followtheprocess.codes/zap/internal/xpath"
+)
+
+// Reference is a reference to part of another request or it's response.
+type Reference struct {
+	// Request is the name of the referenced request.
+	Request string
+
+	// Source is what is referenced, either "request" or "response".
+	Source string
+
+	// Part is the part of the request or response referenced, one
+	// of "body", "headers" or "status".
+	Part string
+
+	// Selector selects from the part, it is the header name for headers or
+	// a JSONPath or XPath query for bodies. An empty or '*' selector on a body
+	// refers to the whole body.
+	Selector string
+}
+
+// String implements [fmt.Stringer] for a [Reference], returning it in the form
+// it is written inside an interpolation e.g. 'login.response.body.$.token'.
+func (r Reference) String() string {
+	parts := []string{r.Request, r.Source, r.Part}
+	if r.Selector != "" {
+		parts = append(parts, r.Selector)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// deferred returns the interpolation deferring the reference until runtime.
+func (r Reference) deferred() spec.Template {
+	return spec.Expr(r.String())
+}
+
+// resolveReference resolves a selector expression referring to another request or it's
+// response e.g. '{{ login.response.body.$.token }}', validating the reference and
+// deferring it until the referenced request has been executed.
+func (r *Resolver) resolveReference(root ast.Ident, selectors []ast.Expression) (spec.Template, error) {
+	if !slices.Contains(r.requests, root.Name) {
+		return nil, r.errorf(root, "no request named %s to reference", root.Name)
+	}
+
+	reference, node, err := newReference(root, selectors)
+	if err != nil {
+		return nil, r.error(node, err.Error())
+	}
+
+	return reference.deferred(), nil
+}
+
+// newReference returns the [Reference] made by the root and selectors of a selector
+// expression, along with the node at fault if it is not a valid reference.
+func newReference(root ast.Ident, selectors []ast.Expression) (Reference, ast.Node, error) {
+	names := make([]string, 0, len(selectors))
+
+	for _, selector := range selectors {
+		switch selector := selector.(type) {
+		case ast.Ident:
+			names = append(names, selector.Name)
+		case ast.Query:
+			names = append(names, selector.Value)
+		}
+	}
+
+	// The last thing in the selector chain, diagnostics point here
+	last := selectors[len(selectors)-1]
+
+	// The request name is the root, so the selectors need at least a source and part
+	if len(names) == 1 {
+		return Reference{}, last, fmt.Errorf(
+			"incomplete reference to request %s, expected e.g. %s.response.body",
+			root.Name,
+			root.Name,
+		)
+	}
+
+	reference := Reference{
+		Request: root.Name,
+		Source:  names[0],
+		Part:    names[1],
+	}
+
+	rest := selectors[2:]
+
+	if reference.Source != "request" && reference.Source != "response" {
+		return Reference{}, selectors[0], fmt.Errorf("expected request or response, got %s", reference.Source)
+	}
+
+	switch reference.Part {
+	case "status":
+		if reference.Source != "response" {
+			return Reference{}, selectors[1], errors.New("only a response has a status")
+		}
+
+		if len(rest) != 0 {
+			return Reference{}, rest[0], errors.New("status takes no selector")
+		}
+	case "headers":
+		if len(rest) != 1 {
+			return Reference{}, last, errors.New("headers requires a header name e.g. headers.Content-Type")
+		}
+
+		header, ok := rest[0].(ast.Ident)
+		if !ok {
+			return Reference{}, rest[0], errors.New("headers requires a header name e.g. headers.Content-Type")
+		}
+
+		reference.Selector = header.Name
+	case "body":
+		if len(rest) > 1 {
+			return Reference{}, rest[1], errors.New("body takes at most one JSONPath or XPath selector")
+		}
+
+		if len(rest) == 1 {
+			query, ok := rest[0].(ast.Query)
+			if !ok {
+				return Reference{}, rest[0], errors.New(
+					"body selector must be a JSONPath ('$...'), XPath ('/...') or '*'",
+				)
+			}
+
+			if err := validateQuery(query.Value); err != nil {
+				return Reference{}, query, err
+			}
+
+			reference.Selector = query.Value
+		}
+	default:
+		return Reference{}, selectors[1], fmt.Errorf("expected body, headers or status, got %s", reference.Part)
+	}
+
+	return reference, nil, nil
+}
+
+// validateQuery checks a body selector query is valid.
+func validateQuery(query string) error {
+	switch {
+	case query == "*":
+		return nil
+	case strings.HasPrefix(query, "$"):
+		_, err := jsonpath.Parse(query)
+		return err
+	default:
+		_, err := xpath.Parse(query)
+		return err
+	}
+}
+
+// requestNames returns the names of all the requests in the file declared
+// with '@name' that may be referenced by others.
+func requestNames(file ast.File) []string {
+	var names []string
+
+	for _, statement := range file.Statements {
+		request, ok := statement.(ast.Request)
+		if !ok {
+			continue
+		}
+
+		for _, variable := range request.Vars {
+			if name, ok := variable.Value.(ast.TextLiteral); ok && variable.Ident.Name == "name" {
+				names = append(names, name.Value)
+			}
+		}
+	}
+
+	return names
+}
+
+// dependencies returns the names of the requests referenced anywhere in request, in
+// the order they are first referenced.
+func dependencies(request spec.Request) []string {
+	var names []string
+
+	for _, value := range deferredValues(request) {
+		for _, reference := range References(value) {
+			if !slices.Contains(names, reference.Request) {
+				names = append(names, reference.Request)
+			}
+		}
+	}
+
+	return names
+}
+
+// deferredValues returns everything in request that may contain an interpolation
+// deferred until runtime.
+func deferredValues(request spec.Request) []spec.Template {
+	values := []spec.Template{request.URL, request.Body, request.BodyFile, request.ResponseFile, request.ResponseRef}
+
+	for _, key := range slices.Sorted(maps.Keys(request.Vars)) {
+		values = append(values, request.Vars[key])
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(request.Headers)) {
+		values = append(values, request.Headers[key]...)
+	}
+
+	for _, assertion := range request.Assertions {
+		values = append(values, assertion.Value)
+	}
+
+	return values
+}
+
+// flattenSelector unwraps a (possibly nested) selector expression e.g. 'a.b.c' into
+// the expression at the root, 'a', and the selectors applied to it in order, 'b' and 'c'.
+func flattenSelector(selector ast.SelectorExpression) (ast.Expression, []ast.Expression) {
+	selectors := []ast.Expression{selector.Selector}
+	root := selector.Expr
+
+	for {
+		inner, ok := root.(ast.SelectorExpression)
+		if !ok {
+			break
+		}
+
+		selectors = append(selectors, inner.Selector)
+		root = inner.Expr
+	}
+
+	slices.Reverse(selectors)
+
+	return root, selectors
+}
@@ -2,6 +2,12 @@
 //
 // The resolution stage evaluates interpolations, parses durations and otherwise makes
 // the structure concrete, resulting in a [spec.File].
+//
+// Some values are only known once the file is executed: the answers to prompts, values
+// captured from responses and references to other requests. Rather than a value, these
+// interpolations are deferred as segments of a [spec.Template] holding the expression as it
+// is written e.g. 'token', to be evaluated against a runtime [Scope] with [Evaluate] when the
+// request is executed.
 package resolver
 
 import (
@@ -9,31 +15,24 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"os"
 	"path/filepath"
+	"regexp"
 	"slices"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
+	"go.followtheprocess.codes/zap/internal/jsonpath"
 	"go.followtheprocess.codes/zap/internal/spec"
 	"go.followtheprocess.codes/zap/internal/syntax"
 	"go.followtheprocess.codes/zap/internal/syntax/ast"
+	"go.followtheprocess.codes/zap/internal/syntax/parser"
 	"go.followtheprocess.codes/zap/internal/syntax/resolver/builtins"
 	"go.followtheprocess.codes/zap/internal/syntax/token"
 )
 
-const (
-	// PromptPlaceholderGlobal is the placeholder prefix for a global prompt variable.
-	//
-	// It should be concatenated at runtime with the name of the prompt variable to form
-	// a unique key with which to look up the prompt value.
-	PromptPlaceholderGlobal = "zap::prompt::global::"
-
-	// PromptPlaceholderLocal is the placeholder prefix for a local prompt variable.
-	//
-	// It should be concatenated at runtime with the name of the prompt variable to form
-	// a unique key with which to look up the prompt value.
-	PromptPlaceholderLocal = "zap::prompt::local::"
-)
-
 // ErrResolve is a generic resolving error, details on the error are provided through
 // a [Diagnostic].
 var ErrResolve = errors.New("resolve error")
@@ -45,19 +44,56 @@ var ErrResolve = errors.New("resolve error")
 // along the way.
 type Resolver struct {
 	library     builtins.Library    // Library of builtins to draw from.
+	environment map[string]string   // Variables from the selected environment file environment, if any.
+	overrides   map[string]string   // Variables given by the user, taking precedence over the file's globals.
+	overridden  *environment        // The scope holding the overrides, so their use can be tracked.
 	name        string              // The name of the file being resolved.
 	src         []byte              // Raw source
 	diagnostics []syntax.Diagnostic // Diagnostics collected during resolving.
+	requests    []string            // Names of the requests in the file, which others may reference.
 	hadErrors   bool                // Whether we encountered resolver errors.
 }
 
+// Option is a functional option for configuring a [Resolver].
+type Option func(r *Resolver)
+
+// WithEnvironment sets the variables of the selected environment, e.g. one
+// from 'http-client.env.json'.
+//
+// They are resolved in a scope below the file's globals so a global variable of
+// the same name takes precedence.
+func WithEnvironment(variables map[string]string) Option {
+	return func(r *Resolver) {
+		r.environment = variables
+	}
+}
+
+// WithOverrides sets variables given by the user e.g. on the command line.
+//
+// They take precedence over the file's globals (and so over the selected
+// environment), but not over variables declared on a request.
+func WithOverrides(variables map[string]string) Option {
+	return func(r *Resolver) {
+		r.overrides = variables
+	}
+}
+
 // New returns a new [Resolver].
-func New(name string, src []byte, library builtins.Library) *Resolver {
-	return &Resolver{
+//
+// The builtins that read local files e.g. '$dotenv' are added to library, reading
+// files relative to the directory containing the file called name.
+func New(name string, src []byte, library builtins.Library, options ...Option) *Resolver {
+	r := &Resolver{
 		name:    name,
 		src:     src,
-		library: library,
+		library: builtins.Local(library, filepath.Dir(name)),
+	}
+
+	for _, option := range options {
+		option(r)
 	}
+
+	return r
 }
 
 // Resolve resolves an [ast.File] into a concrete [spec.File].
@@ -67,14 +103,35 @@ func New(name string, src []byte, library builtins.Library) *Resolver {
 func (r *Resolver) Resolve(in ast.File) (spec.File, error) {
 	file := spec.File{
 		Name:     in.Name,
-		Vars:     make(map[string]string),
+		Vars:     make(map[string]spec.Template),
 		Prompts:  make(map[string]spec.Prompt),
 		Requests: []spec.Request{},
 	}
 
 	var errs []error
 
+	// The selected environment sits below the file's globals, so globals may override it
 	env := newEnvironment()
+	for key, value := range r.environment {
+		if err := env.define(key, spec.Text(value)); err != nil {
+			return spec.File{}, err
+		}
+	}
+
+	// Then the overrides, globals of the same name take their value from here
+	env = env.child()
+	for key, value := range r.overrides {
+		if err := env.define(key, spec.Text(value)); err != nil {
+			return spec.File{}, err
+		}
+	}
+
+	r.overridden = env
+	env = env.child()
+
+	// Requests may reference others by name before or after them in the file
+	// so gather all the names up front
+	r.requests = requestNames(in)
 
 	for _, statement := range in.Statements {
 		err := r.resolveFileStatement(env, &file, statement)
@@ -91,9 +148,23 @@ func (r *Resolver) Resolve(in ast.File) (spec.File, error) {
 		return spec.File{}, fmt.Errorf("%w: %w", ErrResolve, errors.Join(errs...))
 	}
 
+	linkCaptures(file.Requests, file.Vars)
+
 	return file, nil
 }
 
+// UnusedOverrides returns the names of the variables passed with [WithOverrides] that
+// were not used anywhere in the file, sorted alphabetically.
+//
+// It is only meaningful after calling [Resolver.Resolve].
+func (r *Resolver) UnusedOverrides() []string {
+	if r.overridden == nil {
+		return nil
+	}
+
+	return r.overridden.unused()
+}
+
 // Diagnostics returns the diagnostics gathered during resolving.
 func (r *Resolver) Diagnostics() []syntax.Diagnostic {
 	slices.SortFunc(r.diagnostics, func(a, b syntax.Diagnostic) int {
@@ -214,6 +285,19 @@ func (r *Resolver) resolveGlobalVarStatement(env *environment, file *spec.File,
 		return nil
 	}
 
+	if _, overridden := r.overrides[key]; overridden && !isKeyword {
+		// The user's value wins, so the one in the file doesn't need resolving
+		// and is left to be found in the overrides scope
+		value, err := env.get(key)
+		if err != nil {
+			return r.error(statement.Value, err.Error())
+		}
+
+		file.Vars[key] = value
+
+		return nil
+	}
+
 	value, err := r.resolveExpression(env, statement.Value)
 	if err != nil {
 		return r.errorf(statement.Value, "failed to resolve value expression for key %s: %v", key, err)
@@ -222,10 +306,10 @@ func (r *Resolver) resolveGlobalVarStatement(env *environment, file *spec.File,
 	if !isKeyword {
 		// Normal var
 		if file.Vars == nil {
-			file.Vars = make(map[string]string)
+			file.Vars = make(map[string]spec.Template)
 		}
 
-		if err := env.define(key, value); err != nil {
+		if err := env.define(key, r.reference(key, value)); err != nil {
 			return r.error(statement.Value, err.Error())
 		}
 
@@ -234,24 +318,37 @@ func (r *Resolver) resolveGlobalVarStatement(env *environment, file *spec.File,
 		return nil
 	}
 
+	text, err := r.evaluateNow(value)
+	if err != nil {
+		return r.errorf(statement.Value, "failed to evaluate value expression for key %s: %v", key, err)
+	}
+
 	// Otherwise, handle the specific keyword by setting the right field
 	switch kind {
 	case token.Name:
-		file.Name = value
+		file.Name = text
 	case token.Timeout:
-		duration, err := time.ParseDuration(value)
+		duration, err := time.ParseDuration(text)
 		if err != nil {
 			return r.errorf(statement.Value, "invalid timeout value: %v", err)
 		}
 
 		file.Timeout = duration
 	case token.ConnectionTimeout:
-		duration, err := time.ParseDuration(value)
+		duration, err := time.ParseDuration(text)
 		if err != nil {
 			return r.errorf(statement.Value, "invalid connection-timeout value: %v", err)
 		}
 
 		file.ConnectionTimeout = duration
+	case token.Ignore:
+		if _, err := jsonpath.Parse(text); err != nil {
+			return r.errorf(statement.Value, "invalid ignore value: %v", err)
+		}
+
+		file.Ignore = append(file.Ignore, text)
+	case token.IgnoreHeader:
+		file.IgnoreHeaders = append(file.IgnoreHeaders, http.CanonicalHeaderKey(text))
 	default:
 		return fmt.Errorf("unhandled keyword: %s", kind)
 	}
@@ -268,9 +365,9 @@ func (r *Resolver) resolveGlobalPromptStatement(
 ) error {
 	name := statement.Ident.Name
 
-	prompt := spec.Prompt{
-		Name:        name,
-		Description: statement.Description.Value,
+	prompt, err := r.resolvePrompt(statement)
+	if err != nil {
+		return err
 	}
 
 	if _, exists := file.Prompts[name]; exists {
@@ -284,8 +381,7 @@ func (r *Resolver) resolveGlobalPromptStatement(
 	}
 
 	// We obviously don't know the value of the prompt yet, this comes later when the user actually
-	// runs the file and is prompted for the answer, so for now insert a unique placeholder for
-	// each prompt which we can easily swap out once prompts are resolved.
+	// runs the file and is prompted for the answer, so defer it until runtime.
 	//
 	// This means that something like:
 	// @prompt id
@@ -294,7 +390,7 @@ func (r *Resolver) resolveGlobalPromptStatement(
 	// GET https://someurl.com/users/{{ id }}
 	//
 	// Won't think 'id' is missing and fail because it's not defined yet.
-	if err := env.define(name, PromptPlaceholderGlobal+name); err != nil {
+	if err := env.define(name, spec.Expr(name)); err != nil {
 		return r.errorf(statement.Ident, "prompt %s shadows global variable of the same name: %v", name, err)
 	}
 
@@ -306,8 +402,8 @@ func (r *Resolver) resolveGlobalPromptStatement(
 // resolveRequestStatement resolves an [ast.Request] into a [spec.Request].
 func (r *Resolver) resolveRequestStatement(env *environment, in ast.Request) (spec.Request, error) {
 	request := spec.Request{
-		Vars:    make(map[string]string),
-		Headers: make(http.Header),
+		Vars:    make(map[string]spec.Template),
+		Headers: make(spec.Headers),
 		Prompts: make(map[string]spec.Prompt),
 	}
 
@@ -343,6 +439,16 @@ func (r *Resolver) resolveRequestStatement(env *environment, in ast.Request) (sp
 		}
 	}
 
+	for _, assertStatement := range in.Assertions {
+		assertion, err := r.resolveAssertStatement(env, assertStatement)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		request.Assertions = append(request.Assertions, assertion)
+	}
+
 	method, err := r.resolveHTTPMethod(in.Method)
 	if err != nil {
 		return spec.Request{}, err
@@ -355,20 +461,26 @@ func (r *Resolver) resolveRequestStatement(env *environment, in ast.Request) (sp
 		return spec.Request{}, r.errorf(in.URL, "failed to resolve URL expression: %v", err)
 	}
 
-	// Validate the URL here
-	parsed, err := url.ParseRequestURI(rawURL)
-	if err != nil {
-		return spec.Request{}, r.errorf(in.URL, "invalid URL %s: %v", rawURL, err)
-	}
+	if rawURL.Deferred() {
+		// URLs containing deferred values, e.g. prompts, can only be validated once
+		// they have been evaluated at runtime
+		request.URL = rawURL
+	} else {
+		// Validate the URL here
+		parsed, err := url.ParseRequestURI(rawURL.String())
+		if err != nil {
+			return spec.Request{}, r.errorf(in.URL, "invalid URL %s: %v", rawURL, err)
+		}
 
-	request.URL = parsed.String()
+		request.URL = spec.Text(parsed.String())
+	}
 
 	// HTTP Headers
 	for _, header := range in.Headers {
 		// Otherwise err shadows one from earlier
 		var (
 			key   string
-			value string
+			value spec.Template
 		)
 
 		key, value, err = r.resolveHeader(env, header)
@@ -385,25 +497,25 @@ func (r *Resolver) resolveRequestStatement(env *environment, in ast.Request) (sp
 	}
 
 	if in.ResponseRedirect != nil {
-		var redirect string
+		var redirect spec.Template
 
 		redirect, err = r.resolveExpression(env, in.ResponseRedirect.File)
 		if err != nil {
 			return spec.Request{}, r.errorf(in.ResponseRedirect.File, "invalid response redirect expression: %v", err)
 		}
 
-		request.ResponseFile = filepath.Clean(redirect)
+		request.ResponseFile = cleanPath(redirect)
 	}
 
 	if in.ResponseReference != nil {
-		var reference string
+		var reference spec.Template
 
 		reference, err = r.resolveExpression(env, in.ResponseReference.File)
 		if err != nil {
 			return spec.Request{}, r.errorf(in.ResponseReference.File, "invalid response reference expression: %v", err)
 		}
 
-		request.ResponseRef = filepath.Clean(reference)
+		request.ResponseRef = cleanPath(reference)
 	}
 
 	// Bubble up all the errors at once
@@ -412,31 +524,46 @@ func (r *Resolver) resolveRequestStatement(env *environment, in ast.Request) (sp
 		return spec.Request{}, err
 	}
 
+	// Captures are only visible to the requests after this one, so resolve
+	// them last
+	for _, captureStatement := range in.Captures {
+		capture, err := r.resolveCaptureStatement(env, captureStatement)
+		if err != nil {
+			return spec.Request{}, err
+		}
+
+		request.Captures = append(request.Captures, capture)
+	}
+
+	request.DependsOn = dependencies(request)
+
 	return request, nil
 }
 
 // resolveExpression resolves an [ast.Expression].
 //
 // The environment is passed in to provide access to local and global scopes.
-func (r *Resolver) resolveExpression(env *environment, expression ast.Expression) (string, error) {
+func (r *Resolver) resolveExpression(env *environment, expression ast.Expression) (spec.Template, error) {
 	if expression == nil {
 		// Nil expressions are okay, e.g. in the this interp:
 		// Authorization: Bearer {{ token }}
 		// Left: "Bearer " (TextLiteral)
 		// Interp: {{ token }}
 		// Right: nil
-		return "", nil
+		return nil, nil
 	}
 
 	switch expr := expression.(type) {
 	case ast.TextLiteral:
-		return expr.Value, nil
+		return spec.Text(expr.Value), nil
 	case ast.Body:
-		return expr.Value, nil
+		return spec.Text(expr.Value), nil
 	case ast.Ident:
 		return r.resolveIdent(env, expr)
-	case ast.Builtin:
-		return r.resolveBuiltin(expr)
+	case ast.Builtin, ast.CallExpression:
+		return r.resolveBuiltinCall(env, expr)
+	case ast.BinaryExpression:
+		return r.resolveBinaryExpression(env, expr)
 	case ast.InterpolatedExpression:
 		return r.resolveInterpolatedExpression(env, expr)
 	case ast.SelectorExpression:
@@ -447,7 +574,7 @@ func (r *Resolver) resolveExpression(env *environment, expression ast.Expression
 		return r.resolveExpression(env, expr.Value)
 
 	default:
-		return "", fmt.Errorf("unhandled ast expression: %T", expr)
+		return nil, fmt.Errorf("unhandled ast expression: %T", expr)
 	}
 }
 
@@ -503,10 +630,10 @@ func (r *Resolver) resolveRequestVarStatement(
 	if !isKeyword {
 		// Normal var
 		if request.Vars == nil {
-			request.Vars = make(map[string]string)
+			request.Vars = make(map[string]spec.Template)
 		}
 
-		if err := env.define(key, value); err != nil {
+		if err := env.define(key, r.reference(key, value)); err != nil {
 			return r.error(statement.Ident, err.Error())
 		}
 
@@ -515,24 +642,37 @@ func (r *Resolver) resolveRequestVarStatement(
 		return nil
 	}
 
+	text, err := r.evaluateNow(value)
+	if err != nil {
+		return r.errorf(statement.Value, "failed to evaluate value expression for key %s: %v", key, err)
+	}
+
 	// Otherwise, handle the specific keyword by setting the right field
 	switch kind {
 	case token.Name:
-		request.Name = value
+		request.Name = text
 	case token.Timeout:
-		duration, err := time.ParseDuration(value)
+		duration, err := time.ParseDuration(text)
 		if err != nil {
 			return r.errorf(statement.Value, "invalid timeout value: %v", err)
 		}
 
 		request.Timeout = duration
 	case token.ConnectionTimeout:
-		duration, err := time.ParseDuration(value)
+		duration, err := time.ParseDuration(text)
 		if err != nil {
 			return r.errorf(statement.Value, "invalid connection-timeout value: %v", err)
 		}
 
 		request.ConnectionTimeout = duration
+	case token.Ignore:
+		if _, err := jsonpath.Parse(text); err != nil {
+			return r.errorf(statement.Value, "invalid ignore value: %v", err)
+		}
+
+		request.Ignore = append(request.Ignore, text)
+	case token.IgnoreHeader:
+		request.IgnoreHeaders = append(request.IgnoreHeaders, http.CanonicalHeaderKey(text))
 	default:
 		return fmt.Errorf("unhandled keyword: %s", kind)
 	}
@@ -549,9 +689,9 @@ func (r *Resolver) resolveRequestPromptStatement(
 ) error {
 	name := statement.Ident.Name
 
-	prompt := spec.Prompt{
-		Name:        name,
-		Description: statement.Description.Value,
+	prompt, err := r.resolvePrompt(statement)
+	if err != nil {
+		return err
 	}
 
 	if _, exists := request.Prompts[name]; exists {
@@ -565,8 +705,7 @@ func (r *Resolver) resolveRequestPromptStatement(
 	}
 
 	// We obviously don't know the value of the prompt yet, this comes later when the user actually
-	// runs the file and is prompted for the answer, so for now insert a unique placeholder for
-	// each prompt which we can easily swap out once prompts are resolved.
+	// runs the file and is prompted for the answer, so defer it until runtime.
 	//
 	// This means that something like:
 	// ###
@@ -574,7 +713,7 @@ func (r *Resolver) resolveRequestPromptStatement(
 	// GET https://someurl.com/users/{{ id }}
 	//
 	// Won't think 'id' is missing and fail because it's not defined yet.
-	if err := env.define(name, PromptPlaceholderLocal+name); err != nil {
+	if err := env.define(name, spec.Expr(name)); err != nil {
 		return r.errorf(statement.Ident, "prompt %s shadows local variable of the same name: %v", name, err)
 	}
 
@@ -583,11 +722,83 @@ func (r *Resolver) resolveRequestPromptStatement(
 	return nil
 }
 
+// resolveAssertStatement resolves a request level @assert statement, validating
+// the subject, operator and (where it is static) the expected value up front so
+// mistakes are reported as diagnostics rather than as test failures.
+func (r *Resolver) resolveAssertStatement(env *environment, statement ast.AssertStatement) (spec.Assertion, error) {
+	value, err := r.resolveExpression(env, statement.Value)
+	if err != nil {
+		return spec.Assertion{}, r.errorf(statement.Value, "invalid value expression for assertion: %v", err)
+	}
+
+	assertion := spec.Assertion{
+		Subject:  statement.Subject.Value,
+		Operator: statement.Operator,
+		Value:    trimSpace(value),
+		Position: r.position(statement),
+	}
+
+	switch subject := assertion.Subject; {
+	case subject == "status", subject == "body":
+		// Nothing to validate
+	case strings.HasPrefix(subject, "header."):
+		if strings.TrimPrefix(subject, "header.") == "" {
+			return spec.Assertion{}, r.error(statement.Subject, "header assertion missing header name e.g. header.Content-Type")
+		}
+	case strings.HasPrefix(subject, "$"):
+		if _, err := jsonpath.Parse(subject); err != nil {
+			return spec.Assertion{}, r.error(statement.Subject, err.Error())
+		}
+	default:
+		return spec.Assertion{}, r.errorf(
+			statement.Subject,
+			"invalid assertion subject %q, expected status, body, header.<name> or a JSONPath expression",
+			subject,
+		)
+	}
+
+	if assertion.Operator == "exists" {
+		if len(assertion.Value) != 0 {
+			return spec.Assertion{}, r.error(statement.Value, "operator exists does not take a value")
+		}
+
+		return assertion, nil
+	}
+
+	if len(assertion.Value) == 0 {
+		return spec.Assertion{}, r.errorf(statement, "operator %s requires a value", assertion.Operator)
+	}
+
+	// Interpolated values may not be known until the request is executed (e.g. prompts)
+	// so can only be checked now if they are plain text
+	if _, isText := statement.Value.(ast.TextLiteral); !isText {
+		return assertion, nil
+	}
+
+	switch value := assertion.Value.String(); assertion.Operator {
+	case "matches":
+		if _, err := regexp.Compile(value); err != nil {
+			return spec.Assertion{}, r.errorf(statement.Value, "invalid regular expression: %v", err)
+		}
+	case "<", "<=", ">", ">=":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return spec.Assertion{}, r.errorf(
+				statement.Value,
+				"operator %s requires a number, got %q",
+				assertion.Operator,
+				value,
+			)
+		}
+	}
+
+	return assertion, nil
+}
+
 // resolveHeader resolves a single [ast.Header].
-func (r *Resolver) resolveHeader(env *environment, in ast.Header) (key, value string, err error) {
+func (r *Resolver) resolveHeader(env *environment, in ast.Header) (key string, value spec.Template, err error) {
 	value, err = r.resolveExpression(env, in.Value)
 	if err != nil {
-		return "", "", r.errorf(in.Value, "invalid value expression for header %s: %v", in.Key, err)
+		return "", nil, r.errorf(in.Value, "invalid value expression for header %s: %v", in.Key, err)
 	}
 
 	return in.Key, value, nil
@@ -621,7 +832,19 @@ func (r *Resolver) resolveBody(env *environment, request *spec.Request, expressi
 			return err
 		}
 
-		request.BodyFile = filepath.Clean(value)
+		path := cleanPath(value)
+
+		if !expr.Interpolate {
+			request.BodyFile = path
+			return nil
+		}
+
+		body, err := r.resolveBodyTemplate(env, path.String())
+		if err != nil {
+			return r.errorf(expr, "could not interpolate body file %s: %v", path, err)
+		}
+
+		request.Body = body
 
 		return nil
 	default:
@@ -629,56 +852,242 @@ func (r *Resolver) resolveBody(env *environment, request *spec.Request, expressi
 	}
 }
 
+// resolveBodyTemplate reads the body file at path (relative to the file being resolved)
+// and resolves the '{{ }}' interpolations in its contents using env, as requested by '<@'.
+//
+// Diagnostics in the body file are reported against the body file itself.
+func (r *Resolver) resolveBodyTemplate(env *environment, path string) (spec.Template, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(r.name), path)
+	}
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser.NewTemplate(path, src)
+
+	template, err := p.ParseTemplate()
+	if err != nil {
+		r.diagnostics = append(r.diagnostics, p.Diagnostics()...)
+		return nil, err
+	}
+
+	// Not New, as that would resolve the local builtins e.g. '$file' relative to the body
+	// file, they're always relative to the .http file wherever they're used
+	sub := &Resolver{
+		name:     path,
+		src:      src,
+		library:  r.library,
+		requests: r.requests,
+	}
+
+	value, err := sub.resolveExpression(env, template)
+	r.diagnostics = append(r.diagnostics, sub.diagnostics...)
+
+	return value, err
+}
+
 // resolveInterpolatedExpression resolves an [ast.InterpolatedExpression] node into
 // it's concrete string.
-func (r *Resolver) resolveInterpolatedExpression(env *environment, expr ast.InterpolatedExpression) (string, error) {
+func (r *Resolver) resolveInterpolatedExpression(
+	env *environment,
+	expr ast.InterpolatedExpression,
+) (spec.Template, error) {
 	leftResolved, err := r.resolveExpression(env, expr.Left)
 	if err != nil {
-		return "", r.errorf(expr.Left, "could not resolve LHS of interpolated expression: %v", err)
+		return nil, r.errorf(expr.Left, "could not resolve LHS of interpolated expression: %v", err)
 	}
 
 	interpResolved, err := r.resolveExpression(env, expr.Interp)
 	if err != nil {
-		return "", r.errorf(expr.Interp, "could not resolve interp of interpolated expression: %v", err)
+		return nil, r.errorf(expr.Interp, "could not resolve interp of interpolated expression: %v", err)
 	}
 
 	rightResolved, err := r.resolveExpression(env, expr.Right)
 	if err != nil {
-		return "", r.errorf(expr.Right, "could not resolve RHS of interpolated expression: %v", err)
+		return nil, r.errorf(expr.Right, "could not resolve RHS of interpolated expression: %v", err)
 	}
 
-	return leftResolved + interpResolved + rightResolved, nil
+	return leftResolved.Concat(interpResolved).Concat(rightResolved), nil
 }
 
 // resolveSelectorExpression resolves an [ast.SelectorExpression].
-func (r *Resolver) resolveSelectorExpression(_ *environment, selector ast.SelectorExpression) (string, error) {
-	switch expr := selector.Expr.(type) {
+//
+// This is either a builtin with an argument e.g. '$env.HOME' or a reference to another
+// request e.g. 'login.response.body.$.token'.
+func (r *Resolver) resolveSelectorExpression(
+	env *environment,
+	selector ast.SelectorExpression,
+) (spec.Template, error) {
+	root, selectors := flattenSelector(selector)
+
+	switch expr := root.(type) {
 	case ast.Builtin:
-		return r.resolveBuiltin(expr, selector.Selector.Name)
+		return r.resolveBuiltinCall(env, selector)
+	case ast.Ident:
+		return r.resolveReference(expr, selectors)
 	default:
-		return "", fmt.Errorf("unsupported selector expression on %T", selector.Expr)
+		return nil, fmt.Errorf("unsupported selector expression on %T", root)
 	}
 }
 
+// reference returns what uses of the variable called key with the given value resolve to.
+//
+// That is normally the value itself, but a value using a dynamic builtin is deferred as a
+// reference to the variable so that every use of it in a request has the same value.
+func (r *Resolver) reference(key string, value spec.Template) spec.Template {
+	if dynamic(value) {
+		return spec.Expr(key)
+	}
+
+	return value
+}
+
+// evaluateNow evaluates any dynamic builtins in value immediately, for values that must
+// be known when the file is resolved e.g. the name of a request or a timeout.
+func (r *Resolver) evaluateNow(value spec.Template) (string, error) {
+	return Evaluate(value, Scope{Library: r.library, Partial: true})
+}
+
 // resolveIdent resolves an [ast.Ident] into the concrete value it refers to given
 // the environment.
-func (r *Resolver) resolveIdent(env *environment, ident ast.Ident) (string, error) {
+func (r *Resolver) resolveIdent(env *environment, ident ast.Ident) (spec.Template, error) {
 	if env == nil {
-		return "", errors.New("resolveIdent: env was nil")
+		return nil, errors.New("resolveIdent: env was nil")
 	}
 
 	return env.get(ident.Name)
 }
 
-// resolveBuiltin resolves an [ast.Builtin] into the concrete value it
-// refers to.
+// resolveBuiltinCall resolves a use of a builtin, directly e.g. '$uuid', with selectors e.g.
+// '$env.HOME', called e.g. '$random.alphanumeric(8)' or as a filter e.g. 'token | trim'.
+//
+// Dynamic builtins, and builtins given arguments only known at runtime, are deferred
+// until the request is executed. Dynamic builtins are still called here so problems with
+// their arguments are reported up front.
+func (r *Resolver) resolveBuiltinCall(env *environment, expression ast.Expression) (spec.Template, error) {
+	name, argExprs, ok := builtinCall(expression)
+	if !ok {
+		return nil, fmt.Errorf("only builtins may be called, not %s", expression.Kind())
+	}
+
+	source := spec.Expr(string(r.src[expression.Start().Start:expression.End().End]))
+
+	args := make([]string, 0, len(argExprs))
+
+	for _, arg := range argExprs {
+		value, err := r.resolveExpression(env, arg)
+		if err != nil {
+			return nil, err
+		}
+
+		if value.Deferred() {
+			return source, nil
+		}
+
+		args = append(args, value.String())
+	}
+
+	value, err := r.resolveBuiltin(name, args...)
+	if err != nil {
+		var argErr builtins.ArgumentError
+		if errors.As(err, &argErr) && argErr.Index >= 0 && argErr.Index < len(argExprs) {
+			return nil, r.error(argExprs[argErr.Index], err.Error())
+		}
+
+		return nil, err
+	}
+
+	if builtins.Dynamic(name) {
+		// Evaluated every time a request is executed, not once here
+		return source, nil
+	}
+
+	return spec.Text(value), nil
+}
+
+// resolveBinaryExpression resolves an [ast.BinaryExpression]: the concatenation of its
+// operands e.g. 'user + ":" + pass', a default e.g. 'region ?? "eu-west-1"' or a filter
+// e.g. 'token | trim'.
+//
+// Operands deferred until runtime are concatenated as they are, the result is then
+// itself deferred. A default whose left hand side is deferred is deferred as a whole.
+func (r *Resolver) resolveBinaryExpression(env *environment, expr ast.BinaryExpression) (spec.Template, error) {
+	switch expr.Op.Kind {
+	case token.Pipe:
+		return r.resolveBuiltinCall(env, expr)
+	case token.DoubleQuestion:
+		value, err := r.resolveExpression(env, expr.Left)
+		if errors.Is(err, builtins.ErrUndefined) {
+			// Only undefined values have a default, any other error is still an error
+			return r.resolveExpression(env, expr.Right)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if value.Deferred() {
+			return spec.Expr(string(r.src[expr.Start().Start:expr.End().End])), nil
+		}
+
+		return value, nil
+	default:
+		left, err := r.resolveExpression(env, expr.Left)
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := r.resolveExpression(env, expr.Right)
+		if err != nil {
+			return nil, err
+		}
+
+		return left.Concat(right), nil
+	}
+}
+
+// resolveBuiltin calls the builtin called name with args, returning the concrete
+// value it gives.
 //
 // Note: no environment here as builtins are well... built in.
-func (r *Resolver) resolveBuiltin(b ast.Builtin, args ...string) (string, error) {
-	fn, ok := r.library.Get(b.Name)
+func (r *Resolver) resolveBuiltin(name string, args ...string) (string, error) {
+	fn, ok := r.library.Get(name)
 	if !ok {
-		return "", fmt.Errorf("no such builtin: %q", b.Name)
+		return "", fmt.Errorf("no such builtin: %q", name)
 	}
 
 	return fn(args...)
 }
+
+// cleanPath returns path cleaned by [filepath.Clean], a path deferred until runtime is
+// left as it is.
+func cleanPath(path spec.Template) spec.Template {
+	if path.Deferred() {
+		return path
+	}
+
+	return spec.Text(filepath.Clean(path.String()))
+}
+
+// trimSpace returns value without leading or trailing whitespace, like [strings.TrimSpace].
+func trimSpace(value spec.Template) spec.Template {
+	if len(value) == 0 {
+		return value
+	}
+
+	value = slices.Clone(value)
+	first, last := &value[0], &value[len(value)-1]
+
+	if first.Expr == "" {
+		first.Text = strings.TrimLeftFunc(first.Text, unicode.IsSpace)
+	}
+
+	if last.Expr == "" {
+		last.Text = strings.TrimRightFunc(last.Text, unicode.IsSpace)
+	}
+
+	return spec.Template{}.Concat(value)
+}
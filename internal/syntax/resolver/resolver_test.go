@@ -4,9 +4,13 @@ import (
 	"bytes"
 	"encoding/json"
 	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
 
 	"go.followtheprocess.codes/test"
@@ -14,6 +18,7 @@ import (
 	"go.followtheprocess.codes/zap/internal/spec"
 	"go.followtheprocess.codes/zap/internal/syntax/parser"
 	"go.followtheprocess.codes/zap/internal/syntax/resolver"
+	"go.followtheprocess.codes/zap/internal/syntax/resolver/builtins"
 	"go.followtheprocess.codes/zap/internal/syntax/syntaxtest"
 	"go.uber.org/goleak"
 	"go.yaml.in/yaml/v4"
@@ -146,6 +151,294 @@ func TestInvalid(t *testing.T) {
 	}
 }
 
+func TestBodyFileTemplate(t *testing.T) {
+	tests := []struct {
+		name    string // Name of the test case
+		src     string // The .http file source
+		body    string // Contents of input.json, next to the .http file
+		want    string // Expected resolved request body
+		wantErr bool   // Whether we want a resolver error
+	}{
+		{
+			name: "static",
+			src:  "###\nPOST https://example.com\n\n<@ ./input.json\n",
+			body: `{"static": true}`,
+			want: `{"static": true}`,
+		},
+		{
+			name: "interpolated",
+			src:  "@id = 123\n\n###\nPOST https://example.com\n\n<@ ./input.json\n",
+			body: `{"id": {{ id }}, "env": "{{ $env.ZAP_TEST_VAR }}"}`,
+			want: `{"id": 123, "env": "test_env_value"}`,
+		},
+		{
+			name: "request scoped",
+			src:  "###\n# @id = 456\nPOST https://example.com\n\n<@ input.json\n",
+			body: `{"id": {{ id }}}`,
+			want: `{"id": 456}`,
+		},
+		{
+			name:    "undefined variable",
+			src:     "###\nPOST https://example.com\n\n<@ ./input.json\n",
+			body:    `{"id": {{ missing }}}`,
+			wantErr: true,
+		},
+		{
+			name:    "missing file",
+			src:     "###\nPOST https://example.com\n\n<@ ./nope.json\n",
+			body:    `{}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			name := filepath.Join(dir, "src.http")
+
+			test.Ok(t, os.WriteFile(filepath.Join(dir, "input.json"), []byte(tt.body), 0o644))
+
+			p := parser.New(name, []byte(tt.src))
+
+			parsed, err := p.Parse()
+			test.Ok(t, err, test.Context("unexpected parser error"))
+
+			res := resolver.New(name, []byte(tt.src), syntaxtest.NewTestLibrary(syntaxtest.Env()))
+
+			resolved, err := res.Resolve(parsed)
+			test.WantErr(t, err, tt.wantErr)
+
+			if tt.wantErr {
+				test.NotEqual(t, len(res.Diagnostics()), 0)
+				return
+			}
+
+			test.Equal(t, len(resolved.Requests), 1)
+			test.Equal(t, resolved.Requests[0].Body.String(), tt.want)
+			test.Equal(t, len(resolved.Requests[0].BodyFile), 0)
+		})
+	}
+}
+
+func TestBodyFileTemplateLocalFiles(t *testing.T) {
+	// '$file' and '$dotenv' in a body file in another directory still read
+	// files relative to the .http file, not the body file
+	dir := t.TempDir()
+	name := filepath.Join(dir, "src.http")
+	src := "###\nPOST https://example.com\n\n<@ ./bodies/input.json\n"
+
+	test.Ok(t, os.Mkdir(filepath.Join(dir, "bodies"), 0o755))
+	test.Ok(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("API_KEY=abc123\n"), 0o644))
+	test.Ok(t, os.WriteFile(filepath.Join(dir, "key.pem"), []byte("secret\n"), 0o644))
+	test.Ok(t, os.WriteFile(filepath.Join(dir, "bodies", ".env"), []byte("API_KEY=wrong\n"), 0o644))
+	test.Ok(t, os.WriteFile(filepath.Join(dir, "bodies", "key.pem"), []byte("wrong\n"), 0o644))
+
+	body := `{"key": "{{ $file("./key.pem") }}", "api": "{{ $dotenv API_KEY }}"}`
+	test.Ok(t, os.WriteFile(filepath.Join(dir, "bodies", "input.json"), []byte(body), 0o644))
+
+	p := parser.New(name, []byte(src))
+
+	parsed, err := p.Parse()
+	test.Ok(t, err, test.Context("unexpected parser error"))
+
+	res := resolver.New(name, []byte(src), syntaxtest.NewTestLibrary(syntaxtest.Env()))
+
+	resolved, err := res.Resolve(parsed)
+	test.Ok(t, err, test.Context("unexpected resolver error: %v", res.Diagnostics()))
+
+	test.Equal(t, len(resolved.Requests), 1)
+	test.Equal(t, resolved.Requests[0].Body.String(), `{"key": "secret", "api": "abc123"}`)
+}
+
+func TestLiteralInterpolations(t *testing.T) {
+	// Text that only looks like an interpolation, here read by '$file', is literal and
+	// never evaluated at runtime even alongside a deferred prompt
+	dir := t.TempDir()
+	name := filepath.Join(dir, "src.http")
+	src := "@prompt id\n\n###\nGET https://example.com/{{ id }}\nX-Literal: {{ $file(\"./literal.txt\") }}\n"
+	literal := "⟦{{ id }}⟧ {{ id }} ⟦ unmatched"
+
+	test.Ok(t, os.WriteFile(filepath.Join(dir, "literal.txt"), []byte(literal), 0o644))
+
+	p := parser.New(name, []byte(src))
+
+	parsed, err := p.Parse()
+	test.Ok(t, err, test.Context("unexpected parser error"))
+
+	res := resolver.New(name, []byte(src), syntaxtest.NewTestLibrary(syntaxtest.Env()))
+
+	resolved, err := res.Resolve(parsed)
+	test.Ok(t, err, test.Context("unexpected resolver error: %v", res.Diagnostics()))
+
+	test.Equal(t, len(resolved.Requests), 1)
+
+	request := resolved.Requests[0]
+	header := request.Headers.Get("X-Literal")
+
+	test.True(t, request.URL.Deferred())
+	test.False(t, header.Deferred())
+
+	got, err := resolver.Evaluate(header, resolver.Scope{Variables: map[string]string{"id": "1"}})
+	test.Ok(t, err)
+	test.Equal(t, got, literal)
+}
+
+func TestLocalFiles(t *testing.T) {
+	tests := []struct {
+		name string // Name of the test case
+		src  string // The .http file source, next to a .env and key.pem
+		want string // Expected resolved request URL
+		diag string // If we want a diagnostic, what the innermost one should contain
+	}{
+		{
+			name: "dotenv",
+			src:  "###\nGET https://example.com/{{ $dotenv API_KEY }}\n",
+			want: "https://example.com/abc123",
+		},
+		{
+			name: "file call",
+			src:  "###\nGET https://example.com/{{ $file(\"./key.pem\") }}\n",
+			want: "https://example.com/secret",
+		},
+		{
+			name: "file selector",
+			src:  "###\nGET https://example.com/{{ $file.key.pem }}\n",
+			want: "https://example.com/secret",
+		},
+		{
+			name: "default",
+			src:  "###\nGET https://example.com/{{ $dotenv MISSING ?? \"fallback\" }}\n",
+			want: "https://example.com/fallback",
+		},
+		{
+			name: "missing key",
+			src:  "###\nGET https://example.com/{{ $dotenv MISSING }}\n",
+			diag: "$dotenv MISSING: MISSING is not set in",
+		},
+		{
+			name: "missing file",
+			src:  "###\nGET https://example.com/{{ $file(\"./nope.pem\") }}\n",
+			diag: "nope.pem does not exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			name := filepath.Join(dir, "src.http")
+
+			test.Ok(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("API_KEY=abc123\n"), 0o644))
+			test.Ok(t, os.WriteFile(filepath.Join(dir, "key.pem"), []byte("secret\n"), 0o644))
+
+			p := parser.New(name, []byte(tt.src))
+
+			parsed, err := p.Parse()
+			test.Ok(t, err, test.Context("unexpected parser error"))
+
+			res := resolver.New(name, []byte(tt.src), syntaxtest.NewTestLibrary(syntaxtest.Env()))
+
+			resolved, err := res.Resolve(parsed)
+			test.WantErr(t, err, tt.diag != "")
+
+			if tt.diag != "" {
+				diagnostics := res.Diagnostics()
+				test.NotEqual(t, len(diagnostics), 0)
+
+				innermost := diagnostics[len(diagnostics)-1].Msg
+				test.True(t, strings.Contains(innermost, tt.diag), test.Context("diagnostic %q", innermost))
+
+				return
+			}
+
+			test.Equal(t, len(resolved.Requests), 1)
+			test.Equal(t, resolved.Requests[0].URL.String(), tt.want)
+		})
+	}
+}
+
+func TestResolveEnvironment(t *testing.T) {
+	src := `@version = v2
+@base = https://{{ host }}/{{ version }}
+
+###
+# @name = Get
+GET {{ base }}/items
+Authorization: Bearer {{ token }}
+`
+
+	environment := map[string]string{
+		"host":    "api.com",
+		"token":   "secret",
+		"version": "v1", // The global takes precedence
+	}
+
+	p := parser.New("env.http", []byte(src))
+
+	parsed, err := p.Parse()
+	test.Ok(t, err, test.Context("unexpected parser error"))
+
+	res := resolver.New(
+		"env.http",
+		[]byte(src),
+		syntaxtest.NewTestLibrary(syntaxtest.Env()),
+		resolver.WithEnvironment(environment),
+	)
+
+	resolved, err := res.Resolve(parsed)
+	test.Ok(t, err, test.Context("unexpected resolver error: %+v", res.Diagnostics()))
+
+	test.Equal(t, len(resolved.Requests), 1)
+	test.Equal(t, resolved.Requests[0].URL.String(), "https://api.com/v2/items")
+	test.Equal(t, resolved.Requests[0].Headers.Get("Authorization").String(), "Bearer secret")
+}
+
+func TestResolveOverrides(t *testing.T) {
+	src := `@host = {{ $env.NOT_SET }}
+@version = v1
+
+###
+# @name = Get
+# @id = 1
+GET https://{{ host }}/{{ version }}/items/{{ id }}
+X-Env: {{ env }}
+`
+
+	environment := map[string]string{
+		"env":  "dev",
+		"host": "env.com",
+	}
+
+	overrides := map[string]string{
+		"env":    "ci",
+		"host":   "override.com", // Overrides a global, so it's never resolved
+		"id":     "2",            // Request variables take precedence
+		"unused": "yes",
+	}
+
+	p := parser.New("overrides.http", []byte(src))
+
+	parsed, err := p.Parse()
+	test.Ok(t, err, test.Context("unexpected parser error"))
+
+	res := resolver.New(
+		"overrides.http",
+		[]byte(src),
+		syntaxtest.NewTestLibrary(syntaxtest.Env()),
+		resolver.WithEnvironment(environment),
+		resolver.WithOverrides(overrides),
+	)
+
+	resolved, err := res.Resolve(parsed)
+	test.Ok(t, err, test.Context("unexpected resolver error: %+v", res.Diagnostics()))
+
+	test.Equal(t, resolved.Vars["host"].String(), "override.com")
+	test.Equal(t, len(resolved.Requests), 1)
+	test.Equal(t, resolved.Requests[0].URL.String(), "https://override.com/v1/items/1")
+	test.Equal(t, resolved.Requests[0].Headers.Get("X-Env").String(), "ci")
+
+	test.EqualFunc(t, res.UnusedOverrides(), []string{"id", "unused"}, slices.Equal)
+}
+
 func BenchmarkResolver(b *testing.B) {
 	file := filepath.Join("testdata", "valid", "full.txtar")
 
@@ -209,3 +502,216 @@ func FuzzResolver(f *testing.F) {
 		}
 	})
 }
+
+func TestEvaluate(t *testing.T) {
+	login := func(reference resolver.Reference) (string, error) {
+		if reference.Request != "login" {
+			return "", fmt.Errorf("request %s has not been executed", reference.Request)
+		}
+
+		return "token-" + reference.Part, nil
+	}
+
+	tests := []struct {
+		scope    resolver.Scope // Scope to evaluate against
+		name     string         // Name of the test case
+		want     string         // Expected result
+		errMsg   string         // Expected error message, if any
+		template spec.Template  // Template to evaluate
+	}{
+		{
+			name:     "static",
+			template: spec.Text("https://example.com"),
+			want:     "https://example.com",
+		},
+		{
+			name: "variables",
+			template: spec.Template{
+				{Text: `{"user": "`},
+				{Expr: "user"},
+				{Text: `", "id": `},
+				{Expr: "id"},
+				{Text: "}"},
+			},
+			scope: resolver.Scope{Variables: map[string]string{"user": "zap", "id": "1"}},
+			want:  `{"user": "zap", "id": 1}`,
+		},
+		{
+			name:     "reference",
+			template: spec.Template{{Text: "Bearer "}, {Expr: "login.response.body.$.token"}},
+			scope:    resolver.Scope{Reference: login},
+			want:     "Bearer token-body",
+		},
+		{
+			name:     "builtin",
+			template: spec.Expr("$uuid"),
+			scope:    resolver.Scope{Library: syntaxtest.NewTestLibrary(nil)},
+			want:     syntaxtest.UUID,
+		},
+		{
+			name:     "builtin args",
+			template: spec.Expr(`$datetime "YYYY-MM-DD" 1 d`),
+			scope:    resolver.Scope{Library: syntaxtest.NewTestLibrary(nil)},
+			want:     "2024-03-01",
+		},
+		{
+			name:     "builtin call",
+			template: spec.Template{{Text: "Basic "}, {Expr: `$base64("id:" + id)`}},
+			scope: resolver.Scope{
+				Variables: map[string]string{"id": "123"},
+				Library:   syntaxtest.NewTestLibrary(nil),
+			},
+			want: "Basic aWQ6MTIz",
+		},
+		{
+			name:     "filters",
+			template: spec.Expr("id | trim | upper"),
+			scope: resolver.Scope{
+				Variables: map[string]string{"id": " abc "},
+				Library:   syntaxtest.NewTestLibrary(nil),
+			},
+			want: "ABC",
+		},
+		{
+			name:     "default",
+			template: spec.Template{{Expr: `region ?? "eu-west-1"`}, {Text: "/"}, {Expr: `id ?? "none"`}},
+			scope:    resolver.Scope{Variables: map[string]string{"id": "123"}},
+			want:     "eu-west-1/123",
+		},
+		{
+			name:     "builtin not allowed",
+			template: spec.Expr("$uuid"),
+			errMsg:   "cannot evaluate $uuid here",
+		},
+		{
+			name:     "templates",
+			template: spec.Template{{Expr: "id"}, {Text: "/"}, {Expr: "id"}, {Text: "/"}, {Expr: "$uuid"}},
+			scope: resolver.Scope{
+				Variables: map[string]string{},
+				Templates: map[string]spec.Template{"id": spec.Expr("$uuid")},
+				Library:   &counter{},
+			},
+			want: "1/1/2", // Each use of id has the same value
+		},
+		{
+			name:     "template refers to itself",
+			template: spec.Expr("id"),
+			scope: resolver.Scope{
+				Templates: map[string]spec.Template{"id": spec.Expr("id")},
+				Library:   syntaxtest.NewTestLibrary(nil),
+			},
+			errMsg: "id refers to itself",
+		},
+		{
+			name:     "missing variable",
+			template: spec.Expr("user"),
+			errMsg:   "user has no value",
+		},
+		{
+			name:     "reference not allowed",
+			template: spec.Expr("login.response.status"),
+			errMsg:   "cannot reference request login here",
+		},
+		{
+			name:     "reference error",
+			template: spec.Expr("other.response.status"),
+			scope:    resolver.Scope{Reference: login},
+			errMsg:   "request other has not been executed",
+		},
+		{
+			name:     "invalid",
+			template: spec.Expr("user +"),
+			errMsg:   `invalid interpolation "{{ user + }}": expected one of [Text Ident Dollar], got CloseInterp`,
+		},
+		{
+			name:     "literal",
+			template: spec.Text(`{"name": "{{ name }}"}`),
+			want:     `{"name": "{{ name }}"}`,
+		},
+		{
+			name:     "literal and deferred",
+			template: spec.Template{{Expr: "user"}, {Text: " says {{ hi }}"}},
+			scope:    resolver.Scope{Variables: map[string]string{"user": "{{ zap }}"}},
+			want:     "{{ zap }} says {{ hi }}",
+		},
+		{
+			name:     "literal template",
+			template: spec.Expr("greeting"),
+			scope: resolver.Scope{
+				Templates: map[string]spec.Template{"greeting": spec.Text("{{oops}}")},
+			},
+			want: "{{oops}}",
+		},
+		{
+			name:     "literal delimiters",
+			template: spec.Text("⟦{{ user }}⟧ and ⟦ unmatched"),
+			scope:    resolver.Scope{Variables: map[string]string{"user": "zap"}},
+			want:     "⟦{{ user }}⟧ and ⟦ unmatched",
+		},
+		{
+			name: "partial",
+			template: spec.Template{
+				{Expr: "host"},
+				{Text: "/"},
+				{Expr: "user"},
+				{Text: "?token="},
+				{Expr: "login.response.body.$.token"},
+			},
+			scope: resolver.Scope{Variables: map[string]string{"user": "zap"}, Partial: true},
+			want:  "{{ host }}/zap?token={{ login.response.body.$.token }}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolver.Evaluate(tt.template, tt.scope)
+			if tt.errMsg != "" {
+				test.Err(t, err)
+				test.Equal(t, err.Error(), tt.errMsg)
+
+				return
+			}
+
+			test.Ok(t, err)
+			test.Equal(t, got, tt.want)
+		})
+	}
+}
+
+func TestDeferred(t *testing.T) {
+	template := spec.Template{
+		{Expr: "base"},
+		{Text: "/"},
+		{Expr: "login.response.body.$.id"},
+		{Text: "?q="},
+		{Expr: "query"},
+		{Text: "&r="},
+		{Expr: "login.response.status"},
+		{Text: "&sig="},
+		{Expr: "$sha256(secret + signup.response.status)"},
+		{Text: "&literal={{ other }}"},
+	}
+
+	test.EqualFunc(t, resolver.Variables(template), []string{"base", "query", "secret"}, slices.Equal)
+	test.EqualFunc(t, resolver.References(template), []resolver.Reference{
+		{Request: "login", Source: "response", Part: "body", Selector: "$.id"},
+		{Request: "login", Source: "response", Part: "status"},
+		{Request: "signup", Source: "response", Part: "status"},
+	}, slices.Equal)
+}
+
+// counter is a [builtins.Library] whose '$uuid' counts the number of times it is called.
+type counter struct {
+	calls int
+}
+
+func (c *counter) Get(name string) (builtins.Builtin, bool) {
+	if name != "uuid" {
+		return nil, false
+	}
+
+	return func(...string) (string, error) {
+		c.calls++
+		return strconv.Itoa(c.calls), nil
+	}, true
+}
@@ -0,0 +1,412 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.followtheprocess.codes/zap/internal/spec"
+	"go.followtheprocess.codes/zap/internal/syntax/ast"
+	"go.followtheprocess.codes/zap/internal/syntax/parser"
+	"go.followtheprocess.codes/zap/internal/syntax/resolver/builtins"
+	"go.followtheprocess.codes/zap/internal/syntax/token"
+)
+
+// Scope is the runtime scope against which deferred interpolations are evaluated.
+type Scope struct {
+	// Variables are the values of the deferred variables by name, that is the
+	// answers to prompts and the values captured from responses.
+	//
+	// Templates are added to it as they are evaluated.
+	Variables map[string]string
+
+	// Templates are variables whose values are themselves deferred, e.g. those using a
+	// dynamic builtin. Each is evaluated when first used and its value reused from then
+	// on, so every use of the variable in the scope has the same value.
+	Templates map[string]spec.Template
+
+	// Library provides the dynamic builtins e.g. '$uuid', if nil none may be used.
+	Library builtins.Library
+
+	// Reference returns the value of a reference to another request or its response,
+	// if nil no requests may be referenced.
+	Reference func(reference Reference) (string, error)
+
+	// evaluating are the names of the templates currently being evaluated, to catch
+	// those that refer to themselves.
+	evaluating []string
+
+	// Partial leaves interpolations that cannot be evaluated as they are written rather
+	// than returning an error, e.g. when exporting a file without executing it.
+	Partial bool
+}
+
+// Evaluate evaluates the deferred interpolations in template against scope, returning
+// the resulting text.
+func Evaluate(template spec.Template, scope Scope) (string, error) {
+	builder := &strings.Builder{}
+
+	for _, segment := range template {
+		if segment.Expr == "" {
+			builder.WriteString(segment.Text)
+			continue
+		}
+
+		interp, expr, err := parseInterp(segment.Expr)
+		if err != nil {
+			return "", err
+		}
+
+		value, err := scope.evaluate(interp, expr)
+		if err != nil {
+			return "", err
+		}
+
+		builder.WriteString(value)
+	}
+
+	return builder.String(), nil
+}
+
+// Variables returns the names of the variables deferred in template, in the
+// order they appear.
+func Variables(template spec.Template) []string {
+	var names []string
+
+	walkDeferred(template, func(expr ast.Expression) {
+		if ident, ok := expr.(ast.Ident); ok {
+			names = append(names, ident.Name)
+		}
+	})
+
+	return names
+}
+
+// References returns the references to other requests deferred in template, in the
+// order they appear.
+func References(template spec.Template) []Reference {
+	var references []Reference
+
+	walkDeferred(template, func(expr ast.Expression) {
+		selector, ok := expr.(ast.SelectorExpression)
+		if !ok {
+			return
+		}
+
+		root, selectors := flattenSelector(selector)
+
+		ident, ok := root.(ast.Ident)
+		if !ok {
+			return
+		}
+
+		if reference, _, err := newReference(ident, selectors); err == nil {
+			references = append(references, reference)
+		}
+	})
+
+	return references
+}
+
+// dynamic reports whether template uses a dynamic builtin.
+func dynamic(template spec.Template) bool {
+	found := false
+
+	walkDeferred(template, func(expr ast.Expression) {
+		if _, _, ok := builtinCall(expr); ok {
+			found = true
+		}
+	})
+
+	return found
+}
+
+// evaluate evaluates a parsed template expression against the scope, template is
+// the source it was parsed from.
+func (s Scope) evaluate(template string, expression ast.Expression) (string, error) {
+	switch expr := expression.(type) {
+	case nil:
+		return "", nil
+	case ast.TextLiteral:
+		return expr.Value, nil
+	case ast.Body:
+		return expr.Value, nil
+	case ast.InterpolatedExpression:
+		left, err := s.evaluate(template, expr.Left)
+		if err != nil {
+			return "", err
+		}
+
+		interp, err := s.evaluate(template, expr.Interp)
+		if err != nil {
+			return "", err
+		}
+
+		right, err := s.evaluate(template, expr.Right)
+		if err != nil {
+			return "", err
+		}
+
+		return left + interp + right, nil
+	case ast.Interp:
+		value, err := s.evaluate(template, expr.Expr)
+		if err != nil && s.Partial {
+			return template[expr.Start().Start:expr.End().End], nil
+		}
+
+		return value, err
+	case ast.Ident:
+		if value, ok := s.Variables[expr.Name]; ok {
+			return value, nil
+		}
+
+		template, ok := s.Templates[expr.Name]
+		if !ok {
+			return "", builtins.Undefined("%s has no value", expr.Name)
+		}
+
+		if slices.Contains(s.evaluating, expr.Name) {
+			return "", fmt.Errorf("%s refers to itself", expr.Name)
+		}
+
+		inner := s
+		inner.evaluating = append(slices.Clone(s.evaluating), expr.Name)
+
+		value, err := Evaluate(template, inner)
+		if err != nil {
+			return "", err
+		}
+
+		if s.Variables != nil {
+			s.Variables[expr.Name] = value
+		}
+
+		return value, nil
+	case ast.Builtin, ast.CallExpression:
+		return s.evaluateBuiltin(template, expr)
+	case ast.BinaryExpression:
+		return s.evaluateBinary(template, expr)
+	case ast.SelectorExpression:
+		root, selectors := flattenSelector(expr)
+
+		if _, ok := root.(ast.Builtin); ok {
+			return s.evaluateBuiltin(template, expr)
+		}
+
+		ident, ok := root.(ast.Ident)
+		if !ok {
+			return "", fmt.Errorf("unsupported selector expression on %T", root)
+		}
+
+		reference, _, err := newReference(ident, selectors)
+		if err != nil {
+			return "", err
+		}
+
+		if s.Reference == nil {
+			return "", fmt.Errorf("cannot reference request %s here", reference.Request)
+		}
+
+		return s.Reference(reference)
+	default:
+		return "", fmt.Errorf("unhandled deferred expression: %T", expr)
+	}
+}
+
+// evaluateBinary evaluates a concatenation, default or filter.
+func (s Scope) evaluateBinary(template string, expr ast.BinaryExpression) (string, error) {
+	switch expr.Op.Kind {
+	case token.Pipe:
+		return s.evaluateBuiltin(template, expr)
+	case token.DoubleQuestion:
+		value, err := s.evaluate(template, expr.Left)
+		if errors.Is(err, builtins.ErrUndefined) {
+			return s.evaluate(template, expr.Right)
+		}
+
+		return value, err
+	default:
+		left, err := s.evaluate(template, expr.Left)
+		if err != nil {
+			return "", err
+		}
+
+		right, err := s.evaluate(template, expr.Right)
+		if err != nil {
+			return "", err
+		}
+
+		return left + right, nil
+	}
+}
+
+// evaluateBuiltin evaluates a use of a builtin, see [builtinCall].
+func (s Scope) evaluateBuiltin(template string, expression ast.Expression) (string, error) {
+	name, argExprs, ok := builtinCall(expression)
+	if !ok {
+		return "", fmt.Errorf("only builtins may be called, not %s", expression.Kind())
+	}
+
+	if s.Library == nil {
+		return "", fmt.Errorf("cannot evaluate $%s here", name)
+	}
+
+	fn, ok := s.Library.Get(name)
+	if !ok {
+		return "", fmt.Errorf("no such builtin: %q", name)
+	}
+
+	args := make([]string, 0, len(argExprs))
+
+	for _, arg := range argExprs {
+		value, err := s.evaluate(template, arg)
+		if err != nil {
+			return "", err
+		}
+
+		args = append(args, value)
+	}
+
+	return fn(args...)
+}
+
+// builtinCall returns the name of the builtin used by expression, directly e.g. '$uuid', with
+// selectors e.g. '$random.name.firstName' or called e.g. '$random.alphanumeric(8)', along with
+// the arguments it is given in that order: any space separated arguments, the names of the
+// selectors and the call arguments.
+//
+// A filter e.g. 'token | trim' or 'body | hmacSHA256(secret)' is a use of the builtin it
+// names, given the value being filtered followed by any call arguments.
+//
+// ok is false if expression does not use a builtin.
+func builtinCall(expression ast.Expression) (name string, args []ast.Expression, ok bool) {
+	if filter, isFilter := expression.(ast.BinaryExpression); isFilter && filter.Op.Is(token.Pipe) {
+		args = []ast.Expression{filter.Left}
+
+		fn := filter.Right
+		if call, isCall := fn.(ast.CallExpression); isCall {
+			fn = call.Func
+			args = append(args, call.Args...)
+		}
+
+		ident, isIdent := fn.(ast.Ident)
+		if !isIdent {
+			return "", nil, false
+		}
+
+		return ident.Name, args, true
+	}
+
+	var callArgs []ast.Expression
+
+	if call, isCall := expression.(ast.CallExpression); isCall {
+		expression, callArgs = call.Func, call.Args
+	}
+
+	var selectors []ast.Expression
+
+	if selector, isSelector := expression.(ast.SelectorExpression); isSelector {
+		expression, selectors = flattenSelector(selector)
+	}
+
+	builtin, ok := expression.(ast.Builtin)
+	if !ok {
+		return "", nil, false
+	}
+
+	for _, arg := range builtin.Args {
+		args = append(args, arg)
+	}
+
+	// Selectors are literal names e.g. 'VAR' in '$env.VAR', not variables
+	for _, selector := range selectors {
+		name := ast.TextLiteral{Token: selector.Start(), Type: ast.KindTextLiteral}
+
+		switch selector := selector.(type) {
+		case ast.Ident:
+			name.Value = selector.Name
+		case ast.Query:
+			name.Value = selector.Value
+		}
+
+		args = append(args, name)
+	}
+
+	return builtin.Name, append(args, callArgs...), true
+}
+
+// walkDeferred calls fn with the expression inside every interpolation in template, and
+// with every operand and argument to a builtin call within it.
+//
+// Invalid templates have no interpolations.
+func walkDeferred(template spec.Template, fn func(expr ast.Expression)) {
+	var visit, walk func(expr ast.Expression)
+
+	// visit calls fn with an expression inside an interpolation and its operands and arguments
+	visit = func(expr ast.Expression) {
+		switch expr := expr.(type) {
+		case ast.BinaryExpression:
+			visit(expr.Left)
+
+			if !expr.Op.Is(token.Pipe) {
+				visit(expr.Right)
+			} else if call, ok := expr.Right.(ast.CallExpression); ok {
+				// The filter is named by an ident, not a variable, but its arguments may use them
+				for _, arg := range call.Args {
+					visit(arg)
+				}
+			}
+		case ast.CallExpression:
+			fn(expr)
+
+			for _, arg := range expr.Args {
+				visit(arg)
+			}
+		default:
+			fn(expr)
+		}
+	}
+
+	walk = func(expr ast.Expression) {
+		switch expr := expr.(type) {
+		case ast.InterpolatedExpression:
+			walk(expr.Left)
+			walk(expr.Interp)
+			walk(expr.Right)
+		case ast.Interp:
+			visit(expr.Expr)
+		}
+	}
+
+	for _, segment := range template {
+		if segment.Expr == "" {
+			continue
+		}
+
+		_, expr, err := parseInterp(segment.Expr)
+		if err != nil {
+			continue
+		}
+
+		walk(expr)
+	}
+}
+
+// parseInterp parses the interpolation of a deferred expression, returning its source
+// e.g. '{{ token }}' along with the parsed interpolation.
+func parseInterp(expression string) (string, ast.Expression, error) {
+	interp := "{{ " + expression + " }}"
+	p := parser.NewTemplate("template", []byte(interp))
+
+	expr, err := p.ParseTemplate()
+	if err != nil {
+		if diagnostics := p.Diagnostics(); len(diagnostics) != 0 {
+			return "", nil, fmt.Errorf("invalid interpolation %q: %s", interp, diagnostics[0].Msg)
+		}
+
+		return "", nil, fmt.Errorf("invalid interpolation %q: %w", interp, err)
+	}
+
+	return interp, expr, nil
+}
@@ -65,6 +65,20 @@ type Scanner struct {
 
 // New returns a new [Scanner].
 func New(name string, src []byte) *Scanner {
+	return newScanner(name, src, scanStart)
+}
+
+// NewTemplate returns a new [Scanner] that scans src as a template: free text
+// containing '{{ }}' interpolations, such as the contents of a body file
+// included with '<@'.
+//
+// The text either side of the interpolations is emitted as [token.Body].
+func NewTemplate(name string, src []byte) *Scanner {
+	return newScanner(name, src, scanTemplate)
+}
+
+// newScanner returns a new [Scanner] that begins scanning in the start state.
+func newScanner(name string, src []byte, start stateFn) *Scanner {
 	s := &Scanner{
 		tokens: make(chan token.Token, bufferSize),
 		stack:  make([]stateFn, 0, stackSize),
@@ -75,7 +89,7 @@ func New(name string, src []byte) *Scanner {
 
 	// run terminates when the scanning state machine is finished and all the
 	// tokens are drained from s.tokens, so no other synchronisation needed here
-	go s.run()
+	go s.run(start)
 
 	return s
 }
@@ -324,8 +338,8 @@ func (s *Scanner) errorf(format string, a ...any) stateFn {
 // run starts the state machine for the scanner, it runs with each [scanFn] returning the next
 // state until one returns nil (typically in response to an error or eof), at which point the tokens channel
 // is closed as a signal to the receiver that no more tokens will be sent.
-func (s *Scanner) run() {
-	for state := scanStart; state != nil; {
+func (s *Scanner) run(start stateFn) {
+	for state := start; state != nil; {
 		state = state(s)
 	}
 
@@ -449,7 +463,7 @@ func scanGlobalVariable(s *Scanner) stateFn {
 
 	s.skip(isLineSpace)
 
-	if kind == token.Prompt {
+	if kind == token.Prompt || kind == token.PromptSecret {
 		return scanPrompt
 	}
 
@@ -488,7 +502,15 @@ func scanInsideInterp(s *Scanner) stateFn {
 			return scanCloseInterp
 		}
 
-		switch next := s.peek(); next {
+		next := s.peek()
+
+		// A '$', '/' or '*' straight after a '.' is a JSONPath or XPath query
+		// selecting from a request or response body e.g. '{{ login.response.body.$.token }}'
+		if s.pos > 0 && s.src[s.pos-1] == '.' && strings.ContainsRune("$/*", next) {
+			return scanQuery
+		}
+
+		switch next {
 		case eof, '\n':
 			return s.error("unterminated interpolation")
 		case '$':
@@ -497,6 +519,61 @@ func scanInsideInterp(s *Scanner) stateFn {
 		case '.':
 			s.next()
 			s.emit(token.Dot)
+		case '(':
+			s.next()
+			s.emit(token.LeftParen)
+		case ')':
+			s.next()
+			s.emit(token.RightParen)
+		case ',':
+			s.next()
+			s.emit(token.Comma)
+		case '+':
+			s.next()
+			s.emit(token.Plus)
+		case '|':
+			// A filter e.g. '{{ token | trim }}'
+			s.next()
+			s.emit(token.Pipe)
+		case '?':
+			// A default e.g. '{{ region ?? "eu-west-1" }}'
+			s.next()
+
+			if !s.take("?") {
+				return s.errorf("unexpected character in interpolation: %q, expected '??'", s.peek())
+			}
+
+			s.emit(token.DoubleQuestion)
+		case '"':
+			// A quoted argument to a builtin e.g. '{{ $datetime "YYYY-MM-DD" }}', which
+			// may contain escaped quotes e.g. '"{\"sub\": 1}"'
+			s.next()
+			s.takeUntil('"', '\\', '\n', eof)
+
+			for s.take(`\`) {
+				if next := s.peek(); next == '\n' || next == eof {
+					break
+				}
+
+				s.next()
+				s.takeUntil('"', '\\', '\n', eof)
+			}
+
+			if !s.take(`"`) {
+				return s.error("unterminated quoted argument in interpolation")
+			}
+
+			s.emit(token.Text)
+		case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+			// A numeric argument to a builtin e.g. '{{ $timestamp -1 d }}'
+			s.next()
+			s.takeWhile(isDigit)
+
+			if s.take(".") {
+				s.takeWhile(isDigit)
+			}
+
+			s.emit(token.Text)
 		default:
 			if !isAlpha(next) {
 				return s.errorf("unexpected character in interpolation: %q", next)
@@ -508,6 +585,25 @@ func scanInsideInterp(s *Scanner) stateFn {
 	}
 }
 
+// scanQuery scans a JSONPath or XPath query inside an interpolation, the query
+// continues up to the closing '}}' and may contain any characters but a newline.
+func scanQuery(s *Scanner) stateFn {
+	for !s.restHasPrefix("}}") {
+		if next := s.peek(); next == eof || next == '\n' {
+			return s.error("unterminated interpolation")
+		}
+
+		s.next()
+	}
+
+	// Trailing whitespace before the '}}' isn't part of the query
+	query := s.src[s.start:s.pos]
+	s.pos = s.start + len(bytes.TrimRight(query, " \t"))
+	s.emit(token.Query)
+
+	return scanInsideInterp
+}
+
 // scanCloseInterp scans a closing '}}' marking the end of an interpolation.
 func scanCloseInterp(s *Scanner) stateFn {
 	s.takeExact("}}")
@@ -517,17 +613,89 @@ func scanCloseInterp(s *Scanner) stateFn {
 	return s.statePop()
 }
 
-// scanPrompt scans a prompt statement.
+// scanPrompt scans a prompt statement, in its fullest form:
+//
+//	@prompt name:type [choice|choice] = default description
 //
-// It assumes the '@prompt' has already been consumed.
+// Everything after the name is optional. It assumes the '@prompt' (or '@prompt-secret')
+// has already been consumed.
 func scanPrompt(s *Scanner) stateFn {
 	if isIdent(s.peek()) {
 		s.takeWhile(isIdent)
 		s.emit(token.Ident)
 	}
 
+	// The type of the value e.g. 'id:int'
+	if s.take(":") {
+		s.emit(token.Colon)
+
+		s.takeWhile(isIdent)
+
+		if s.pos == s.start {
+			return s.errorf("expected prompt type after ':', got %q", s.peek())
+		}
+
+		s.emit(token.Ident)
+	}
+
 	s.skip(isLineSpace)
 
+	// The allowed values e.g. '[dev|staging|prod]'
+	if s.take("[") {
+		s.emit(token.LeftBracket)
+
+		for {
+			s.skip(isLineSpace)
+			s.takeWhile(isChoice)
+
+			if s.pos == s.start {
+				return s.errorf("expected prompt choice, got %q", s.peek())
+			}
+
+			// Trailing space isn't part of the choice
+			s.pos = s.start + len(bytes.TrimRight(s.src[s.start:s.pos], " \t"))
+			s.emit(token.Text)
+			s.skip(isLineSpace)
+
+			if s.take("|") {
+				s.emit(token.Pipe)
+				continue
+			}
+
+			if s.take("]") {
+				s.emit(token.RightBracket)
+				break
+			}
+
+			return s.errorf("unterminated prompt choices, expected '|' or ']', got %q", s.peek())
+		}
+
+		s.skip(isLineSpace)
+	}
+
+	// The default value, either a single word or a quoted string e.g. '= 10' or '= "a b"'
+	if s.take("=") {
+		s.emit(token.Eq)
+		s.skip(isLineSpace)
+
+		if s.take(`"`) {
+			s.takeUntil('"', '\n', eof)
+
+			if !s.take(`"`) {
+				return s.error("unterminated quoted prompt default")
+			}
+		} else {
+			s.takeWhile(isText)
+		}
+
+		if s.pos == s.start {
+			return s.errorf("expected prompt default value, got %q", s.peek())
+		}
+
+		s.emit(token.Text)
+		s.skip(isLineSpace)
+	}
+
 	if isAlpha(s.peek()) {
 		s.takeUntil('\n', eof)
 		s.emit(token.Text)
@@ -584,6 +752,37 @@ func scanText(s *Scanner) stateFn {
 	return s.statePop()
 }
 
+// scanTemplate scans the entire input as a template, text with optional
+// interpolations, ending at eof.
+func scanTemplate(s *Scanner) stateFn {
+	for {
+		if s.restHasPrefix("{{") {
+			if s.pos > s.start {
+				s.emit(token.Body)
+			}
+
+			s.statePush(scanTemplate)
+
+			return scanOpenInterp
+		}
+
+		next := s.peek()
+		if next == eof || next == utf8.RuneError {
+			break
+		}
+
+		s.next()
+	}
+
+	if s.pos > s.start {
+		s.emit(token.Body)
+	}
+
+	s.emit(token.EOF)
+
+	return nil
+}
+
 // scanRequest scans inside a HTTP request definition.
 //
 // The opening '###' and any request comment has already been consumed.
@@ -665,10 +864,18 @@ func scanRequestVariable(s *Scanner) stateFn {
 
 	s.skip(isLineSpace)
 
-	if kind == token.Prompt {
+	if kind == token.Prompt || kind == token.PromptSecret {
 		return scanPrompt
 	}
 
+	if kind == token.Assert {
+		return scanAssert
+	}
+
+	if kind == token.Capture {
+		return scanCapture
+	}
+
 	if s.take("=") {
 		s.emit(token.Eq)
 		s.skip(isLineSpace)
@@ -686,6 +893,102 @@ func scanRequestVariable(s *Scanner) stateFn {
 	return s.statePop()
 }
 
+// scanAssert scans a response assertion e.g. '# @assert status == 200'.
+//
+// It assumes the '@assert' has already been consumed.
+func scanAssert(s *Scanner) stateFn {
+	// The subject of the assertion e.g. 'status', 'header.Content-Type' or '$.items[0].id'
+	s.takeWhile(isText)
+
+	if s.pos == s.start {
+		return s.errorf("expected assertion subject, got %q", s.peek())
+	}
+
+	s.emit(token.Text)
+	s.skip(isLineSpace)
+
+	// The operator is either symbolic e.g. '==' or a word e.g. 'contains'
+	if isAlpha(s.peek()) {
+		s.takeWhile(isAlpha)
+	} else {
+		s.takeWhile(isOperatorSymbol)
+	}
+
+	if s.pos == s.start {
+		return s.errorf("expected assertion operator, got %q", s.peek())
+	}
+
+	if operator := string(s.src[s.start:s.pos]); !isOperator(operator) {
+		return s.errorf("invalid assertion operator %q", operator)
+	}
+
+	s.emit(token.Operator)
+	s.skip(isLineSpace)
+
+	return scanAssertValue
+}
+
+// scanCapture scans a response capture e.g. '# @capture token = $.body.access_token'.
+//
+// It assumes the '@capture' has already been consumed.
+func scanCapture(s *Scanner) stateFn {
+	// The name of the variable to capture into
+	if !isAlpha(s.peek()) {
+		return s.errorf("expected capture variable name, got %q", s.peek())
+	}
+
+	s.takeWhile(isIdent)
+	s.emit(token.Ident)
+	s.skip(isLineSpace)
+
+	if !s.take("=") {
+		return s.errorf("expected '=' after capture variable name, got %q", s.peek())
+	}
+
+	s.emit(token.Eq)
+	s.skip(isLineSpace)
+
+	// The source of the value e.g. 'status', 'header.ETag' or '$.body.access_token'
+	s.takeUntil('\n', eof)
+
+	if s.pos == s.start {
+		return s.errorf("expected capture source, got %q", s.peek())
+	}
+
+	s.emit(token.Text)
+
+	return s.statePop()
+}
+
+// scanAssertValue scans the (optional) expected value in a response assertion,
+// including any interpolation.
+func scanAssertValue(s *Scanner) stateFn {
+	for {
+		if s.restHasPrefix("{{") {
+			if s.pos > s.start {
+				s.emit(token.Text)
+			}
+
+			s.statePush(scanAssertValue)
+
+			return scanOpenInterp
+		}
+
+		next := s.peek()
+		if next == '\n' || next == eof || next == utf8.RuneError {
+			break
+		}
+
+		s.next()
+	}
+
+	if s.pos > s.start {
+		s.emit(token.Text)
+	}
+
+	return s.statePop()
+}
+
 // scanMethod scans a HTTP method.
 func scanMethod(s *Scanner) stateFn {
 	s.takeWhile(isUpperAlpha)
@@ -895,17 +1198,22 @@ func scanBody(s *Scanner) stateFn {
 }
 
 // scanLeftAngle scans a '<' in the context of reading a request
-// body from the filepath specified next.
+// body from the filepath specified next, or a '<@' where the contents of
+// the file are to be interpolated.
 //
 // It assumes the '<' has already been consumed.
 func scanLeftAngle(s *Scanner) stateFn {
-	if !s.take(">") {
-		if s.pos > s.start {
-			s.emit(token.LeftAngle)
-		}
-	} else {
+	switch {
+	case s.take(">"):
 		// It's a response reference '<>'
 		s.emit(token.ResponseRef)
+	case s.pos > s.start:
+		if s.take("@") {
+			// It's a body file with interpolation '<@'
+			s.emit(token.LeftAngleAt)
+		} else {
+			s.emit(token.LeftAngle)
+		}
 	}
 
 	s.skip(isLineSpace)
@@ -996,6 +1304,26 @@ func isIdent(r rune) bool {
 	return isAlphaNumeric(r) || r == '_' || r == '-'
 }
 
+// isChoice reports whether r is valid in a prompt choice.
+func isChoice(r rune) bool {
+	return r != '|' && r != ']' && r != '\n' && r != eof && r != utf8.RuneError
+}
+
+// isOperatorSymbol reports whether r may be part of a symbolic assertion operator.
+func isOperatorSymbol(r rune) bool {
+	return r == '=' || r == '!' || r == '<' || r == '>'
+}
+
+// isOperator reports whether text is a valid assertion operator.
+func isOperator(text string) bool {
+	switch text {
+	case "==", "!=", "<", "<=", ">", ">=", "contains", "matches", "exists":
+		return true
+	default:
+		return false
+	}
+}
+
 // isText reports whether r is valid in a continuous string of text.
 //
 // The only things that are rejected by this are:
@@ -142,6 +142,61 @@ func TestInvalid(t *testing.T) {
 	}
 }
 
+func TestTemplate(t *testing.T) {
+	// Force colour for diffs but only locally
+	test.ColorEnabled(os.Getenv("CI") == "")
+
+	dir := filepath.Join("testdata", "template")
+
+	for file, err := range syntaxtest.AllFilesWithExtension(dir, ".txtar") {
+		test.Ok(t, err)
+
+		name, err := filepath.Rel(dir, file)
+		test.Ok(t, err)
+
+		name = filepath.ToSlash(name)
+
+		t.Run(name, func(t *testing.T) {
+			defer goleak.VerifyNone(t)
+
+			archive, err := txtar.ParseFile(file)
+			test.Ok(t, err)
+
+			src, ok := archive.Read("src.txt")
+			test.True(t, ok, test.Context("%s missing src.txt", file))
+
+			want, ok := archive.Read("tokens.txt")
+			test.True(t, ok, test.Context("%s missing tokens.txt", file))
+
+			scanner := scanner.NewTemplate(name, []byte(src))
+
+			tokens := collect(scanner)
+
+			var formattedTokens strings.Builder
+			for _, tok := range tokens {
+				formattedTokens.WriteString(tok.String())
+				formattedTokens.WriteByte('\n')
+			}
+
+			got := formattedTokens.String()
+
+			test.Equal(t, len(scanner.Diagnostics()), 0)
+
+			if *update {
+				err := archive.Write("tokens.txt", got)
+				test.Ok(t, err)
+
+				err = txtar.DumpFile(file, archive)
+				test.Ok(t, err)
+
+				return
+			}
+
+			test.Diff(t, got, want)
+		})
+	}
+}
+
 func FuzzScanner(f *testing.F) {
 	for file, err := range syntaxtest.AllFilesWithExtension("testdata", ".txtar") {
 		test.Ok(f, err)
@@ -154,7 +209,12 @@ func FuzzScanner(f *testing.F) {
 		}
 
 		src, ok := archive.Read("src.http")
-		test.True(f, ok, test.Context("%s missing src.http", file))
+		if !ok {
+			// Templates are scanned differently but still make good seeds
+			src, ok = archive.Read("src.txt")
+		}
+
+		test.True(f, ok, test.Context("%s missing src.http or src.txt", file))
 
 		f.Add(src)
 	}
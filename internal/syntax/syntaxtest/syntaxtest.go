@@ -7,6 +7,7 @@ import (
 	"io/fs"
 	"iter"
 	"path/filepath"
+	"time"
 
 	"go.followtheprocess.codes/zap/internal/syntax/resolver/builtins"
 )
@@ -15,8 +16,18 @@ import (
 const (
 	// UUID is the value returned from the '$uuid' test builtin.
 	UUID = "d0a43b68-b9a1-4e89-bd21-b06fc59fefb5"
+
+	// Seed is the seed of the random test builtins e.g. '$randomInt', so every
+	// new test library generates the same sequence of values.
+	Seed = 42
 )
 
+// Now returns the fixed time used by the test date and time builtins: Thursday 29th
+// February 2024 at 13:04:05 local time, in a fixed UTC+1 timezone.
+func Now() time.Time {
+	return time.Date(2024, time.February, 29, 13, 4, 5, 0, time.FixedZone("", int(time.Hour.Seconds())))
+}
+
 // TestBuiltins is a [builtins.Library] containing deterministic mock implementations
 // of the zap builtins.
 type TestBuiltins struct {
@@ -37,13 +48,26 @@ func NewTestLibrary(env map[string]string) TestBuiltins {
 
 			val, ok := env[args[0]]
 			if !ok {
-				return "", fmt.Errorf("$env.%s: environment variable %s is not set", args[0], args[0])
+				return "", builtins.Undefined("$env.%s: environment variable %s is not set", args[0], args[0])
 			}
 
 			return val, nil
 		},
 	}
 
+	// The rest are the real ones, using the fixed clock and seed where they need them
+	fixed, err := builtins.NewLibrary(builtins.WithClock(Now), builtins.WithSeed(Seed))
+	if err != nil {
+		panic(fmt.Sprintf("could not create builtins library: %v", err))
+	}
+
+	for _, name := range []string{
+		"timestamp", "isoTimestamp", "datetime", "localDatetime", "randomInt", "random",
+		"base64", "urlencode", "sha256", "hmacSHA256", "jwt", "trim", "upper", "lower",
+	} {
+		library[name], _ = fixed.Get(name)
+	}
+
 	return TestBuiltins{library: library}
 }
 
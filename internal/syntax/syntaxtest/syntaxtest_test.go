@@ -49,6 +49,29 @@ func TestTestLibrary(t *testing.T) {
 				"VAR": "A value here",
 			},
 		},
+		{
+			name:    "fixed timestamp",
+			fn:      "timestamp",
+			want:    "1709208245",
+			ok:      true,
+			wantErr: false,
+		},
+		{
+			name:    "fixed datetime",
+			fn:      "localDatetime",
+			args:    []string{"YYYY-MM-DD HH:mm", "1", "d"},
+			want:    "2024-03-01 13:04",
+			ok:      true,
+			wantErr: false,
+		},
+		{
+			name:    "seeded random",
+			fn:      "randomInt",
+			args:    []string{"1", "10"},
+			want:    "6",
+			ok:      true,
+			wantErr: false,
+		},
 		{
 			name:    "missing env",
 			fn:      "env",
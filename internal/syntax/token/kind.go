@@ -14,10 +14,20 @@ const (
 	At                            // At
 	Ident                         // Ident
 	Dot                           // Dot
+	Query                         // Query
 	Eq                            // Eq
 	Dollar                        // Dollar
 	Colon                         // Colon
+	LeftBracket                   // LeftBracket
+	RightBracket                  // RightBracket
+	Pipe                          // Pipe
+	LeftParen                     // LeftParen
+	RightParen                    // RightParen
+	Comma                         // Comma
+	Plus                          // Plus
+	DoubleQuestion                // DoubleQuestion
 	LeftAngle                     // LeftAngle
+	LeftAngleAt                   // LeftAngleAt
 	RightAngle                    // RightAngle
 	ResponseRef                   // ResponseRef
 	Text                          // Text
@@ -26,11 +36,17 @@ const (
 	Header                        // Header
 	OpenInterp                    // OpenInterp
 	CloseInterp                   // CloseInterp
+	Operator                      // Operator
 	Name                          // Name
 	Prompt                        // Prompt
+	PromptSecret                  // PromptSecret
 	Timeout                       // Timeout
 	ConnectionTimeout             // ConnectionTimeout
 	NoRedirect                    // NoRedirect
+	Assert                        // Assert
+	Ignore                        // Ignore
+	IgnoreHeader                  // IgnoreHeader
+	Capture                       // Capture
 	MethodGet                     // MethodGet
 	MethodHead                    // MethodHead
 	MethodPost                    // MethodPost
@@ -15,37 +15,53 @@ func _() {
 	_ = x[At-4]
 	_ = x[Ident-5]
 	_ = x[Dot-6]
-	_ = x[Eq-7]
-	_ = x[Dollar-8]
-	_ = x[Colon-9]
-	_ = x[LeftAngle-10]
-	_ = x[RightAngle-11]
-	_ = x[ResponseRef-12]
-	_ = x[Text-13]
-	_ = x[Body-14]
-	_ = x[HTTPVersion-15]
-	_ = x[Header-16]
-	_ = x[OpenInterp-17]
-	_ = x[CloseInterp-18]
-	_ = x[Name-19]
-	_ = x[Prompt-20]
-	_ = x[Timeout-21]
-	_ = x[ConnectionTimeout-22]
-	_ = x[NoRedirect-23]
-	_ = x[MethodGet-24]
-	_ = x[MethodHead-25]
-	_ = x[MethodPost-26]
-	_ = x[MethodPut-27]
-	_ = x[MethodDelete-28]
-	_ = x[MethodConnect-29]
-	_ = x[MethodPatch-30]
-	_ = x[MethodOptions-31]
-	_ = x[MethodTrace-32]
+	_ = x[Query-7]
+	_ = x[Eq-8]
+	_ = x[Dollar-9]
+	_ = x[Colon-10]
+	_ = x[LeftBracket-11]
+	_ = x[RightBracket-12]
+	_ = x[Pipe-13]
+	_ = x[LeftParen-14]
+	_ = x[RightParen-15]
+	_ = x[Comma-16]
+	_ = x[Plus-17]
+	_ = x[DoubleQuestion-18]
+	_ = x[LeftAngle-19]
+	_ = x[LeftAngleAt-20]
+	_ = x[RightAngle-21]
+	_ = x[ResponseRef-22]
+	_ = x[Text-23]
+	_ = x[Body-24]
+	_ = x[HTTPVersion-25]
+	_ = x[Header-26]
+	_ = x[OpenInterp-27]
+	_ = x[CloseInterp-28]
+	_ = x[Operator-29]
+	_ = x[Name-30]
+	_ = x[Prompt-31]
+	_ = x[PromptSecret-32]
+	_ = x[Timeout-33]
+	_ = x[ConnectionTimeout-34]
+	_ = x[NoRedirect-35]
+	_ = x[Assert-36]
+	_ = x[Ignore-37]
+	_ = x[IgnoreHeader-38]
+	_ = x[Capture-39]
+	_ = x[MethodGet-40]
+	_ = x[MethodHead-41]
+	_ = x[MethodPost-42]
+	_ = x[MethodPut-43]
+	_ = x[MethodDelete-44]
+	_ = x[MethodConnect-45]
+	_ = x[MethodPatch-46]
+	_ = x[MethodOptions-47]
+	_ = x[MethodTrace-48]
 }
 
-const _Kind_name = "EOFErrorCommentSeparatorAtIdentDotEqDollarColonLeftAngleRightAngleResponseRefTextBodyHTTPVersionHeaderOpenInterpCloseInterpNamePromptTimeoutConnectionTimeoutNoRedirectMethodGetMethodHeadMethodPostMethodPutMethodDeleteMethodConnectMethodPatchMethodOptionsMethodTrace"
+const _Kind_name = "EOFErrorCommentSeparatorAtIdentDotQueryEqDollarColonLeftBracketRightBracketPipeLeftParenRightParenCommaPlusDoubleQuestionLeftAngleLeftAngleAtRightAngleResponseRefTextBodyHTTPVersionHeaderOpenInterpCloseInterpOperatorNamePromptPromptSecretTimeoutConnectionTimeoutNoRedirectAssertIgnoreIgnoreHeaderCaptureMethodGetMethodHeadMethodPostMethodPutMethodDeleteMethodConnectMethodPatchMethodOptionsMethodTrace"
 
-var _Kind_index = [...]uint16{0, 3, 8, 15, 24, 26, 31, 34, 36, 42, 47, 56, 66, 77, 81, 85, 96, 102, 112, 123, 127, 133, 140, 157, 167, 176, 186, 196, 205, 217, 230, 241, 254, 265}
+var _Kind_index = [...]uint16{0, 3, 8, 15, 24, 26, 31, 34, 39, 41, 47, 52, 63, 75, 79, 88, 98, 103, 107, 121, 130, 141, 151, 162, 166, 170, 181, 187, 197, 208, 216, 220, 226, 238, 245, 262, 272, 278, 284, 296, 303, 312, 322, 332, 341, 353, 366, 377, 390, 401}
 
 func (i Kind) String() string {
 	idx := int(i) - 0
@@ -37,8 +37,14 @@ func (t Token) Precedence() int {
 	switch t.Kind {
 	case OpenInterp:
 		return HighestPrecedence
-	case Dot:
+	case Dot, LeftParen:
 		return HighestPrecedence - 1
+	case Plus:
+		return HighestPrecedence - 2
+	case DoubleQuestion:
+		return HighestPrecedence - 3
+	case Pipe:
+		return HighestPrecedence - 4
 	default:
 		return LowestPrecedence
 	}
@@ -52,12 +58,22 @@ func Keyword(text string) (kind Kind, ok bool) {
 		return Name, true
 	case "prompt":
 		return Prompt, true
+	case "prompt-secret":
+		return PromptSecret, true
 	case "timeout":
 		return Timeout, true
 	case "connection-timeout":
 		return ConnectionTimeout, true
 	case "no-redirect":
 		return NoRedirect, true
+	case "assert":
+		return Assert, true
+	case "ignore":
+		return Ignore, true
+	case "ignore-header":
+		return IgnoreHeader, true
+	case "capture":
+		return Capture, true
 	default:
 		return Ident, false
 	}
@@ -49,6 +49,12 @@ func TestKeyword(t *testing.T) {
 		{text: "timeout", want: token.Timeout, ok: true},
 		{text: "connection-timeout", want: token.ConnectionTimeout, ok: true},
 		{text: "no-redirect", want: token.NoRedirect, ok: true},
+		{text: "assert", want: token.Assert, ok: true},
+		{text: "ignore", want: token.Ignore, ok: true},
+		{text: "ignore-header", want: token.IgnoreHeader, ok: true},
+		{text: "capture", want: token.Capture, ok: true},
+		{text: "prompt", want: token.Prompt, ok: true},
+		{text: "prompt-secret", want: token.PromptSecret, ok: true},
 		{text: "something-else", want: token.Ident, ok: false},
 		{text: "base", want: token.Ident, ok: false},
 		{text: "myVar", want: token.Ident, ok: false},
@@ -99,8 +105,13 @@ func TestPrecedence(t *testing.T) {
 		kind token.Kind // Token kind under test
 		want int        // Expected precedence
 	}{
-		// Currently the only one with any precedence
+		// Operators, binding from tightest to loosest
 		{kind: token.OpenInterp, want: token.HighestPrecedence},
+		{kind: token.Dot, want: token.HighestPrecedence - 1},
+		{kind: token.LeftParen, want: token.HighestPrecedence - 1},
+		{kind: token.Plus, want: token.HighestPrecedence - 2},
+		{kind: token.DoubleQuestion, want: token.HighestPrecedence - 3},
+		{kind: token.Pipe, want: token.HighestPrecedence - 4},
 		// Some others
 		{kind: token.Text, want: token.LowestPrecedence},
 		{kind: token.Separator, want: token.LowestPrecedence},
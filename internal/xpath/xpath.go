@@ -0,0 +1,267 @@
+// Package xpath implements a small, dependency free subset of XPath for selecting
+// values out of XML documents.
+//
+// The supported syntax is:
+//
+//   - '/name' a child element called 'name', every path must start with '/'
+//   - '//name' an element called 'name' at any depth below the current node
+//   - '*' in place of a name, any element
+//   - '[n]' the nth (1 based) of the matching elements under each parent
+//   - '@name' an attribute, only as the final step
+//   - 'text()' the text directly inside an element, only as the final step
+//
+// Selected elements evaluate to their text content, i.e. all the text inside them
+// concatenated in document order.
+package xpath
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// kind is the kind of a single path step.
+type kind int
+
+const (
+	kindChild   kind = iota // Child elements e.g. '/name'
+	kindDescend             // Descendant elements e.g. '//name'
+	kindAttr                // An attribute e.g. '/@name'
+	kindText                // Direct text e.g. '/text()'
+)
+
+// step is a single step in a [Path].
+type step struct {
+	name  string // The element or attribute name, '*' matches any element
+	index int    // The 1 based position predicate, 0 if there isn't one
+	kind  kind   // The kind of step
+}
+
+// Path is a compiled XPath expression.
+type Path struct {
+	raw   string // The original expression
+	steps []step // The compiled steps
+}
+
+// node is an element in a decoded XML document.
+type node struct {
+	attrs    map[string]string // Attributes by local name
+	name     string            // Local name of the element, "" for the document root
+	text     string            // Text directly inside the element
+	children []*node           // Child elements in document order
+	content  strings.Builder   // All text inside the element, including descendants
+}
+
+// Parse compiles an XPath expression into a [Path].
+func Parse(expr string) (Path, error) {
+	if !strings.HasPrefix(expr, "/") {
+		return Path{}, fmt.Errorf("invalid XPath %q: must start with '/'", expr)
+	}
+
+	path := Path{raw: expr}
+	rest := expr
+
+	for rest != "" {
+		if len(path.steps) != 0 && path.steps[len(path.steps)-1].kind >= kindAttr {
+			return Path{}, fmt.Errorf("invalid XPath %q: attribute and text() must be the final step", expr)
+		}
+
+		var seg step
+
+		switch {
+		case strings.HasPrefix(rest, "//"):
+			seg.kind = kindDescend
+			rest = rest[2:]
+		case rest[0] == '/':
+			seg.kind = kindChild
+			rest = rest[1:]
+		default:
+			return Path{}, fmt.Errorf("invalid XPath %q: unexpected character %q", expr, rest[0])
+		}
+
+		end := strings.IndexAny(rest, "/[")
+		if end == -1 {
+			end = len(rest)
+		}
+
+		name := rest[:end]
+		rest = rest[end:]
+
+		switch {
+		case name == "":
+			return Path{}, fmt.Errorf("invalid XPath %q: empty step", expr)
+		case name == "text()":
+			seg.kind = kindText
+		case strings.HasPrefix(name, "@"):
+			if seg.kind != kindChild || len(name) == 1 {
+				return Path{}, fmt.Errorf("invalid XPath %q: bad attribute step %q", expr, name)
+			}
+
+			seg.kind = kindAttr
+			name = name[1:]
+		}
+
+		if name != "*" && seg.kind != kindText && strings.ContainsFunc(name, invalidName) {
+			return Path{}, fmt.Errorf("invalid XPath %q: invalid name %q", expr, name)
+		}
+
+		seg.name = name
+
+		if inner, ok := strings.CutPrefix(rest, "["); ok {
+			index, after, found := strings.Cut(inner, "]")
+			if !found {
+				return Path{}, fmt.Errorf("invalid XPath %q: unterminated '['", expr)
+			}
+
+			n, err := strconv.Atoi(strings.TrimSpace(index))
+			if err != nil || n < 1 {
+				return Path{}, fmt.Errorf("invalid XPath %q: invalid position %q", expr, index)
+			}
+
+			if seg.kind >= kindAttr {
+				return Path{}, fmt.Errorf("invalid XPath %q: position on %s", expr, name)
+			}
+
+			seg.index = n
+			rest = after
+		}
+
+		path.steps = append(path.steps, seg)
+	}
+
+	return path, nil
+}
+
+// String implements [fmt.Stringer] for a [Path], returning the original expression.
+func (p Path) String() string {
+	return p.raw
+}
+
+// Select decodes the XML document and returns the string value of everything
+// matched by the path, in document order.
+//
+// If nothing matches, the returned slice is empty.
+func (p Path) Select(document []byte) ([]string, error) {
+	root, err := decode(document)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []*node{root}
+
+	var values []string
+
+	for _, seg := range p.steps {
+		var next []*node
+
+		for _, n := range nodes {
+			switch seg.kind {
+			case kindChild:
+				next = append(next, seg.filter(n.children)...)
+			case kindDescend:
+				next = append(next, seg.filter(descendants(n, nil))...)
+			case kindAttr:
+				if value, ok := n.attrs[seg.name]; ok {
+					values = append(values, value)
+				}
+			case kindText:
+				if n.name != "" {
+					values = append(values, n.text)
+				}
+			}
+		}
+
+		nodes = next
+	}
+
+	for _, n := range nodes {
+		values = append(values, n.content.String())
+	}
+
+	return values, nil
+}
+
+// invalidName reports whether r may not appear in an element or attribute name.
+func invalidName(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_.:", r)
+}
+
+// filter returns the elements matching the step's name and position.
+func (s step) filter(elements []*node) []*node {
+	var matched []*node
+
+	for _, element := range elements {
+		if s.name == "*" || element.name == s.name {
+			matched = append(matched, element)
+		}
+	}
+
+	if s.index == 0 {
+		return matched
+	}
+
+	if s.index > len(matched) {
+		return nil
+	}
+
+	return matched[s.index-1 : s.index]
+}
+
+// descendants appends every element below n to found, in document order.
+func descendants(n *node, found []*node) []*node {
+	for _, child := range n.children {
+		found = append(found, child)
+		found = descendants(child, found)
+	}
+
+	return found
+}
+
+// decode parses an XML document into a tree of nodes, returning the document root
+// whose only child is the root element.
+func decode(document []byte) (*node, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(document))
+	root := &node{}
+	stack := []*node{root}
+
+	for {
+		tok, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			element := &node{name: t.Name.Local, attrs: make(map[string]string, len(t.Attr))}
+			for _, attr := range t.Attr {
+				element.attrs[attr.Name.Local] = attr.Value
+			}
+
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, element)
+			stack = append(stack, element)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			stack[len(stack)-1].text += string(t)
+			for _, open := range stack {
+				open.content.Write(t)
+			}
+		}
+	}
+
+	if len(root.children) == 0 {
+		return nil, errors.New("invalid XML: no root element")
+	}
+
+	return root, nil
+}
@@ -0,0 +1,88 @@
+package xpath_test
+
+import (
+	"slices"
+	"testing"
+
+	"go.followtheprocess.codes/test"
+	"go.followtheprocess.codes/zap/internal/xpath"
+)
+
+const document = `<?xml version="1.0"?>
+<library name="zap">
+  <book id="1"><title>First</title><author>A</author></book>
+  <book id="2"><title>Second</title><author>B</author></book>
+  <shelf><book id="3"><title>Third</title></book></shelf>
+</library>`
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name string   // Name of the test case
+		expr string   // XPath expression under test
+		want []string // Expected matches
+	}{
+		{name: "child", expr: "/library/book/title", want: []string{"First", "Second"}},
+		{name: "attribute", expr: "/library/@name", want: []string{"zap"}},
+		{name: "position", expr: "/library/book[2]/title", want: []string{"Second"}},
+		{name: "position out of range", expr: "/library/book[5]", want: nil},
+		{name: "descendant", expr: "//title", want: []string{"First", "Second", "Third"}},
+		{name: "descendant attribute", expr: "//book/@id", want: []string{"1", "2", "3"}},
+		{name: "wildcard", expr: "/library/*[3]/book/title", want: []string{"Third"}},
+		{name: "text", expr: "/library/book[1]/author/text()", want: []string{"A"}},
+		{name: "element content", expr: "/library/book[1]", want: []string{"FirstA"}},
+		{name: "missing", expr: "/library/magazine", want: nil},
+		{name: "missing attribute", expr: "/library/@missing", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := xpath.Parse(tt.expr)
+			test.Ok(t, err)
+
+			test.Equal(t, path.String(), tt.expr)
+
+			got, err := path.Select([]byte(document))
+			test.Ok(t, err)
+
+			test.EqualFunc(t, got, tt.want, slices.Equal)
+		})
+	}
+}
+
+func TestSelectInvalidDocument(t *testing.T) {
+	path, err := xpath.Parse("/root")
+	test.Ok(t, err)
+
+	_, err = path.Select([]byte(`{"not": "xml"}`))
+	test.Err(t, err)
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string // Name of the test case
+		expr string // XPath expression under test
+		want string // Expected error message
+	}{
+		{name: "empty", expr: "", want: `invalid XPath "": must start with '/'`},
+		{name: "relative", expr: "library/book", want: `invalid XPath "library/book": must start with '/'`},
+		{name: "empty step", expr: "/library/", want: `invalid XPath "/library/": empty step`},
+		{name: "bad position", expr: "/book[first]", want: `invalid XPath "/book[first]": invalid position "first"`},
+		{name: "zero position", expr: "/book[0]", want: `invalid XPath "/book[0]": invalid position "0"`},
+		{name: "unterminated", expr: "/book[1", want: `invalid XPath "/book[1": unterminated '['`},
+		{
+			name: "attribute not last",
+			expr: "/book/@id/title",
+			want: `invalid XPath "/book/@id/title": attribute and text() must be the final step`,
+		},
+		{name: "descendant attribute", expr: "//@id", want: `invalid XPath "//@id": bad attribute step "@id"`},
+		{name: "junk", expr: "/book]", want: `invalid XPath "/book]": invalid name "book]"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := xpath.Parse(tt.expr)
+			test.Err(t, err)
+			test.Equal(t, err.Error(), tt.want)
+		})
+	}
+}
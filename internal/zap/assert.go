@@ -0,0 +1,231 @@
+package zap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go.followtheprocess.codes/zap/internal/jsonpath"
+	"go.followtheprocess.codes/zap/internal/spec"
+)
+
+// maxShownLength is the maximum length in bytes of an actual value shown in an
+// assertion failure message before it is truncated.
+const maxShownLength = 80
+
+// assertionFailure is a single response assertion that did not hold.
+type assertionFailure struct {
+	err       error          // Why the assertion failed e.g. 'got 404'
+	assertion spec.Assertion // The assertion that failed
+}
+
+// String implements [fmt.Stringer] for an [assertionFailure].
+func (a assertionFailure) String() string {
+	return fmt.Sprintf("%s: %s: %v", a.assertion.Position, a.assertion, a.err)
+}
+
+// checkAssertions evaluates every assertion against the response, returning
+// those that failed.
+func checkAssertions(assertions []spec.Assertion, response Response) []assertionFailure {
+	var failures []assertionFailure
+
+	// Only decode the body once, and only if something needs it
+	var (
+		document any
+		bodyErr  error
+		decoded  bool
+	)
+
+	for _, assertion := range assertions {
+		if strings.HasPrefix(assertion.Subject, "$") && !decoded {
+			bodyErr = json.Unmarshal(response.Body, &document)
+			if bodyErr != nil {
+				bodyErr = fmt.Errorf("response body is not valid JSON: %w", bodyErr)
+			}
+
+			decoded = true
+		}
+
+		if err := checkAssertion(assertion, response, document, bodyErr); err != nil {
+			failures = append(failures, assertionFailure{assertion: assertion, err: err})
+		}
+	}
+
+	return failures
+}
+
+// checkAssertion evaluates a single assertion against the response, returning an
+// error describing the failure if it does not hold.
+//
+// document is the decoded JSON body, only used for JSONPath subjects. If the body
+// could not be decoded, bodyErr is non-nil.
+func checkAssertion(assertion spec.Assertion, response Response, document any, bodyErr error) error {
+	var (
+		actual  any
+		present bool
+	)
+
+	switch subject := assertion.Subject; {
+	case subject == "status":
+		actual, present = response.StatusCode, true
+	case subject == "body":
+		actual, present = string(response.Body), true
+	case strings.HasPrefix(subject, "header."):
+		values := response.Header.Values(strings.TrimPrefix(subject, "header."))
+		actual, present = strings.Join(values, ", "), len(values) != 0
+	case strings.HasPrefix(subject, "$"):
+		if bodyErr != nil {
+			return bodyErr
+		}
+
+		path, err := jsonpath.Parse(subject)
+		if err != nil {
+			return err
+		}
+
+		matches := path.Select(document)
+
+		switch len(matches) {
+		case 0:
+			present = false
+		case 1:
+			actual, present = matches[0], true
+		default:
+			actual, present = matches, true
+		}
+	default:
+		return fmt.Errorf("unknown assertion subject %q", subject)
+	}
+
+	if !present {
+		return errors.New("not found")
+	}
+
+	if assertion.Operator == "exists" {
+		return nil
+	}
+
+	return compare(actual, assertion.Operator, assertion.Value.String())
+}
+
+// compare compares the actual value against the expected text using operator.
+func compare(actual any, operator, expected string) error {
+	got := fmt.Errorf("got %s", show(actual))
+
+	switch operator {
+	case "==":
+		if !equal(actual, expected) {
+			return got
+		}
+	case "!=":
+		if equal(actual, expected) {
+			return got
+		}
+	case "contains":
+		if !strings.Contains(text(actual), expected) {
+			return got
+		}
+	case "matches":
+		re, err := regexp.Compile(expected)
+		if err != nil {
+			return fmt.Errorf("invalid regular expression: %w", err)
+		}
+
+		if !re.MatchString(text(actual)) {
+			return got
+		}
+	case "<", "<=", ">", ">=":
+		want, err := strconv.ParseFloat(expected, 64)
+		if err != nil {
+			return fmt.Errorf("operator %s requires a number, got %q", operator, expected)
+		}
+
+		have, err := strconv.ParseFloat(text(actual), 64)
+		if err != nil {
+			return fmt.Errorf("%s is not a number", show(actual))
+		}
+
+		if !ordered(have, want, operator) {
+			return got
+		}
+	default:
+		return fmt.Errorf("unknown assertion operator %q", operator)
+	}
+
+	return nil
+}
+
+// ordered reports whether have and want satisfy the ordering operator.
+func ordered(have, want float64, operator string) bool {
+	switch operator {
+	case "<":
+		return have < want
+	case "<=":
+		return have <= want
+	case ">":
+		return have > want
+	case ">=":
+		return have >= want
+	default:
+		return false
+	}
+}
+
+// equal reports whether actual is equal to the expected text.
+//
+// The expected text is first compared against the textual form of actual, then
+// if it is valid JSON e.g. '"quoted"', '[1, 2]' or '{"a": true}', it is decoded
+// and compared structurally.
+func equal(actual any, expected string) bool {
+	if text(actual) == expected {
+		return true
+	}
+
+	var want any
+	if err := json.Unmarshal([]byte(expected), &want); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(actual, want)
+}
+
+// text returns the textual form of a value, strings are returned as is
+// and everything else as JSON.
+func text(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(encoded)
+}
+
+// show returns a short representation of value for use in failure messages.
+func show(value any) string {
+	shown := text(value)
+
+	if len(shown) > maxShownLength {
+		// Back off to the start of a rune so a multi-byte character is never split
+		end := maxShownLength
+		for end > 0 && !utf8.RuneStart(shown[end]) {
+			end--
+		}
+
+		shown = shown[:end] + "..."
+	}
+
+	if _, isString := value.(string); isString {
+		return strconv.Quote(shown)
+	}
+
+	return shown
+}
@@ -0,0 +1,365 @@
+package zap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.followtheprocess.codes/zap/internal/jsonpath"
+	"go.followtheprocess.codes/zap/internal/spec"
+	"go.followtheprocess.codes/zap/internal/syntax/resolver"
+	"go.followtheprocess.codes/zap/internal/syntax/resolver/builtins"
+	"go.followtheprocess.codes/zap/internal/xpath"
+)
+
+// exchange is a request as it was sent and the response it received, kept so that
+// later requests may reference them.
+type exchange struct {
+	request  spec.Request // The request with all references replaced
+	response Response     // The response to the request
+}
+
+// executionOrder returns the selected requests along with any others they depend on,
+// ordered such that every request comes after the requests it references.
+//
+// Otherwise the order of the requests in the file is preserved.
+func executionOrder(all, selected []spec.Request) ([]spec.Request, error) {
+	byName := make(map[string]spec.Request, len(all))
+	for _, request := range all {
+		byName[request.Name] = request
+	}
+
+	var (
+		ordered []spec.Request
+		done    = make(map[string]bool)
+		visit   func(request spec.Request, path []string) error
+	)
+
+	visit = func(request spec.Request, path []string) error {
+		if done[request.Name] {
+			return nil
+		}
+
+		if slices.Contains(path, request.Name) {
+			cycle := slices.Concat(path[slices.Index(path, request.Name):], []string{request.Name})
+			return fmt.Errorf("requests reference each other in a cycle: %s", strings.Join(cycle, " -> "))
+		}
+
+		path = append(path, request.Name)
+
+		for _, name := range request.DependsOn {
+			dependency, ok := byName[name]
+			if !ok {
+				return fmt.Errorf("request %s references unknown request %s", request.Name, name)
+			}
+
+			if err := visit(dependency, path); err != nil {
+				return err
+			}
+		}
+
+		done[request.Name] = true
+		ordered = append(ordered, request)
+
+		return nil
+	}
+
+	for _, request := range selected {
+		if err := visit(request, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+// requestScope returns the runtime scope in which the deferred interpolations in request
+// are evaluated: the answers to its prompts and the global ones, the values captured from
+// responses so far, references to the already executed exchanges and the variables whose
+// values are dynamic builtins e.g. '$uuid', evaluated using library along with the builtins
+// reading local files e.g. '$dotenv'.
+//
+// A new scope must be created for every execution of a request, dynamic variables are
+// evaluated at most once per scope so every use of one within a request has the same value.
+//
+// dir is the directory containing the .http file, relative to which any referenced body
+// file, or file read by a builtin, is resolved.
+func requestScope(
+	file spec.File,
+	request spec.Request,
+	exchanges map[string]exchange,
+	captured map[string]string,
+	dir string,
+	library builtins.Library,
+) resolver.Scope {
+	variables := promptValues(file.Prompts)
+	maps.Copy(variables, captured)
+	maps.Copy(variables, promptValues(request.Prompts))
+
+	return resolver.Scope{
+		Variables: variables,
+		Templates: templates(file, request),
+		Library:   builtins.Local(library, dir),
+		Reference: func(reference resolver.Reference) (string, error) {
+			ex, ok := exchanges[reference.Request]
+			if !ok {
+				return "", fmt.Errorf("request %s has not been executed", reference.Request)
+			}
+
+			result, err := referenceValue(reference, ex, dir)
+			if err != nil {
+				return "", fmt.Errorf("could not evaluate %s: %w", reference, err)
+			}
+
+			return result, nil
+		},
+	}
+}
+
+// templates returns the variables in scope for request, global and request level, that
+// are evaluated when used rather than when the file was resolved.
+func templates(file spec.File, request spec.Request) map[string]spec.Template {
+	templates := make(map[string]spec.Template, len(file.Vars)+len(request.Vars))
+	maps.Copy(templates, file.Vars)
+	maps.Copy(templates, request.Vars)
+
+	return templates
+}
+
+// evaluateRequest evaluates the interpolations in request that were deferred until runtime,
+// e.g. prompts, captured values and references to other requests, against scope.
+func evaluateRequest(request spec.Request, scope resolver.Scope) (spec.Request, error) {
+	var err error
+
+	for _, field := range []*spec.Template{
+		&request.URL,
+		&request.Body,
+		&request.BodyFile,
+		&request.ResponseFile,
+		&request.ResponseRef,
+	} {
+		if *field, err = evaluate(*field, scope); err != nil {
+			return spec.Request{}, err
+		}
+	}
+
+	vars := make(map[string]spec.Template, len(request.Vars))
+
+	for name, value := range request.Vars {
+		if vars[name], err = evaluate(value, scope); err != nil {
+			return spec.Request{}, err
+		}
+	}
+
+	request.Vars = vars
+
+	headers := make(spec.Headers, len(request.Headers))
+
+	for key, values := range request.Headers {
+		for _, header := range values {
+			evaluated, err := evaluate(header, scope)
+			if err != nil {
+				return spec.Request{}, err
+			}
+
+			headers.Add(key, evaluated)
+		}
+	}
+
+	request.Headers = headers
+
+	assertions := slices.Clone(request.Assertions)
+	for i, assertion := range assertions {
+		if assertions[i].Value, err = evaluate(assertion.Value, scope); err != nil {
+			return spec.Request{}, err
+		}
+	}
+
+	request.Assertions = assertions
+
+	return request, nil
+}
+
+// evaluate evaluates template against scope, returning the result as text.
+func evaluate(template spec.Template, scope resolver.Scope) (spec.Template, error) {
+	if !template.Deferred() {
+		return template, nil
+	}
+
+	value, err := resolver.Evaluate(template, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return spec.Text(value), nil
+}
+
+// captureValues evaluates the captures declared on request against its response,
+// returning the captured values by variable name.
+func captureValues(request spec.Request, response Response) (map[string]string, error) {
+	values := make(map[string]string, len(request.Captures))
+
+	for _, capture := range request.Captures {
+		value, err := captureValue(capture.Source, response)
+		if err != nil {
+			return nil, fmt.Errorf("could not capture %s from %s: %w", capture.Name, capture.Source, err)
+		}
+
+		values[capture.Name] = value
+	}
+
+	return values, nil
+}
+
+// captureValue returns the part of the response given by source.
+//
+// JSONPath sources select from the response as a document of the form
+// '{"status": 200, "headers": {"Name": "value"}, "body": <JSON body or text>}'.
+func captureValue(source string, response Response) (string, error) {
+	switch {
+	case source == "status":
+		return strconv.Itoa(response.StatusCode), nil
+	case source == "body":
+		return string(response.Body), nil
+	case strings.HasPrefix(source, "header."):
+		name := strings.TrimPrefix(source, "header.")
+
+		values := response.Header.Values(name)
+		if len(values) == 0 {
+			return "", fmt.Errorf("no header %s", name)
+		}
+
+		return strings.Join(values, ", "), nil
+	default:
+		path, err := jsonpath.Parse(source)
+		if err != nil {
+			return "", err
+		}
+
+		headers := make(map[string]any, len(response.Header))
+		for key, values := range response.Header {
+			headers[key] = strings.Join(values, ", ")
+		}
+
+		var body any
+		if err := json.Unmarshal(response.Body, &body); err != nil {
+			body = string(response.Body)
+		}
+
+		document := map[string]any{
+			"status":  response.StatusCode,
+			"headers": headers,
+			"body":    body,
+		}
+
+		return firstMatch(path, document)
+	}
+}
+
+// referenceValue returns the value of a single reference into an exchange.
+func referenceValue(reference resolver.Reference, ex exchange, dir string) (string, error) {
+	var (
+		header http.Header
+		body   []byte
+	)
+
+	switch reference.Source {
+	case "request":
+		header = ex.request.Headers.Header()
+		body = []byte(ex.request.Body.String())
+
+		if len(ex.request.BodyFile) != 0 {
+			path := ex.request.BodyFile.String()
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+
+			contents, err := os.ReadFile(path)
+			if err != nil {
+				return "", fmt.Errorf("could not read body file: %w", err)
+			}
+
+			body = contents
+		}
+	case "response":
+		header = ex.response.Header
+		body = ex.response.Body
+	default:
+		return "", fmt.Errorf("unknown reference source %q", reference.Source)
+	}
+
+	switch reference.Part {
+	case "status":
+		return strconv.Itoa(ex.response.StatusCode), nil
+	case "headers":
+		values := header.Values(reference.Selector)
+		if len(values) == 0 {
+			return "", fmt.Errorf("no header %s", reference.Selector)
+		}
+
+		return strings.Join(values, ", "), nil
+	case "body":
+		return selectBody(body, reference.Selector)
+	default:
+		return "", fmt.Errorf("unknown reference part %q", reference.Part)
+	}
+}
+
+// selectBody returns the first value in body matched by a JSONPath or XPath query,
+// or the whole body if the query is empty or '*'.
+//
+// Matched JSON strings are returned as is, any other JSON values are returned encoded.
+// Matched XML elements are returned as their text content.
+func selectBody(body []byte, query string) (string, error) {
+	switch {
+	case query == "" || query == "*":
+		return string(body), nil
+	case strings.HasPrefix(query, "$"):
+		path, err := jsonpath.Parse(query)
+		if err != nil {
+			return "", err
+		}
+
+		var document any
+		if err := json.Unmarshal(body, &document); err != nil {
+			return "", fmt.Errorf("body is not valid JSON: %w", err)
+		}
+
+		return firstMatch(path, document)
+	default:
+		path, err := xpath.Parse(query)
+		if err != nil {
+			return "", err
+		}
+
+		matches, err := path.Select(body)
+		if err != nil {
+			return "", err
+		}
+
+		if len(matches) == 0 {
+			return "", errors.New("no match")
+		}
+
+		return matches[0], nil
+	}
+}
+
+// firstMatch returns the textual form of the first value in document matched by path.
+//
+// Matched strings are returned as is, any other values are returned as JSON.
+func firstMatch(path jsonpath.Path, document any) (string, error) {
+	matches := path.Select(document)
+	if len(matches) == 0 {
+		return "", errors.New("no match")
+	}
+
+	return text(matches[0]), nil
+}
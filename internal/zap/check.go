@@ -3,10 +3,8 @@ package zap
 import (
 	"context"
 	"fmt"
-	"io/fs"
 	"log/slog"
 	"os"
-	"path/filepath"
 
 	"go.followtheprocess.codes/msg"
 	"golang.org/x/sync/errgroup"
@@ -17,6 +15,13 @@ type CheckOptions struct {
 	// Path is the path (file or directory) to check.
 	Path string
 
+	// Vars are variables given as 'key=value' pairs, overriding the file's globals.
+	Vars []string
+
+	// VarFiles are dotenv, JSON or YAML files of variables, overriding the file's
+	// globals. Vars take precedence over them.
+	VarFiles []string
+
 	// Debug enables debug logging.
 	Debug bool
 }
@@ -26,38 +31,18 @@ func (z Zap) Check(ctx context.Context, options CheckOptions) error {
 	logger := z.logger.Prefixed("check").With(slog.String("path", options.Path))
 	logger.Debug("Checking path")
 
-	info, err := os.Stat(options.Path)
+	paths, err := collectFiles(logger, options.Path)
 	if err != nil {
-		return fmt.Errorf("could not get path info: %w", err)
+		return err
 	}
 
-	var paths []string
-
-	if info.IsDir() {
-		logger.Debug("Path is a directory")
-
-		err = filepath.WalkDir(options.Path, func(path string, d fs.DirEntry, err error) error {
-			if err != nil {
-				return err
-			}
-
-			if filepath.Ext(path) == ".http" {
-				paths = append(paths, path)
-			}
-
-			return nil
-		})
-		if err != nil {
-			return fmt.Errorf("could not walk %s: %w", options.Path, err)
-		}
-	} else {
-		logger.Debug("Path is a file")
+	logger.Debug("Checking http files given by path", slog.Int("number", len(paths)))
 
-		paths = []string{options.Path}
+	parse, err := newParseOptions(options.Vars, options.VarFiles, "", 0)
+	if err != nil {
+		return err
 	}
 
-	logger.Debug("Checking http files given by path", slog.Int("number", len(paths)))
-
 	group := errgroup.Group{}
 
 	for _, path := range paths {
@@ -68,7 +53,7 @@ func (z Zap) Check(ctx context.Context, options CheckOptions) error {
 			}
 			defer f.Close()
 
-			_, err = z.parseFile(path, f)
+			_, err = z.parseFile(path, f, parse)
 			if err != nil {
 				return fmt.Errorf("zap check: %w", err)
 			}
@@ -81,6 +66,8 @@ func (z Zap) Check(ctx context.Context, options CheckOptions) error {
 		return err
 	}
 
+	warnUnusedVars(logger, parse)
+
 	for _, path := range paths {
 		msg.Fsuccess(z.stdout, "%s is valid", path)
 	}
@@ -5,12 +5,13 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"path/filepath"
 	"slices"
 	"strings"
 	"time"
 
 	"go.followtheprocess.codes/zap/internal/format"
-	"go.followtheprocess.codes/zap/internal/spec"
+	"go.followtheprocess.codes/zap/internal/syntax/resolver/builtins"
 )
 
 const (
@@ -19,6 +20,8 @@ const (
 	formatTOML    = "toml"
 	formatCurl    = "curl"
 	formatPostman = "postman"
+	formatHAR     = "har"
+	formatOpenAPI = "openapi"
 )
 
 // ExportOptions are the flags passed to the export subcommand.
@@ -29,6 +32,29 @@ type ExportOptions struct {
 	// Format is the format of the export e.g. curl, postman etc.
 	Format string
 
+	// Vars are variables given as 'key=value' pairs, overriding the file's globals.
+	Vars []string
+
+	// VarFiles are dotenv, JSON or YAML files of variables, overriding the file's
+	// globals. Vars take precedence over them.
+	VarFiles []string
+
+	// Prompts are answers to prompts given as 'name=value' pairs, so they
+	// aren't asked interactively.
+	Prompts []string
+
+	// PromptFile is a dotenv, JSON or YAML file of answers to prompts, or "-" to
+	// read them from stdin. Prompts take precedence over it.
+	PromptFile string
+
+	// Environment is the name of the environment whose variables are
+	// resolved into the export, empty means none.
+	Environment string
+
+	// Seed seeds the random builtins e.g. '$uuid' and '$random.email' so their
+	// values are reproducible between runs. Zero means a random seed.
+	Seed uint64
+
 	// Debug controls debug logging.
 	Debug bool
 }
@@ -36,7 +62,7 @@ type ExportOptions struct {
 // Validate reports whether the ExportOptions is valid, returning a non-nil
 // error if it's not.
 func (e ExportOptions) Validate() error {
-	allowed := []string{formatJSON, formatYAML, formatTOML, formatCurl, formatPostman}
+	allowed := []string{formatJSON, formatYAML, formatTOML, formatCurl, formatPostman, formatHAR}
 	if !slices.Contains(allowed, e.Format) {
 		return fmt.Errorf("invalid option for --format, expected one of (%s)", strings.Join(allowed, ", "))
 	}
@@ -48,7 +74,11 @@ func (e ExportOptions) Validate() error {
 func (z Zap) Export(ctx context.Context, r io.Reader, options ExportOptions) error {
 	logger := z.logger.Prefixed("export")
 
-	logger.Debug("Export configuration", slog.String("options", fmt.Sprintf("%+v", options)))
+	// Answers to prompts may be secret, only log which prompts they answer
+	logged := options
+	logged.Prompts = redactAnswers(options.Prompts)
+
+	logger.Debug("Export configuration", slog.String("options", fmt.Sprintf("%+v", logged)))
 
 	if err := options.Validate(); err != nil {
 		return err
@@ -56,43 +86,92 @@ func (z Zap) Export(ctx context.Context, r io.Reader, options ExportOptions) err
 
 	start := time.Now()
 
-	httpFile, err := z.parseFile(options.File, r)
+	parse, err := newParseOptions(options.Vars, options.VarFiles, options.Environment, options.Seed)
+	if err != nil {
+		return err
+	}
+
+	answers, err := loadAnswers(options.Prompts, options.PromptFile, z.stdin)
+	if err != nil {
+		return err
+	}
+
+	httpFile, err := z.parseFile(options.File, r, parse)
 	if err != nil {
 		return err
 	}
 
+	warnUnusedVars(logger, parse)
+
 	logger.Debug(
 		"Parsed file successfully",
 		slog.String("file", options.File),
 		slog.Duration("took", time.Since(start)),
 	)
 
-	httpFile, err = z.evaluateAllPrompts(logger, httpFile)
+	exporter, ok := exporterFor(options.Format, z.version)
+	if !ok {
+		return fmt.Errorf("no exporter for format %s", options.Format)
+	}
+
+	var library builtins.Library = parse.library
+	if builtinExporter, ok := exporter.(format.BuiltinExporter); ok {
+		library = exportLibrary{library: parse.library, exporter: builtinExporter}
+	}
+
+	library = builtins.Local(library, filepath.Dir(options.File))
+
+	httpFile, err = z.evaluateAllPrompts(logger, httpFile, answers, library)
 	if err != nil {
 		return err
 	}
 
-	return z.exportFile(httpFile, options)
+	return exporter.Export(z.stdout, httpFile)
 }
 
-// exportFile performs the export operation on the given http file.
-func (z Zap) exportFile(file spec.File, options ExportOptions) error {
-	// Default to JSON
-	var exporter format.Exporter
-
-	switch options.Format {
+// exporterFor returns the [format.Exporter] for the named export format, and whether
+// there is one.
+//
+// version is the version of zap, for formats that record what created them.
+func exporterFor(name, version string) (format.Exporter, bool) {
+	switch name {
 	case formatJSON:
-		exporter = format.JSONExporter{}
+		return format.JSONExporter{}, true
 	case formatYAML:
-		exporter = format.YAMLExporter{}
+		return format.YAMLExporter{}, true
 	case formatTOML:
-		exporter = format.TOMLExporter{}
+		return format.TOMLExporter{}, true
 	case formatCurl:
-		exporter = format.CurlExporter{}
+		return format.CurlExporter{}, true
+	case formatPostman:
+		return format.PostmanExporter{}, true
+	case formatHAR:
+		return format.HARExporter{Version: version}, true
 	default:
-		fmt.Printf("TODO: Handle %s\n", options.Format)
-		return nil
+		return nil, false
 	}
+}
+
+// exportLibrary is a [builtins.Library] for exporting, dynamic builtins the exporter has an
+// equivalent of are replaced by it so they are still evaluated every time the exported
+// request is sent, rather than fixing the value they had when exported.
+type exportLibrary struct {
+	library  builtins.Library       // The library providing everything else
+	exporter format.BuiltinExporter // The exporter providing the equivalents
+}
+
+// Get implements [builtins.Library] for [exportLibrary].
+func (e exportLibrary) Get(name string) (builtins.Builtin, bool) {
+	fn, ok := e.library.Get(name)
+	if !ok || !builtins.Dynamic(name) {
+		return fn, ok
+	}
+
+	return func(args ...string) (string, error) {
+		if equivalent, ok := e.exporter.Builtin(name, args...); ok {
+			return equivalent, nil
+		}
 
-	return exporter.Export(z.stdout, file)
+		return fn(args...)
+	}, true
 }
@@ -1,8 +1,11 @@
 package zap
 
 import (
+	"crypto/tls"
 	"net"
 	"net/http"
+	"net/http/httptrace"
+	"sync"
 	"time"
 
 	"go.followtheprocess.codes/zap/internal/spec"
@@ -49,6 +52,7 @@ func NewHTTPClient(file spec.File) http.Client {
 // Response is a compact version of a [http.Response] with only the data we need
 // to display a HTTP response to a user.
 type Response struct {
+	Started       time.Time     // When the request was started
 	Header        http.Header   // Response headers
 	Status        string        // E.g. "200 OK"
 	Proto         string        // e.g. "HTTP/1.2"
@@ -56,4 +60,98 @@ type Response struct {
 	StatusCode    int           // HTTP status code
 	ContentLength int           // len(Body)
 	Duration      time.Duration // Duration of the request/response round trip
+	Timings       Timings       // How long each phase of the request took
+}
+
+// Timings are how long each phase of a HTTP request took.
+//
+// A phase that did not happen, such as the DNS lookup and connecting when a connection
+// is reused, is zero.
+type Timings struct {
+	Blocked time.Duration // Waiting for a connection to become available
+	DNS     time.Duration // Resolving the host name
+	Connect time.Duration // Creating the connection, including TLS
+	TLS     time.Duration // The TLS handshake
+	Send    time.Duration // Sending the request
+	Wait    time.Duration // Waiting for the first byte of the response
+	Receive time.Duration // Reading the response
+}
+
+// timer records when each phase of a HTTP request starts and ends, with the hooks in
+// a [httptrace.ClientTrace].
+//
+// When a request is redirected, the times are the last ones recorded.
+type timer struct {
+	start        time.Time  // When the request was started
+	dnsStart     time.Time  // When the DNS lookup started
+	dnsDone      time.Time  // When the DNS lookup finished
+	connectStart time.Time  // When dialing started
+	connectDone  time.Time  // When dialing finished
+	tlsStart     time.Time  // When the TLS handshake started
+	tlsDone      time.Time  // When the TLS handshake finished
+	gotConn      time.Time  // When the connection was obtained
+	wroteRequest time.Time  // When the request was written
+	firstByte    time.Time  // When the first byte of the response was read
+	mu           sync.Mutex // Hooks may be called concurrently e.g. dialing more than one address
+}
+
+// trace returns the [httptrace.ClientTrace] recording the times in t.
+func (t *timer) trace() *httptrace.ClientTrace {
+	record := func(at *time.Time) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		*at = time.Now()
+	}
+
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { record(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { record(&t.dnsDone) },
+		ConnectStart:         func(string, string) { record(&t.connectStart) },
+		ConnectDone:          func(string, string, error) { record(&t.connectDone) },
+		TLSHandshakeStart:    func() { record(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { record(&t.tlsDone) },
+		GotConn:              func(httptrace.GotConnInfo) { record(&t.gotConn) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { record(&t.wroteRequest) },
+		GotFirstResponseByte: func() { record(&t.firstByte) },
+	}
+}
+
+// timings returns how long each phase of the request took, given when the response
+// had been read.
+func (t *timer) timings(done time.Time) Timings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// between returns the time from start to end, or zero if either didn't happen
+	between := func(start, end time.Time) time.Duration {
+		if start.IsZero() || end.IsZero() || end.Before(start) {
+			return 0
+		}
+
+		return end.Sub(start)
+	}
+
+	timings := Timings{
+		DNS:     between(t.dnsStart, t.dnsDone),
+		Connect: between(t.connectStart, latest(t.connectDone, t.tlsDone)),
+		TLS:     between(t.tlsStart, t.tlsDone),
+		Send:    between(t.gotConn, t.wroteRequest),
+		Wait:    between(t.wroteRequest, t.firstByte),
+		Receive: between(t.firstByte, done),
+	}
+
+	// Whatever isn't accounted for before getting the connection was spent waiting for it
+	timings.Blocked = max(between(t.start, t.gotConn)-timings.DNS-timings.Connect, 0)
+
+	return timings
+}
+
+// latest returns the later of a and b.
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
 }
@@ -0,0 +1,147 @@
+package zap
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"go.followtheprocess.codes/log"
+	"go.followtheprocess.codes/zap/internal/format"
+)
+
+// ImportOptions are the flags passed to the import subcommand.
+type ImportOptions struct {
+	// File is the name of the file to import.
+	File string
+
+	// From is the format of the file being imported e.g. postman.
+	From string
+
+	// Split, if set, is the directory in which to write a .http file for each tag in an
+	// OpenAPI document, rather than writing a single file to stdout.
+	Split string
+
+	// Dedupe imports only the first request for each static asset in a HAR archive
+	// e.g. scripts and images.
+	Dedupe bool
+
+	// Debug controls debug logging.
+	Debug bool
+}
+
+// Validate reports whether the ImportOptions is valid, returning a non-nil
+// error if it's not.
+func (i ImportOptions) Validate() error {
+	allowed := []string{formatJSON, formatYAML, formatTOML, formatCurl, formatPostman, formatHAR, formatOpenAPI}
+	if !slices.Contains(allowed, i.From) {
+		return fmt.Errorf("invalid option for --from, expected one of (%s)", strings.Join(allowed, ", "))
+	}
+
+	if i.Split != "" && i.From != formatOpenAPI {
+		return fmt.Errorf("--split is only supported with --from %s", formatOpenAPI)
+	}
+
+	return nil
+}
+
+// Import handles the import subcommand, converting the file in r to a .http file written
+// to stdout.
+//
+// Anything in the file that could not be converted is logged as a warning.
+func (z Zap) Import(ctx context.Context, r io.Reader, options ImportOptions) error {
+	logger := z.logger.Prefixed("import").With(slog.String("file", options.File))
+
+	logger.Debug("Import configuration", slog.String("options", fmt.Sprintf("%+v", options)))
+
+	if err := options.Validate(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+
+	warn := func(msg string) {
+		logger.Warn(msg)
+	}
+
+	if options.Split != "" {
+		return z.importByTag(logger, r, options.Split, format.OpenAPIImporter{Warn: warn})
+	}
+
+	importer, ok := importerFor(options, warn)
+	if !ok {
+		return fmt.Errorf("no importer for format %s", options.From)
+	}
+
+	file, err := importer.Import(r)
+	if err != nil {
+		return fmt.Errorf("could not import %s: %w", options.File, err)
+	}
+
+	logger.Debug(
+		"Imported file successfully",
+		slog.Int("requests", len(file.Requests)),
+		slog.Duration("took", time.Since(start)),
+	)
+
+	_, err = io.WriteString(z.stdout, file.String())
+
+	return err
+}
+
+// importByTag imports the OpenAPI document in r, writing a .http file for each of its tags
+// to dir.
+func (z Zap) importByTag(logger *log.Logger, r io.Reader, dir string, importer format.OpenAPIImporter) error {
+	files, err := importer.ImportByTag(r)
+	if err != nil {
+		return fmt.Errorf("could not import OpenAPI document: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, defaultDirPermissions); err != nil {
+		return fmt.Errorf("could not create directory for imported files: %w", err)
+	}
+
+	for _, tag := range slices.Sorted(maps.Keys(files)) {
+		file := files[tag]
+		path := filepath.Join(dir, file.Name+".http")
+
+		if err := os.WriteFile(path, []byte(file.String()), defaultFilePermissions); err != nil {
+			return fmt.Errorf("could not write imported file: %w", err)
+		}
+
+		logger.Info("Imported tag", slog.String("tag", tag), slog.String("path", path), slog.Int("requests", len(file.Requests)))
+	}
+
+	return nil
+}
+
+// importerFor returns the [format.Importer] for the import format in options, and whether
+// there is one.
+//
+// Importers that may be unable to convert everything call warn with what they left out.
+func importerFor(options ImportOptions, warn func(msg string)) (format.Importer, bool) {
+	switch options.From {
+	case formatJSON:
+		return format.JSONImporter{}, true
+	case formatYAML:
+		return format.YAMLImporter{}, true
+	case formatTOML:
+		return format.TOMLImporter{}, true
+	case formatCurl:
+		return format.CurlImporter{Warn: warn}, true
+	case formatPostman:
+		return format.PostmanImporter{Warn: warn}, true
+	case formatHAR:
+		return format.HARImporter{Warn: warn, Dedupe: options.Dedupe}, true
+	case formatOpenAPI:
+		return format.OpenAPIImporter{Warn: warn}, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,242 @@
+package zap
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"charm.land/huh/v2"
+	"go.followtheprocess.codes/zap/internal/dotenv"
+	"go.followtheprocess.codes/zap/internal/spec"
+	"go.followtheprocess.codes/zap/internal/syntax/resolver"
+)
+
+// redacted replaces the values of secret prompts in logs.
+const redacted = "********"
+
+// answers are the answers to prompts given up front with '--prompt' or '--prompt-file'
+// rather than interactively, by prompt name.
+//
+// A request's prompts may be answered specifically with '<request>.<prompt>', which
+// takes precedence over an answer to any prompt of that name.
+type answers struct {
+	// values are the answers by prompt name.
+	values map[string]string
+
+	// redactSecrets answers secret prompts with a template variable of the same name
+	// rather than asking for them, so their values are never written out e.g. when
+	// exporting.
+	redactSecrets bool
+}
+
+// loadAnswers gathers the prompt answers given on the command line from a --prompt-file
+// and --prompt 'name=value' pairs, the latter taking precedence.
+//
+// A file of "-" means read the answers from stdin, in dotenv format.
+func loadAnswers(prompts []string, file string, stdin io.Reader) (answers, error) {
+	if file != "-" {
+		var files []string
+		if file != "" {
+			files = []string{file}
+		}
+
+		loaded, err := loadVars(prompts, files)
+		if err != nil {
+			return answers{}, fmt.Errorf("could not load prompt answers: %w", err)
+		}
+
+		return answers{values: loaded}, nil
+	}
+
+	contents, err := io.ReadAll(stdin)
+	if err != nil {
+		return answers{}, fmt.Errorf("could not read prompt answers from stdin: %w", err)
+	}
+
+	fromStdin, err := dotenv.Parse(contents)
+	if err != nil {
+		return answers{}, fmt.Errorf("could not load prompt answers from stdin: %w", err)
+	}
+
+	loaded, err := loadVars(prompts, nil)
+	if err != nil {
+		return answers{}, fmt.Errorf("could not load prompt answers: %w", err)
+	}
+
+	maps.Copy(fromStdin, loaded)
+
+	return answers{values: fromStdin}, nil
+}
+
+// lookup returns the answer to the prompt called name, on the request called request
+// or in the global scope if request is empty.
+func (a answers) lookup(request, name string) (string, bool) {
+	if request != "" {
+		if answer, ok := a.values[request+"."+name]; ok {
+			return answer, true
+		}
+	}
+
+	answer, ok := a.values[name]
+
+	return answer, ok
+}
+
+// answered reports whether prompt can be answered without asking the user, either
+// because it was answered up front, it has a default or it is a secret being redacted.
+func (a answers) answered(request string, prompt spec.Prompt) bool {
+	if prompt.Default != "" || (prompt.Secret && a.redactSecrets) {
+		return true
+	}
+
+	_, ok := a.lookup(request, prompt.Name)
+
+	return ok
+}
+
+// ask returns the answer to a prompt, taken from answers if given up front or otherwise
+// by asking the user interactively.
+//
+// request is the name of the request the prompt belongs to, empty for global prompts.
+//
+// Prompts with choices are asked as a selection, secret prompts mask their input and a
+// default is used as the initial value, or as the answer if stdin is not a terminal.
+func (z Zap) ask(answers answers, request string, prompt spec.Prompt) (string, error) {
+	if prompt.Secret && answers.redactSecrets {
+		return "{{ " + prompt.Name + " }}", nil
+	}
+
+	if answer, ok := answers.lookup(request, prompt.Name); ok {
+		if err := resolver.ValidatePrompt(prompt, answer); err != nil {
+			return "", fmt.Errorf("invalid answer to prompt %s: %w", prompt.Name, err)
+		}
+
+		return answer, nil
+	}
+
+	if prompt.Default != "" && !z.interactive {
+		return prompt.Default, nil
+	}
+
+	title := prompt.Name
+	if request != "" {
+		title = fmt.Sprintf("(%s) %s", request, prompt.Name)
+	}
+
+	value := prompt.Default
+
+	validate := func(value string) error {
+		return resolver.ValidatePrompt(prompt, value)
+	}
+
+	var field huh.Field
+
+	if len(prompt.Choices) != 0 {
+		field = huh.NewSelect[string]().
+			Title(title).
+			Description(prompt.Description).
+			Options(huh.NewOptions(prompt.Choices...)...).
+			Validate(validate).
+			Value(&value)
+	} else {
+		input := huh.NewInput().
+			Title(title).
+			Description(prompt.Description).
+			Validate(validate).
+			Value(&value)
+
+		if prompt.Secret {
+			input = input.EchoMode(huh.EchoModePassword)
+		}
+
+		field = input
+	}
+
+	err := huh.NewForm(huh.NewGroup(field)).
+		WithTheme(huh.ThemeFunc(huh.ThemeCatppuccin)).
+		WithInput(z.stdin).
+		WithOutput(z.stderr).
+		Run()
+	if err != nil {
+		return "", fmt.Errorf("failed to prompt user for %s: %w", prompt.Name, err)
+	}
+
+	return value, nil
+}
+
+// checkPrompts ensures every prompt that will be asked can be, returning an error listing
+// those without an answer if stdin is not an interactive terminal.
+//
+// Without this, prompting from e.g. a CI job would hang or fail part way through a run.
+func (z Zap) checkPrompts(answers answers, globals map[string]spec.Prompt, requests []spec.Request) error {
+	if z.interactive {
+		return nil
+	}
+
+	var unanswered []string
+
+	for _, name := range slices.Sorted(maps.Keys(globals)) {
+		if !answers.answered("", globals[name]) {
+			unanswered = append(unanswered, name)
+		}
+	}
+
+	for _, request := range requests {
+		for _, name := range slices.Sorted(maps.Keys(request.Prompts)) {
+			if !answers.answered(request.Name, request.Prompts[name]) {
+				unanswered = append(unanswered, request.Name+"."+name)
+			}
+		}
+	}
+
+	if len(unanswered) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"stdin is not a terminal so prompts cannot be asked, answer them with --prompt name=value or --prompt-file: %s",
+		strings.Join(unanswered, ", "),
+	)
+}
+
+// redactor returns a replacer masking the values of the secret prompts in prompts, for
+// logging things that may contain them.
+func redactor(prompts ...map[string]spec.Prompt) *strings.Replacer {
+	var pairs []string
+
+	for _, scope := range prompts {
+		for _, prompt := range scope {
+			if prompt.Secret && prompt.Value != "" {
+				pairs = append(pairs, prompt.Value, redacted)
+			}
+		}
+	}
+
+	return strings.NewReplacer(pairs...)
+}
+
+// redactAnswers returns the 'name=value' answers to prompts given with '--prompt' with
+// every value redacted, which prompts are secret isn't known until the file is parsed.
+func redactAnswers(prompts []string) []string {
+	logged := make([]string, 0, len(prompts))
+
+	for _, prompt := range prompts {
+		name, _, _ := strings.Cut(prompt, "=")
+		logged = append(logged, name+"="+redacted)
+	}
+
+	return logged
+}
+
+// promptValues returns the answered values of prompts by name.
+func promptValues(prompts map[string]spec.Prompt) map[string]string {
+	values := make(map[string]string, len(prompts))
+
+	for name, prompt := range prompts {
+		values[name] = prompt.Value
+	}
+
+	return values
+}
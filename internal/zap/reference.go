@@ -0,0 +1,197 @@
+package zap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/textproto"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.followtheprocess.codes/diff"
+	"go.followtheprocess.codes/diff/render"
+	"go.followtheprocess.codes/zap/internal/jsonpath"
+)
+
+// ansiEscape matches the ANSI escape sequences that colour text in a terminal.
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// responsePrefix is the prefix of a response reference that records the status line
+// and headers as well as the body.
+const responsePrefix = "HTTP/"
+
+// compareReference compares a live response against the contents of its response reference,
+// returning a plain rendered diff of the differences or "" if they match.
+//
+// References usually contain only the response body but may also record the status line
+// and headers in the form of a raw HTTP response, in which case those are compared too:
+//
+//	HTTP/1.1 200 OK
+//	Content-Type: application/json
+//
+//	{"stuff": "here"}
+//
+// JSON bodies are compared structurally so formatting and key order don't matter, any
+// values matched by the ignore JSONPath expressions are removed from both sides before
+// comparing. Headers named in ignoreHeaders are not compared.
+func compareReference(reference []byte, response Response, ignore, ignoreHeaders []string) (string, error) {
+	var recorded Response
+
+	headers := bytes.HasPrefix(reference, []byte(responsePrefix))
+	body := reference
+
+	if headers {
+		var err error
+
+		recorded, err = parseReference(reference)
+		if err != nil {
+			return "", fmt.Errorf("could not parse response reference: %w", err)
+		}
+
+		body = recorded.Body
+	}
+
+	want, got, err := normaliseBodies(body, response.Body, ignore)
+	if err != nil {
+		return "", err
+	}
+
+	if headers {
+		// Only the status is compared, not the protocol version it was sent over
+		want = formatResponse(Response{
+			Proto:  recorded.Proto,
+			Status: recorded.Status,
+			Header: withoutHeaders(recorded.Header, ignoreHeaders),
+			Body:   want,
+		})
+		got = formatResponse(Response{
+			Proto:  recorded.Proto,
+			Status: response.Status,
+			Header: withoutHeaders(response.Header, ignoreHeaders),
+			Body:   got,
+		})
+	}
+
+	changes := diff.New("reference", want, "response", got)
+	if changes.Equal() {
+		return "", nil
+	}
+
+	// The renderer colours the diff if the terminal supports it, but it also goes in test
+	// reports where escape sequences are noise (or in JUnit XML, invalid) so it's kept
+	// plain here and only coloured when it's shown
+	return ansiEscape.ReplaceAllString(string(render.Render(changes)), ""), nil
+}
+
+// parseReference parses a response reference that records the status line and headers.
+//
+// The body is taken as everything after the blank line following the headers rather
+// than honouring Content-Length, so the reference may be edited freely.
+func parseReference(reference []byte) (Response, error) {
+	reader := textproto.NewReader(bufio.NewReader(bytes.NewReader(reference)))
+
+	line, err := reader.ReadLine()
+	if err != nil {
+		return Response{}, err
+	}
+
+	proto, status, _ := strings.Cut(line, " ")
+	code, _, _ := strings.Cut(status, " ")
+
+	statusCode, err := strconv.Atoi(code)
+	if err != nil {
+		return Response{}, fmt.Errorf("malformed status line %q", line)
+	}
+
+	header, err := reader.ReadMIMEHeader()
+	if err != nil {
+		return Response{}, fmt.Errorf("malformed headers: %w", err)
+	}
+
+	body, err := io.ReadAll(reader.R)
+	if err != nil {
+		return Response{}, err
+	}
+
+	recorded := Response{
+		Proto:      proto,
+		Status:     status,
+		StatusCode: statusCode,
+		Header:     http.Header(header),
+		Body:       body,
+	}
+
+	return recorded, nil
+}
+
+// withoutHeaders returns a copy of header without any of those named in ignore.
+func withoutHeaders(header http.Header, ignore []string) http.Header {
+	kept := header.Clone()
+
+	for _, name := range ignore {
+		kept.Del(name)
+	}
+
+	return kept
+}
+
+// normaliseBodies returns the reference and live response bodies in a form that may be
+// compared line by line.
+//
+// If both are JSON, they are re-encoded with sorted keys and consistent indentation after
+// removing any ignored values, otherwise they are returned as they are with a trailing newline.
+func normaliseBodies(want, got []byte, ignore []string) (wantBody, gotBody []byte, err error) {
+	var wantDoc, gotDoc any
+
+	if json.Unmarshal(want, &wantDoc) != nil || json.Unmarshal(got, &gotDoc) != nil {
+		// Response files are written with a trailing newline so compare like for like
+		return fixNL(want), fixNL(got), nil
+	}
+
+	for _, expr := range ignore {
+		path, err := jsonpath.Parse(expr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("bad ignore expression: %w", err)
+		}
+
+		wantDoc = path.Delete(wantDoc)
+		gotDoc = path.Delete(gotDoc)
+	}
+
+	wantBody, err = json.MarshalIndent(wantDoc, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gotBody, err = json.MarshalIndent(gotDoc, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return fixNL(wantBody), fixNL(gotBody), nil
+}
+
+// formatResponse formats a response as a raw HTTP response, the format used by
+// response references that record the status line and headers.
+func formatResponse(response Response) []byte {
+	buf := &bytes.Buffer{}
+
+	fmt.Fprintf(buf, "%s %s\n", response.Proto, response.Status)
+
+	for _, key := range slices.Sorted(maps.Keys(response.Header)) {
+		for _, value := range response.Header[key] {
+			fmt.Fprintf(buf, "%s: %s\n", key, value)
+		}
+	}
+
+	buf.WriteByte('\n')
+	buf.Write(response.Body)
+
+	return buf.Bytes()
+}
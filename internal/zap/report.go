@@ -0,0 +1,371 @@
+package zap
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	reporterJUnit = "junit"
+	reporterTAP   = "tap"
+	reporterJSON  = "json"
+)
+
+// testSuite is the results of all the tests in a single .http file.
+type testSuite struct {
+	file    string       // Path to the .http file
+	results []testResult // The results of each test in the file, in order
+}
+
+// duration returns the total duration of all the tests in the suite.
+func (t testSuite) duration() time.Duration {
+	var total time.Duration
+	for _, result := range t.results {
+		total += result.response.Duration
+	}
+
+	return total
+}
+
+// failed returns the number of tests in the suite that failed.
+func (t testSuite) failed() int {
+	failed := 0
+
+	for _, result := range t.results {
+		if !result.passed() {
+			failed++
+		}
+	}
+
+	return failed
+}
+
+// groupSuites groups test results by the file they came from, preserving order.
+func groupSuites(results []testResult) []testSuite {
+	var suites []testSuite
+
+	for _, result := range results {
+		if len(suites) == 0 || suites[len(suites)-1].file != result.file {
+			suites = append(suites, testSuite{file: result.file})
+		}
+
+		suites[len(suites)-1].results = append(suites[len(suites)-1].results, result)
+	}
+
+	return suites
+}
+
+// summary returns a short, single line description of why the test failed, or
+// "" if it passed.
+func (t testResult) summary() string {
+	if t.err != nil {
+		return t.err.Error()
+	}
+
+	var reasons []string
+
+	switch len(t.failures) {
+	case 0:
+		// Nothing to report
+	case 1:
+		reasons = append(reasons, "1 assertion failed")
+	default:
+		reasons = append(reasons, fmt.Sprintf("%d assertions failed", len(t.failures)))
+	}
+
+	if t.diff != "" {
+		reasons = append(reasons, "response does not match reference "+t.request.ResponseRef.String())
+	}
+
+	return strings.Join(reasons, ", ")
+}
+
+// details returns the full description of why the test failed including each failed
+// assertion and the reference diff, or "" if it passed.
+func (t testResult) details() string {
+	if t.err != nil {
+		return t.err.Error()
+	}
+
+	builder := &strings.Builder{}
+
+	for _, fail := range t.failures {
+		builder.WriteString(fail.String() + "\n")
+	}
+
+	if t.diff != "" {
+		if len(t.failures) != 0 {
+			builder.WriteString("\n")
+		}
+
+		builder.WriteString("response does not match reference " + t.request.ResponseRef.String() + "\n\n")
+		builder.WriteString(t.diff)
+	}
+
+	return builder.String()
+}
+
+// writeReport writes the test results to w in the machine readable format
+// given by reporter.
+func writeReport(w io.Writer, reporter string, results []testResult) error {
+	suites := groupSuites(results)
+
+	switch reporter {
+	case reporterJUnit:
+		return writeJUnit(w, suites)
+	case reporterTAP:
+		return writeTAP(w, suites)
+	case reporterJSON:
+		return writeJSONReport(w, suites)
+	default:
+		return fmt.Errorf("unknown reporter %q", reporter)
+	}
+}
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+}
+
+// junitTestSuite is a single .http file in a JUnit XML report.
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+}
+
+// junitTestCase is a single request in a JUnit XML report.
+type junitTestCase struct {
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+}
+
+// junitProblem is a test failure or error in a JUnit XML report.
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",cdata"`
+}
+
+// writeJUnit writes the test suites to w as JUnit XML.
+//
+// Requests that could not be executed are reported as errors, those that ran
+// but failed their assertions or reference comparison as failures.
+func writeJUnit(w io.Writer, suites []testSuite) error {
+	var total time.Duration
+
+	report := junitTestSuites{Name: "zap test"}
+
+	for _, suite := range suites {
+		junitSuite := junitTestSuite{
+			Name:  suite.file,
+			Time:  seconds(suite.duration()),
+			Tests: len(suite.results),
+		}
+
+		for _, result := range suite.results {
+			testCase := junitTestCase{
+				Name:      result.request.Name,
+				Classname: suite.file,
+				Time:      seconds(result.response.Duration),
+			}
+
+			problem := &junitProblem{Message: result.summary(), Text: result.details()}
+
+			switch {
+			case result.err != nil:
+				testCase.Error = problem
+				junitSuite.Errors++
+			case !result.passed():
+				testCase.Failure = problem
+				junitSuite.Failures++
+			}
+
+			junitSuite.Cases = append(junitSuite.Cases, testCase)
+		}
+
+		report.Tests += junitSuite.Tests
+		report.Failures += junitSuite.Failures
+		report.Errors += junitSuite.Errors
+		report.Suites = append(report.Suites, junitSuite)
+
+		total += suite.duration()
+	}
+
+	report.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("could not encode JUnit report: %w", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+// writeTAP writes the test suites to w in the Test Anything Protocol (version 14) format.
+//
+// Each .http file is a subtest containing a test point per request, with the duration
+// and any failure details in a YAML diagnostic block.
+func writeTAP(w io.Writer, suites []testSuite) error {
+	builder := &strings.Builder{}
+	builder.WriteString("TAP version 14\n")
+
+	for i, suite := range suites {
+		fmt.Fprintf(builder, "# Subtest: %s\n", suite.file)
+		fmt.Fprintf(builder, "    1..%d\n", len(suite.results))
+
+		for j, result := range suite.results {
+			fmt.Fprintf(builder, "    %s %d - %s\n", tapStatus(result.passed()), j+1, result.request.Name)
+			builder.WriteString("      ---\n")
+			fmt.Fprintf(builder, "      duration_ms: %s\n", millis(result.response.Duration))
+
+			if details := result.details(); details != "" {
+				fmt.Fprintf(builder, "      message: %q\n", result.summary())
+				builder.WriteString("      details: |\n")
+
+				for line := range strings.Lines(details) {
+					if line != "\n" {
+						builder.WriteString("        ")
+					}
+
+					builder.WriteString(line)
+				}
+
+				if !strings.HasSuffix(details, "\n") {
+					builder.WriteString("\n")
+				}
+			}
+
+			builder.WriteString("      ...\n")
+		}
+
+		fmt.Fprintf(builder, "%s %d - %s\n", tapStatus(suite.failed() == 0), i+1, suite.file)
+	}
+
+	fmt.Fprintf(builder, "1..%d\n", len(suites))
+
+	_, err := io.WriteString(w, builder.String())
+
+	return err
+}
+
+// tapStatus returns the TAP test point status for a passed or failed test.
+func tapStatus(passed bool) string {
+	if passed {
+		return "ok"
+	}
+
+	return "not ok"
+}
+
+// jsonReport is the root of a JSON test report.
+type jsonReport struct {
+	Suites   []jsonSuite   `json:"suites"`
+	Tests    int           `json:"tests"`
+	Passed   int           `json:"passed"`
+	Failed   int           `json:"failed"`
+	Duration time.Duration `json:"duration"`
+}
+
+// jsonSuite is a single .http file in a JSON test report.
+type jsonSuite struct {
+	File     string        `json:"file"`
+	Tests    []jsonTest    `json:"tests"`
+	Duration time.Duration `json:"duration"`
+}
+
+// jsonTest is a single request in a JSON test report.
+type jsonTest struct {
+	Name       string        `json:"name"`
+	Error      string        `json:"error,omitempty"`
+	Diff       string        `json:"diff,omitempty"`
+	Failures   []string      `json:"failures,omitempty"`
+	Duration   time.Duration `json:"duration"`
+	StatusCode int           `json:"statusCode,omitempty"`
+	Passed     bool          `json:"passed"`
+}
+
+// writeJSONReport writes the test suites to w as JSON.
+//
+// Durations are in nanoseconds, like the durations in 'zap export --format json'.
+func writeJSONReport(w io.Writer, suites []testSuite) error {
+	var report jsonReport
+
+	for _, suite := range suites {
+		jsonSuite := jsonSuite{
+			File:     suite.file,
+			Tests:    make([]jsonTest, 0, len(suite.results)),
+			Duration: suite.duration(),
+		}
+
+		for _, result := range suite.results {
+			test := jsonTest{
+				Name:       result.request.Name,
+				Diff:       result.diff,
+				Duration:   result.response.Duration,
+				StatusCode: result.response.StatusCode,
+				Passed:     result.passed(),
+			}
+
+			if result.err != nil {
+				test.Error = result.err.Error()
+			}
+
+			for _, fail := range result.failures {
+				test.Failures = append(test.Failures, fail.String())
+			}
+
+			jsonSuite.Tests = append(jsonSuite.Tests, test)
+		}
+
+		report.Tests += len(suite.results)
+		report.Failed += suite.failed()
+		report.Duration += jsonSuite.Duration
+		report.Suites = append(report.Suites, jsonSuite)
+	}
+
+	report.Passed = report.Tests - report.Failed
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("could not encode JSON report: %w", err)
+	}
+
+	return nil
+}
+
+// seconds formats a duration as fractional seconds, as used by JUnit.
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// millis formats a duration as fractional milliseconds, as used by TAP.
+func millis(d time.Duration) string {
+	return fmt.Sprintf("%.3f", float64(d)/float64(time.Millisecond))
+}
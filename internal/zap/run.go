@@ -2,23 +2,27 @@ package zap
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"maps"
 	"net/http"
+	"net/http/httptrace"
 	"os"
 	"path/filepath"
 	"slices"
 	"strings"
 	"time"
 
-	"charm.land/huh/v2"
 	"go.followtheprocess.codes/hue"
 	"go.followtheprocess.codes/log"
+	"go.followtheprocess.codes/zap/internal/format"
 	"go.followtheprocess.codes/zap/internal/spec"
 	"go.followtheprocess.codes/zap/internal/syntax/resolver"
+	"go.followtheprocess.codes/zap/internal/syntax/resolver/builtins"
+	"go.yaml.in/yaml/v4"
 )
 
 // Styles.
@@ -37,11 +41,18 @@ const (
 	// failure is the style used to render failed HTTP response status lines.
 	failure = hue.Red | hue.Bold
 
+	// updated is the style used to render response references that have been
+	// overwritten by 'zap test --update'.
+	updated = hue.Yellow | hue.Bold
+
 	// sepWidth is the width in characters of the horizontal line separator
 	// between HTTP responses.
 	sepWidth = 80
 )
 
+// yamlIndent is the indent used when showing responses as YAML.
+const yamlIndent = 2
+
 const (
 	defaultFilePermissions = 0o644 // Default permissions for writing files, same as unix touch
 	defaultDirPermissions  = 0o755 // Default permissions for creating directories, same as unix mkdir
@@ -54,7 +65,7 @@ type RunOptions struct {
 
 	// Output is the output format in which to display the HTTP response.
 	//
-	// Allowed values: 'stdout', 'json', 'yaml'.
+	// Allowed values: 'stdout', 'json', 'yaml', 'har'.
 	Output string
 
 	// Requests are the names of specific requests to be run.
@@ -63,6 +74,30 @@ type RunOptions struct {
 	// Mutually exclusive with Filter and Pattern.
 	Requests []string
 
+	// Vars are variables given as 'key=value' pairs, overriding the file's globals.
+	Vars []string
+
+	// VarFiles are dotenv, JSON or YAML files of variables, overriding the file's
+	// globals. Vars take precedence over them.
+	VarFiles []string
+
+	// Prompts are answers to prompts given as 'name=value' pairs, so they
+	// aren't asked interactively.
+	Prompts []string
+
+	// PromptFile is a dotenv, JSON or YAML file of answers to prompts, or "-" to
+	// read them from stdin. Prompts take precedence over it.
+	PromptFile string
+
+	// Environment is the name of the environment to select from the environment
+	// files ('http-client.env.json' and 'http-client.private.env.json') nearest
+	// the http file. Empty means no environment.
+	Environment string
+
+	// Seed seeds the random builtins e.g. '$uuid' and '$random.email' so their
+	// values are reproducible between runs. Zero means a random seed.
+	Seed uint64
+
 	// Timeout is the overall per-request timeout.
 	Timeout time.Duration
 
@@ -89,10 +124,10 @@ type RunOptions struct {
 // nil means the options are valid.
 func (r RunOptions) Validate() error {
 	switch output := r.Output; output {
-	case "stdout", "json", "yaml":
+	case "stdout", "json", "yaml", "har":
 		// Nothing, these are fine
 	default:
-		return fmt.Errorf("invalid option for --output %q, allowed values are 'stdout', 'json', 'yaml'", output)
+		return fmt.Errorf("invalid option for --output %q, allowed values are 'stdout', 'json', 'yaml', 'har'", output)
 	}
 
 	switch {
@@ -138,15 +173,31 @@ func (z Zap) Run(ctx context.Context, r io.Reader, options RunOptions) error {
 		)
 	}
 
-	logger.Debug("Run configuration", slog.String("options", fmt.Sprintf("%+v", options)))
+	// Answers to prompts may be secret, only log which prompts they answer
+	logged := options
+	logged.Prompts = redactAnswers(options.Prompts)
+
+	logger.Debug("Run configuration", slog.String("options", fmt.Sprintf("%+v", logged)))
 
 	start := time.Now()
 
-	httpFile, err := z.parseFile(options.File, r)
+	parse, err := newParseOptions(options.Vars, options.VarFiles, options.Environment, options.Seed)
 	if err != nil {
 		return err
 	}
 
+	answers, err := loadAnswers(options.Prompts, options.PromptFile, z.stdin)
+	if err != nil {
+		return err
+	}
+
+	httpFile, err := z.parseFile(options.File, r, parse)
+	if err != nil {
+		return err
+	}
+
+	warnUnusedVars(logger, parse)
+
 	logger.Debug(
 		"Parsed file successfully",
 		slog.String("file", options.File),
@@ -155,11 +206,6 @@ func (z Zap) Run(ctx context.Context, r io.Reader, options RunOptions) error {
 
 	client := NewHTTPClient(httpFile)
 
-	httpFile, err = z.evaluateGlobalPrompts(logger, httpFile)
-	if err != nil {
-		return fmt.Errorf("could not evaluate global prompts: %w", err)
-	}
-
 	var toExecute []spec.Request
 
 	if len(options.Requests) == 0 {
@@ -180,45 +226,115 @@ func (z Zap) Run(ctx context.Context, r io.Reader, options RunOptions) error {
 
 	logger.Debug("Filtered requests to execute", slog.Int("count", len(toExecute)))
 
-	toExecute, err = z.evaluateRequestPrompts(logger, toExecute, httpFile.Prompts)
+	// Requests referenced by those selected have to be executed first, even if
+	// they weren't asked for, but their responses aren't shown
+	selected := make(map[string]bool, len(toExecute))
+	for _, request := range toExecute {
+		selected[request.Name] = true
+	}
+
+	toExecute, err = executionOrder(httpFile.Requests, toExecute)
+	if err != nil {
+		return err
+	}
+
+	// Make sure every prompt can be answered before asking any of them
+	if err = z.checkPrompts(answers, httpFile.Prompts, toExecute); err != nil {
+		return err
+	}
+
+	httpFile, err = z.evaluateGlobalPrompts(logger, httpFile, answers)
+	if err != nil {
+		return fmt.Errorf("could not evaluate global prompts: %w", err)
+	}
+
+	toExecute, err = z.evaluateRequestPrompts(logger, toExecute, answers)
 	if err != nil {
 		return fmt.Errorf("could not evaluate request prompts: %w", err)
 	}
 
+	base := filepath.Dir(options.File)
+	exchanges := make(map[string]exchange, len(toExecute))
+	captured := make(map[string]string)
+
+	// With --output har, the requests and their responses are written as one archive at the end
+	var archived []spec.Request
+
 	for _, request := range toExecute {
+		scope := requestScope(httpFile, request, exchanges, captured, base, parse.library)
+
+		evaluated, err := evaluateRequest(request, scope)
+		if err != nil {
+			return fmt.Errorf("request %s: %w", request.Name, err)
+		}
+
+		request = evaluated
+		redact := redactor(httpFile.Prompts, request.Prompts)
+
 		logger.Debug(
 			"Executing request",
 			slog.String("request", request.Name),
 			slog.String("method", request.Method),
-			slog.String("url", request.URL),
+			slog.String("url", redact.Replace(request.URL.String())),
+			slog.Bool("dependency", !selected[request.Name]),
 		)
 
-		response, err := z.doRequest(ctx, logger, client, request)
+		response, err := z.doRequest(ctx, logger, client, base, request, redact)
 		if err != nil {
 			return err
 		}
 
-		base := filepath.Dir(options.File)
+		exchanges[request.Name] = exchange{request: request, response: response}
 
-		if request.ResponseFile != "" {
-			err := z.writeResponseFile(logger, base, request.ResponseFile, response.Body)
+		captures, err := captureValues(request, response)
+		if err != nil {
+			return fmt.Errorf("request %s: %w", request.Name, err)
+		}
+
+		maps.Copy(captured, captures)
+
+		if len(request.ResponseFile) != 0 {
+			err := z.writeResponseFile(logger, base, request.ResponseFile.String(), response.Body)
 			if err != nil {
 				return err
 			}
 		}
 
-		z.showResponse(options.File, request, response, options.Verbose)
+		if !selected[request.Name] {
+			continue
+		}
+
+		switch options.Output {
+		case formatJSON, formatYAML:
+			if err := z.showRecord(options.Output, request, response, captures); err != nil {
+				return err
+			}
+		case formatHAR:
+			archived = append(archived, request)
+		default:
+			z.showResponse(options.File, request, response, options.Verbose)
+		}
+	}
+
+	if options.Output == formatHAR {
+		return z.showArchive(options.File, archived, exchanges)
 	}
 
 	return nil
 }
 
 // doRequest executes a single HTTP request.
+//
+// dir is the directory containing the .http file, relative to which any body
+// file is resolved. The values of secret prompts are removed from what is
+// logged with redact.
 func (z Zap) doRequest(
 	ctx context.Context,
 	logger *log.Logger,
 	client http.Client,
+	dir string,
 	request spec.Request,
+	redact *strings.Replacer,
 ) (Response, error) {
 	timeout := DefaultTimeout
 	if request.Timeout != 0 {
@@ -236,15 +352,31 @@ func (z Zap) doRequest(
 		}
 	}
 
-	req, err := http.NewRequestWithContext(ctx, request.Method, request.URL, strings.NewReader(request.Body))
+	timer := &timer{}
+	ctx = httptrace.WithClientTrace(ctx, timer.trace())
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		request.Method,
+		request.URL.String(),
+		strings.NewReader(request.Body.String()),
+	)
 	if err != nil {
 		return Response{}, fmt.Errorf("HTTP request %q is invalid: %w", request.Name, err)
 	}
 
-	req.Header = request.Headers
-	req.Header.Add("User-Agent", "go.followtheprocess.codes/zap "+z.version)
+	if len(request.BodyFile) != 0 {
+		if err := setBodyFile(req, dir, request.BodyFile.String()); err != nil {
+			return Response{}, err
+		}
+	}
+
+	// Added to the request's own headers so it's part of the recorded exchange
+	request.Headers.Add("User-Agent", spec.Text("go.followtheprocess.codes/zap "+z.version))
+	req.Header = request.Headers.Header()
 
 	start := time.Now()
+	timer.start = start
 
 	res, err := client.Do(req)
 	if err != nil {
@@ -260,7 +392,7 @@ func (z Zap) doRequest(
 
 	logger.Debug(
 		"Received HTTP response from URL",
-		slog.String("url", request.URL),
+		slog.String("url", redact.Replace(request.URL.String())),
 		slog.Int("status", res.StatusCode),
 		slog.String("content-type", res.Header.Get("Content-Type")),
 		slog.Duration("duration", duration),
@@ -272,6 +404,7 @@ func (z Zap) doRequest(
 	}
 
 	response := Response{
+		Started:       start,
 		Status:        res.Status,
 		StatusCode:    res.StatusCode,
 		Proto:         res.Proto,
@@ -279,12 +412,142 @@ func (z Zap) doRequest(
 		Body:          body,
 		ContentLength: len(body),
 		Duration:      duration,
+		Timings:       timer.timings(time.Now()),
 	}
 
 	return response, nil
 }
 
-// TODO(@FollowTheProcess): Respect --output
+// setBodyFile sets the body of req to be streamed from the file at path, which is
+// relative to dir, rather than read into memory up front.
+func setBodyFile(req *http.Request, dir, path string) error {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	open := func() (io.ReadCloser, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not open body file: %w", err)
+		}
+
+		return f, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("could not open body file: %w", err)
+	}
+
+	body, err := open()
+	if err != nil {
+		return err
+	}
+
+	// The transport closes the body once sent, GetBody allows it to be re-opened
+	// if the request needs to be sent again e.g. when following a redirect
+	req.Body = body
+	req.GetBody = open
+	req.ContentLength = info.Size()
+
+	return nil
+}
+
+// responseRecord is the structured form of an executed request and its response, shown
+// in place of the user friendly output by 'zap run --output json|yaml'.
+type responseRecord struct {
+	Headers    http.Header       `json:"headers,omitempty"  yaml:"headers,omitempty"`
+	Captures   map[string]string `json:"captures,omitempty" yaml:"captures,omitempty"`
+	Name       string            `json:"name"               yaml:"name"`
+	Method     string            `json:"method"             yaml:"method"`
+	URL        string            `json:"url"                yaml:"url"`
+	Status     string            `json:"status"             yaml:"status"`
+	Proto      string            `json:"proto"              yaml:"proto"`
+	Body       string            `json:"body"               yaml:"body"`
+	StatusCode int               `json:"statusCode"         yaml:"statusCode"`
+	Duration   time.Duration     `json:"duration"           yaml:"duration"`
+}
+
+// showRecord prints a structured record of the request and its response to z.stdout
+// in the given output format.
+//
+// JSON records are written one per line so the output can be streamed into tools like
+// jq, YAML records are written as a stream of documents.
+//
+// captures are the variables captured from the response, if any.
+func (z Zap) showRecord(output string, request spec.Request, response Response, captures map[string]string) error {
+	record := responseRecord{
+		Name:       request.Name,
+		Method:     request.Method,
+		URL:        request.URL.String(),
+		Status:     response.Status,
+		Proto:      response.Proto,
+		Headers:    response.Header,
+		Captures:   captures,
+		Body:       string(response.Body),
+		StatusCode: response.StatusCode,
+		Duration:   response.Duration,
+	}
+
+	switch output {
+	case formatJSON:
+		encoder := json.NewEncoder(z.stdout)
+		encoder.SetEscapeHTML(false)
+
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("could not encode response as JSON: %w", err)
+		}
+	case formatYAML:
+		fmt.Fprintln(z.stdout, "---")
+
+		encoder := yaml.NewEncoder(z.stdout)
+		encoder.SetIndent(yamlIndent)
+
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("could not encode response as YAML: %w", err)
+		}
+
+		if err := encoder.Close(); err != nil {
+			return fmt.Errorf("could not encode response as YAML: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported output format %q", output)
+	}
+
+	return nil
+}
+
+// showArchive prints the requests and their responses in exchanges to z.stdout as a HAR
+// archive, for 'zap run --output har'.
+func (z Zap) showArchive(file string, requests []spec.Request, exchanges map[string]exchange) error {
+	responses := make(map[string]format.HARResponse, len(requests))
+
+	for _, request := range requests {
+		response := exchanges[request.Name].response
+		responses[request.Name] = format.HARResponse{
+			Started:    response.Started,
+			Header:     response.Header,
+			Status:     response.Status,
+			Proto:      response.Proto,
+			Body:       response.Body,
+			StatusCode: response.StatusCode,
+			Timings:    format.HARTimings(response.Timings),
+		}
+	}
+
+	exporter := format.HARExporter{Responses: responses, Version: z.version}
+
+	archive := spec.File{
+		Name:     strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)),
+		Requests: requests,
+	}
+
+	if err := exporter.Export(z.stdout, archive); err != nil {
+		return fmt.Errorf("could not write HAR archive: %w", err)
+	}
+
+	return nil
+}
 
 // showResponse prints the response in a user friendly way to z.stdout.
 func (z Zap) showResponse(file string, request spec.Request, response Response, verbose bool) {
@@ -327,136 +590,62 @@ func (z Zap) showResponse(file string, request spec.Request, response Response,
 }
 
 // evaluateGlobalPrompts asks the user to provide values for prompts defined in the top level
-// of the parsed file and replaces the zap::prompt::<id> placeholders inserted by the parser
-// with the user-provided values.
-func (z Zap) evaluateGlobalPrompts(logger *log.Logger, file spec.File) (spec.File, error) {
+// of the parsed file, storing the answers as the values of the prompts to be used when the
+// interpolations deferred until runtime are evaluated.
+//
+// Prompts with an answer in answers are not asked.
+func (z Zap) evaluateGlobalPrompts(logger *log.Logger, file spec.File, answers answers) (spec.File, error) {
 	logger.Debug("Evaluating global prompts")
 
-	for id, prompt := range file.Prompts {
-		var value string
+	prompts := make(map[string]spec.Prompt, len(file.Prompts))
 
-		err := huh.NewInput().
-			Title(prompt.Name).
-			Description(prompt.Description).
-			Value(&value).
-			WithTheme(huh.ThemeFunc(huh.ThemeCatppuccin)).
-			Run()
+	for _, id := range slices.Sorted(maps.Keys(file.Prompts)) {
+		prompt := file.Prompts[id]
+
+		value, err := z.ask(answers, "", prompt)
 		if err != nil {
-			return spec.File{}, fmt.Errorf("failed to prompt user for %s: %w", prompt.Name, err)
+			return spec.File{}, err
 		}
 
-		file.Prompts[id] = spec.Prompt{
-			Name:        prompt.Name,
-			Description: prompt.Description,
-			Value:       value, // The now answered value
-		}
+		prompt.Value = value // The now answered value
+		prompts[id] = prompt
 	}
 
-	// Go through everywhere that could hold a zap::prompt::global::<id> placeholder and replace it
-	// this is just the global vars in this case
-	for id, prompt := range file.Prompts {
-		replacer := strings.NewReplacer(resolver.PromptPlaceholderGlobal+id, prompt.Value)
-
-		// Global variables
-		for name, variable := range file.Vars {
-			replaced := replacer.Replace(variable)
-			logger.Debug(
-				"Replacing prompted global variable",
-				slog.String("name", name),
-				slog.String("from", variable),
-				slog.String("to", replaced),
-			)
-			file.Vars[name] = replaced
-		}
-	}
+	file.Prompts = prompts
 
 	return file, nil
 }
 
-// evaluateRequestPrompts asks the user to provide values for prompts defined in the particular requests
-// and replaces the zap::prompt::<id> placeholders inserted by the parser with the user-provided values.
+// evaluateRequestPrompts asks the user to provide values for prompts defined in the particular
+// requests, storing the answers as the values of the prompts to be used when the interpolations
+// deferred until runtime are evaluated.
 //
-// This method differs from it's global counterpart in that request variables could reference either
-// prompts defined locally or those in the global scope. To this end, the global prompts are passed in
-// as an argument to this method.
+// Prompts with an answer in answers are not asked.
 func (z Zap) evaluateRequestPrompts(
 	logger *log.Logger,
 	requests []spec.Request,
-	globals map[string]spec.Prompt,
+	answers answers,
 ) ([]spec.Request, error) {
 	evaluated := make([]spec.Request, 0, len(requests))
 
 	for _, request := range requests {
 		logger.Debug("Evaluating request prompts", slog.String("request", request.Name))
 
-		allPrompts := make(map[string]spec.Prompt, len(globals)+len(request.Prompts))
-		maps.Copy(allPrompts, globals) // Copy in the global prompts
+		prompts := make(map[string]spec.Prompt, len(request.Prompts))
 
-		for id, prompt := range request.Prompts {
-			var value string
+		for _, id := range slices.Sorted(maps.Keys(request.Prompts)) {
+			prompt := request.Prompts[id]
 
-			err := huh.NewInput().
-				Title(fmt.Sprintf("(%s) %s", request.Name, id)).
-				Description(prompt.Description).
-				Value(&value).
-				WithTheme(huh.ThemeFunc(huh.ThemeCatppuccin)).
-				Run()
+			value, err := z.ask(answers, request.Name, prompt)
 			if err != nil {
-				return nil, fmt.Errorf("failed to prompt user for %s: %w", prompt.Name, err)
-			}
-
-			allPrompts[id] = spec.Prompt{
-				Name:        prompt.Name,
-				Description: prompt.Description,
-				Value:       value, // The now answered value
-			}
-		}
-
-		// Go through everywhere that could hold a zap::prompt::<id> placeholder and replace it
-		for id, prompt := range allPrompts {
-			replacer := strings.NewReplacer(
-				resolver.PromptPlaceholderLocal+id, prompt.Value,
-				resolver.PromptPlaceholderGlobal+id, prompt.Value,
-			)
-
-			// Request variables
-			for name, variable := range request.Vars {
-				replaced := replacer.Replace(variable)
-				logger.Debug(
-					"Replacing prompted local variable",
-					slog.String("name", name),
-					slog.String("from", variable),
-					slog.String("to", replaced),
-				)
-				request.Vars[name] = replaced
+				return nil, err
 			}
 
-			// The request URL
-			replaced := replacer.Replace(request.URL)
-			logger.Debug(
-				"Replacing prompted request URL",
-				slog.String("from", request.URL),
-				slog.String("to", replaced),
-			)
-			request.URL = replacer.Replace(request.URL)
-
-			// Headers
-			for name, header := range request.Headers {
-				replaced := make([]string, 0, len(header))
-				for _, part := range header {
-					newPart := replacer.Replace(part)
-					logger.Debug(
-						"Replacing prompted request Header",
-						slog.String("from", part),
-						slog.String("to", newPart),
-					)
-					replaced = append(replaced, newPart)
-				}
-
-				request.Headers[name] = replaced
-			}
+			prompt.Value = value // The now answered value
+			prompts[id] = prompt
 		}
 
+		request.Prompts = prompts
 		evaluated = append(evaluated, request)
 	}
 
@@ -465,18 +654,64 @@ func (z Zap) evaluateRequestPrompts(
 
 // evaluateAllPrompts evaluates global and all request prompts in the file, this is primarily used
 // when exporting entire files into 3rd party formats as all variables need to be resolved.
-func (z Zap) evaluateAllPrompts(logger *log.Logger, file spec.File) (spec.File, error) {
-	file, err := z.evaluateGlobalPrompts(logger, file)
+//
+// Secret prompts are not asked, they are replaced with a template variable of the same name
+// e.g. '{{ password }}' so their values never end up in the export. Values only known once
+// a request is executed, e.g. references to other requests, are left as they are written.
+//
+// Dynamic builtins e.g. '$uuid' are evaluated using library, once per request.
+func (z Zap) evaluateAllPrompts(
+	logger *log.Logger,
+	file spec.File,
+	answers answers,
+	library builtins.Library,
+) (spec.File, error) {
+	answers.redactSecrets = true
+
+	if err := z.checkPrompts(answers, file.Prompts, file.Requests); err != nil {
+		return spec.File{}, err
+	}
+
+	file, err := z.evaluateGlobalPrompts(logger, file, answers)
 	if err != nil {
 		return spec.File{}, err
 	}
 
 	// Evaluate all prompts for all requests
-	requests, err := z.evaluateRequestPrompts(logger, file.Requests, file.Prompts)
+	requests, err := z.evaluateRequestPrompts(logger, file.Requests, answers)
 	if err != nil {
 		return spec.File{}, err
 	}
 
+	for i, request := range requests {
+		scope := resolver.Scope{
+			Variables: promptValues(file.Prompts),
+			Templates: templates(file, request),
+			Library:   library,
+			Partial:   true,
+		}
+		maps.Copy(scope.Variables, promptValues(request.Prompts))
+
+		if requests[i], err = evaluateRequest(request, scope); err != nil {
+			return spec.File{}, fmt.Errorf("request %s: %w", request.Name, err)
+		}
+	}
+
+	globals := resolver.Scope{
+		Variables: promptValues(file.Prompts),
+		Templates: file.Vars,
+		Library:   library,
+		Partial:   true,
+	}
+
+	vars := make(map[string]spec.Template, len(file.Vars))
+	for name, value := range file.Vars {
+		if vars[name], err = evaluate(value, globals); err != nil {
+			return spec.File{}, err
+		}
+	}
+
+	file.Vars = vars
 	file.Requests = requests
 
 	return file, nil
@@ -1,11 +1,26 @@
 package zap
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
+
+	"go.followtheprocess.codes/hue"
+	"go.followtheprocess.codes/log"
+	"go.followtheprocess.codes/msg"
+	"go.followtheprocess.codes/zap/internal/spec"
+	"go.followtheprocess.codes/zap/internal/syntax/resolver/builtins"
 )
 
 // TestOptions are the options passed to the test subcommand.
@@ -19,6 +34,29 @@ type TestOptions struct {
 	// Mutually exclusive with Filter and Pattern.
 	Requests []string
 
+	// Vars are variables given as 'key=value' pairs, overriding the file's globals.
+	Vars []string
+
+	// VarFiles are dotenv, JSON or YAML files of variables, overriding the file's
+	// globals. Vars take precedence over them.
+	VarFiles []string
+
+	// Prompts are answers to prompts given as 'name=value' pairs, so they
+	// aren't asked interactively.
+	Prompts []string
+
+	// PromptFile is a dotenv, JSON or YAML file of answers to prompts, or "-" to
+	// read them from stdin. Prompts take precedence over it.
+	PromptFile string
+
+	// Environment is the name of the environment to test against, it is looked up
+	// separately for each file in the environment files nearest to it.
+	Environment string
+
+	// Seed seeds the random builtins e.g. '$uuid' and '$random.email' so their
+	// values are reproducible between runs. Zero means a random seed.
+	Seed uint64
+
 	// Timeout is the overall per-request timeout.
 	Timeout time.Duration
 
@@ -31,6 +69,18 @@ type TestOptions struct {
 	// NoRedirect, if true, disables following http redirects.
 	NoRedirect bool
 
+	// Reporter is the machine readable format to report results in, one of
+	// junit, tap or json. Empty means only the human readable summary is shown.
+	Reporter string
+
+	// ReportFile is the path to write the report to. Empty means the report is
+	// written to stdout in place of the human readable summary.
+	ReportFile string
+
+	// Update, if true, overwrites each response reference with the live
+	// response rather than comparing against it.
+	Update bool
+
 	// Debug enables debug logging.
 	Debug bool
 
@@ -44,6 +94,8 @@ type TestOptions struct {
 //
 // nil means the options are valid.
 func (t TestOptions) Validate() error {
+	reporters := []string{reporterJUnit, reporterTAP, reporterJSON}
+
 	switch {
 	case t.Timeout == 0:
 		return errors.New("timeout cannot be 0")
@@ -61,15 +113,528 @@ func (t TestOptions) Validate() error {
 		return fmt.Errorf("connection-timeout (%s) cannot be larger than timeout (%s)", t.ConnectionTimeout, t.Timeout)
 	case t.Timeout >= t.OverallTimeout:
 		return fmt.Errorf("timeout (%s) cannot be larger than overall-timeout (%s)", t.Timeout, t.OverallTimeout)
+	case t.Reporter != "" && !slices.Contains(reporters, t.Reporter):
+		return fmt.Errorf("invalid option for --reporter, expected one of (%s)", strings.Join(reporters, ", "))
+	case t.ReportFile != "" && t.Reporter == "":
+		return errors.New("--report-file requires --reporter")
 	default:
 		return nil
 	}
 }
 
 // Test implements the test subcommand.
+//
+// Every request with a response reference ('<> response.json') or assertions
+// ('# @assert status == 200') in the .http file(s) given by options.Path is executed,
+// its response body is compared against the contents of the reference file and each
+// assertion is checked against the response. A compact summary is printed and a non-nil
+// error is returned if any test fails.
+//
+// Requests a test references, or whose captured values it uses, are executed before it
+// in the same way as 'zap run' but are not tests themselves.
+//
+// If options.Update is set, response references are rewritten with the live response
+// instead, creating them if they don't exist. Assertions are still checked.
+//
+// If options.Reporter is set, the results are also written in that format to options.ReportFile,
+// or to stdout in place of the summary if no file is given.
 func (z Zap) Test(ctx context.Context, options TestOptions) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+
+	report := z.stdout
+	if options.Reporter != "" && options.ReportFile == "" {
+		// The report is going to stdout so it must be the only thing written there
+		z.stdout = io.Discard
+	}
+
 	logger := z.logger.Prefixed("test").With(slog.String("path", options.Path))
 	logger.Debug("Collecting tests in path")
 
+	ctx, cancel := context.WithTimeout(ctx, options.OverallTimeout)
+	defer cancel()
+
+	paths, err := collectFiles(logger, options.Path)
+	if err != nil {
+		return err
+	}
+
+	parse, err := newParseOptions(options.Vars, options.VarFiles, options.Environment, options.Seed)
+	if err != nil {
+		return err
+	}
+
+	answers, err := loadAnswers(options.Prompts, options.PromptFile, z.stdin)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+
+	var results []testResult
+
+	for _, path := range paths {
+		fileResults, err := z.testFile(ctx, logger, path, parse, answers, options)
+		if err != nil {
+			return err
+		}
+
+		results = append(results, fileResults...)
+	}
+
+	warnUnusedVars(logger, parse)
+
+	if options.Reporter != "" {
+		if err := writeReportTo(report, options.ReportFile, options.Reporter, results); err != nil {
+			return err
+		}
+	}
+
+	if len(results) == 0 {
+		msg.Fwarn(z.stderr, "no tests found in %s", options.Path)
+		return nil
+	}
+
+	failed := z.showTestSummary(results, time.Since(start))
+	if failed != 0 {
+		return fmt.Errorf("%d of %d tests failed", failed, len(results))
+	}
+
 	return nil
 }
+
+// writeReportTo writes the test results in the format given by reporter to the file at path,
+// or to stdout if path is empty.
+func writeReportTo(stdout io.Writer, path, reporter string, results []testResult) error {
+	if path == "" {
+		return writeReport(stdout, reporter, results)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not create report file: %w", err)
+	}
+	defer f.Close()
+
+	if err := writeReport(f, reporter, results); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// referenceUpdate describes what happened to a response reference when running
+// with --update.
+type referenceUpdate int
+
+const (
+	referenceNone    referenceUpdate = iota // Not updating, or the request has no response reference
+	referenceSame                           // The reference already matched the response so was left alone
+	referenceChanged                        // The reference differed from the response and was overwritten
+	referenceAdded                          // The reference did not exist and was created
+)
+
+// String implements [fmt.Stringer] for a [referenceUpdate].
+func (r referenceUpdate) String() string {
+	switch r {
+	case referenceSame:
+		return "unchanged"
+	case referenceChanged:
+		return "changed"
+	case referenceAdded:
+		return "added"
+	default:
+		return "none"
+	}
+}
+
+// testResult is the outcome of executing a single request as a test.
+type testResult struct {
+	err       error              // Error that prevented the test from completing e.g. a failed HTTP request
+	file      string             // Path to the .http file the request is defined in
+	diff      string             // Plain diff between the response reference and the live response, empty if they match
+	failures  []assertionFailure // Assertions that did not hold
+	request   spec.Request       // The request under test
+	response  Response           // The live response
+	reference referenceUpdate    // What happened to the response reference in update mode
+}
+
+// passed reports whether the test passed.
+func (t testResult) passed() bool {
+	return t.err == nil && t.diff == "" && len(t.failures) == 0
+}
+
+// testFile executes all the requests in the .http file at path that have a response
+// reference or assertions, checking their responses against them.
+//
+// Requests that fail or do not match their reference are recorded as failed tests rather
+// than returned as errors, the returned error is reserved for problems with the file itself.
+func (z Zap) testFile(
+	ctx context.Context,
+	logger *log.Logger,
+	path string,
+	parse parseOptions,
+	answers answers,
+	options TestOptions,
+) ([]testResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("zap test: %w", err)
+	}
+	defer f.Close()
+
+	httpFile, err := z.parseFile(path, f, parse)
+	if err != nil {
+		return nil, fmt.Errorf("zap test: %w", err)
+	}
+
+	var toTest []spec.Request
+
+	for _, request := range httpFile.Requests {
+		if len(request.ResponseRef) == 0 && len(request.Assertions) == 0 {
+			continue
+		}
+
+		if len(options.Requests) != 0 && !slices.Contains(options.Requests, request.Name) {
+			continue
+		}
+
+		// File level ignores apply to every request in the file
+		request.Ignore = slices.Concat(httpFile.Ignore, request.Ignore)
+		request.IgnoreHeaders = slices.Concat(httpFile.IgnoreHeaders, request.IgnoreHeaders)
+
+		toTest = append(toTest, request)
+	}
+
+	logger.Debug("Collected tests from file", slog.String("file", path), slog.Int("count", len(toTest)))
+
+	if len(toTest) == 0 {
+		return nil, nil
+	}
+
+	// Requests referenced by the tests have to be executed first, even if they
+	// aren't tests themselves, but they aren't reported
+	tests := make(map[string]bool, len(toTest))
+	for _, request := range toTest {
+		tests[request.Name] = true
+	}
+
+	toExecute, err := executionOrder(httpFile.Requests, toTest)
+	if err != nil {
+		return nil, fmt.Errorf("zap test: %w", err)
+	}
+
+	client := NewHTTPClient(httpFile)
+
+	if err = z.checkPrompts(answers, httpFile.Prompts, toExecute); err != nil {
+		return nil, fmt.Errorf("zap test: %w", err)
+	}
+
+	httpFile, err = z.evaluateGlobalPrompts(logger, httpFile, answers)
+	if err != nil {
+		return nil, fmt.Errorf("could not evaluate global prompts: %w", err)
+	}
+
+	toExecute, err = z.evaluateRequestPrompts(logger, toExecute, answers)
+	if err != nil {
+		return nil, fmt.Errorf("could not evaluate request prompts: %w", err)
+	}
+
+	run := &testRun{
+		file:      httpFile,
+		exchanges: make(map[string]exchange, len(toExecute)),
+		captured:  make(map[string]string),
+		failed:    make(map[string]error),
+		dir:       filepath.Dir(path),
+		library:   parse.library,
+	}
+
+	results := make([]testResult, 0, len(toTest))
+
+	for _, request := range toExecute {
+		evaluated, response, err := z.executeRequest(ctx, logger, client, run, request)
+		if err != nil {
+			run.failed[request.Name] = err
+		}
+
+		if !tests[request.Name] {
+			continue
+		}
+
+		result := testResult{file: path, request: evaluated, response: response, err: err}
+		if err == nil {
+			result = z.checkResponse(logger, path, evaluated, response, options.Update)
+		}
+
+		z.showTestResult(result, options.Verbose)
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// testRun is the state of testing a single .http file, kept so that requests may reference
+// those executed before them and the values captured from their responses.
+type testRun struct {
+	file      spec.File           // The .http file under test
+	exchanges map[string]exchange // The requests executed so far, by name
+	captured  map[string]string   // The values captured from responses so far
+	failed    map[string]error    // Why each request that failed did so, by name
+	dir       string              // The directory containing the file
+	library   builtins.Library    // The builtins available to the requests
+}
+
+// executeRequest evaluates and executes a single request in run, recording the exchange
+// and any values captured from its response for the requests that follow.
+//
+// The request is returned as evaluated. One that references a request that failed
+// is not executed, the returned error says why.
+func (z Zap) executeRequest(
+	ctx context.Context,
+	logger *log.Logger,
+	client http.Client,
+	run *testRun,
+	request spec.Request,
+) (spec.Request, Response, error) {
+	for _, name := range request.DependsOn {
+		if err, ok := run.failed[name]; ok {
+			return request, Response{}, fmt.Errorf("request %s failed: %w", name, err)
+		}
+	}
+
+	scope := requestScope(run.file, request, run.exchanges, run.captured, run.dir, run.library)
+
+	evaluated, err := evaluateRequest(request, scope)
+	if err != nil {
+		return request, Response{}, err
+	}
+
+	redact := redactor(run.file.Prompts, evaluated.Prompts)
+
+	logger.Debug(
+		"Executing request",
+		slog.String("request", evaluated.Name),
+		slog.String("method", evaluated.Method),
+		slog.String("url", redact.Replace(evaluated.URL.String())),
+	)
+
+	response, err := z.doRequest(ctx, logger, client, run.dir, evaluated, redact)
+	if err != nil {
+		return evaluated, Response{}, err
+	}
+
+	run.exchanges[evaluated.Name] = exchange{request: evaluated, response: response}
+
+	captures, err := captureValues(evaluated, response)
+	if err != nil {
+		return evaluated, response, err
+	}
+
+	maps.Copy(run.captured, captures)
+
+	return evaluated, response, nil
+}
+
+// checkResponse checks the assertions of a request against its response and compares the
+// response against the contents of its response reference file, skipping any ignored values
+// and headers.
+//
+// If update is true, a response reference that does not match is overwritten with
+// the response instead of being compared against it.
+func (z Zap) checkResponse(
+	logger *log.Logger,
+	file string,
+	request spec.Request,
+	response Response,
+	update bool,
+) testResult {
+	result := testResult{
+		file:     file,
+		request:  request,
+		response: response,
+		failures: checkAssertions(request.Assertions, response),
+	}
+
+	if len(request.ResponseRef) == 0 {
+		return result
+	}
+
+	// Response references are relative to the .http file
+	ref := filepath.Join(filepath.Dir(file), request.ResponseRef.String())
+
+	want, err := os.ReadFile(ref)
+	if err != nil && (!update || !errors.Is(err, fs.ErrNotExist)) {
+		result.err = fmt.Errorf("could not read response reference: %w", err)
+		return result
+	}
+
+	if err == nil {
+		changes, err := compareReference(want, response, request.Ignore, request.IgnoreHeaders)
+		if err != nil {
+			result.err = err
+			return result
+		}
+
+		if !update {
+			result.diff = changes
+			return result
+		}
+
+		if changes == "" {
+			// Leave the reference alone so ignored values don't churn
+			result.reference = referenceSame
+			return result
+		}
+
+		result.reference = referenceChanged
+	} else {
+		result.reference = referenceAdded
+	}
+
+	// Preserve the format of the existing reference
+	content := response.Body
+	if bytes.HasPrefix(want, []byte(responsePrefix)) {
+		content = formatResponse(response)
+	}
+
+	if err := z.writeResponseFile(logger, filepath.Dir(file), request.ResponseRef.String(), content); err != nil {
+		result.err = fmt.Errorf("could not update response reference: %w", err)
+	}
+
+	return result
+}
+
+// showTestResult prints the outcome of a single test to z.stdout, including the
+// reason for failure if it failed.
+//
+// If verbose is true, the full response is shown regardless of the outcome.
+func (z Zap) showTestResult(result testResult, verbose bool) {
+	status := success.Text("PASS")
+	if !result.passed() {
+		status = failure.Text("FAIL")
+	}
+
+	fmt.Fprintf(
+		z.stdout,
+		"%s %s: %s (%s)\n",
+		status,
+		hue.Bold.Text(result.file),
+		result.request.Name,
+		dimmed.Text(result.response.Duration.String()),
+	)
+
+	if result.err != nil {
+		fmt.Fprintf(z.stdout, "\n    %s\n\n", result.err)
+		return
+	}
+
+	switch result.reference {
+	case referenceAdded:
+		fmt.Fprintf(z.stdout, "    %s %s\n", success.Text(result.reference.String()), result.request.ResponseRef)
+	case referenceChanged:
+		fmt.Fprintf(z.stdout, "    %s %s\n", updated.Text(result.reference.String()), result.request.ResponseRef)
+	case referenceSame:
+		fmt.Fprintf(z.stdout, "    %s %s\n", dimmed.Text(result.reference.String()), result.request.ResponseRef)
+	default:
+		// Not updating, nothing to show
+	}
+
+	if len(result.failures) != 0 {
+		fmt.Fprintln(z.stdout)
+
+		for _, fail := range result.failures {
+			fmt.Fprintf(z.stdout, "    %s\n", fail)
+		}
+	}
+
+	if result.diff != "" {
+		fmt.Fprintf(z.stdout, "\n    response does not match reference %s\n\n", result.request.ResponseRef)
+
+		for line := range strings.Lines(result.diff) {
+			// Indented to sit under the message, without trailing space on blank lines
+			line = strings.TrimRight(line, " \n")
+			if line == "" {
+				fmt.Fprintln(z.stdout)
+				continue
+			}
+
+			fmt.Fprintln(z.stdout, "    "+colourDiffLine(line))
+		}
+	}
+
+	if !result.passed() {
+		fmt.Fprintln(z.stdout)
+	}
+
+	if verbose {
+		z.showResponse(result.file, result.request, result.response, verbose)
+		fmt.Fprintln(z.stdout)
+	}
+}
+
+// colourDiffLine returns a line of a reference diff styled by what it shows, lines
+// removed from the reference in red and those added by the response in green.
+func colourDiffLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+		return hue.Bold.Text(line)
+	case strings.HasPrefix(line, "@@"):
+		return dimmed.Text(line)
+	case strings.HasPrefix(line, "-"):
+		return hue.Red.Text(line)
+	case strings.HasPrefix(line, "+"):
+		return hue.Green.Text(line)
+	default:
+		return line
+	}
+}
+
+// showTestSummary prints a summary of all the test results to z.stdout, returning
+// the number of failed tests.
+func (z Zap) showTestSummary(results []testResult, took time.Duration) int {
+	failed := 0
+
+	for _, result := range results {
+		if !result.passed() {
+			failed++
+		}
+	}
+
+	passedText := fmt.Sprintf("%d passed", len(results)-failed)
+	failedText := fmt.Sprintf("%d failed", failed)
+
+	if failed == 0 {
+		passedText = success.Text(passedText)
+	} else {
+		failedText = failure.Text(failedText)
+	}
+
+	fmt.Fprintf(
+		z.stdout,
+		"\n%d tests: %s, %s (%s)\n",
+		len(results),
+		passedText,
+		failedText,
+		dimmed.Text(took.String()),
+	)
+
+	references := make(map[referenceUpdate]int)
+	for _, result := range results {
+		references[result.reference]++
+	}
+
+	if len(results) != references[referenceNone] {
+		fmt.Fprintf(
+			z.stdout,
+			"%d references: %d added, %d changed, %d unchanged\n",
+			len(results)-references[referenceNone],
+			references[referenceAdded],
+			references[referenceChanged],
+			references[referenceSame],
+		)
+	}
+
+	return failed
+}
@@ -0,0 +1,172 @@
+package zap
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"go.followtheprocess.codes/log"
+	"go.followtheprocess.codes/zap/internal/dotenv"
+	"go.followtheprocess.codes/zap/internal/syntax/resolver/builtins"
+	"go.yaml.in/yaml/v4"
+)
+
+// parseOptions are the user supplied sources of variables for resolving a .http file.
+type parseOptions struct {
+	// library is the builtins library used to resolve the files, and to evaluate the
+	// dynamic builtins deferred until a request is executed.
+	library builtins.Library
+
+	// vars are variables from '--var' and '--var-file', overriding the file's globals.
+	vars map[string]string
+
+	// used records the vars used by any of the files parsed, so unused ones can be
+	// reported once they all have been.
+	used *varUsage
+
+	// env is the name of the environment to select from the environment files,
+	// empty means none.
+	env string
+}
+
+// varUsage records which command line variables have been used, it is safe
+// for concurrent use as files may be parsed concurrently.
+type varUsage struct {
+	used map[string]bool
+	mu   sync.Mutex
+}
+
+// newParseOptions loads the variables given on the command line and returns the
+// [parseOptions] for the files parsed by a command.
+//
+// A non-zero seed seeds the random builtins so their values are reproducible.
+func newParseOptions(vars, varFiles []string, env string, seed uint64) (parseOptions, error) {
+	loaded, err := loadVars(vars, varFiles)
+	if err != nil {
+		return parseOptions{}, err
+	}
+
+	var options []builtins.Option
+	if seed != 0 {
+		options = append(options, builtins.WithSeed(seed))
+	}
+
+	library, err := builtins.NewLibrary(options...)
+	if err != nil {
+		return parseOptions{}, fmt.Errorf("failed to initialise the builtins library: %w", err)
+	}
+
+	return parseOptions{
+		library: library,
+		vars:    loaded,
+		used:    &varUsage{used: make(map[string]bool, len(loaded))},
+		env:     env,
+	}, nil
+}
+
+// record marks the given variables as used.
+func (u *varUsage) record(keys ...string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for _, key := range keys {
+		u.used[key] = true
+	}
+}
+
+// loadVars gathers the variables given on the command line from --var-file files and
+// --var 'key=value' pairs.
+//
+// Files are loaded in order with later ones taking precedence, and --var pairs take
+// precedence over all of them.
+func loadVars(vars, files []string) (map[string]string, error) {
+	loaded := make(map[string]string, len(vars))
+
+	for _, file := range files {
+		fromFile, err := loadVarFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("could not load variables from %s: %w", file, err)
+		}
+
+		maps.Copy(loaded, fromFile)
+	}
+
+	for _, pair := range vars {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid variable %q, expected key=value", pair)
+		}
+
+		loaded[key] = value
+	}
+
+	return loaded, nil
+}
+
+// loadVarFile loads the variables in a --var-file, its format is determined by the
+// file extension: JSON for '.json', YAML for '.yaml' or '.yml', and dotenv otherwise.
+//
+// JSON and YAML files must be a flat object of names to scalar values.
+func loadVarFile(path string) (map[string]string, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]any
+
+	switch filepath.Ext(path) {
+	case ".json":
+		if err := json.Unmarshal(contents, &raw); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(contents, &raw); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+	default:
+		return dotenv.Parse(contents)
+	}
+
+	vars := make(map[string]string, len(raw))
+
+	for key, value := range raw {
+		switch value := value.(type) {
+		case string:
+			vars[key] = value
+		case nil:
+			vars[key] = ""
+		case map[string]any, []any:
+			return nil, fmt.Errorf("variable %s must be a string, number or boolean, got %T", key, value)
+		default:
+			vars[key] = fmt.Sprint(value)
+		}
+	}
+
+	return vars, nil
+}
+
+// warnUnusedVars logs a warning for every variable given on the command line that
+// wasn't used by any of the files parsed.
+func warnUnusedVars(logger *log.Logger, options parseOptions) {
+	if options.used == nil {
+		return
+	}
+
+	options.used.mu.Lock()
+	defer options.used.mu.Unlock()
+
+	for _, key := range slices.Sorted(maps.Keys(options.vars)) {
+		if !options.used.used[key] {
+			logger.Warn("Variable was given but never used", slog.String("var", key))
+		}
+	}
+}
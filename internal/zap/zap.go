@@ -7,27 +7,36 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/fs"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"go.followtheprocess.codes/log"
+	"go.followtheprocess.codes/zap/internal/envfile"
 	"go.followtheprocess.codes/zap/internal/spec"
 	"go.followtheprocess.codes/zap/internal/syntax"
 	"go.followtheprocess.codes/zap/internal/syntax/parser"
 	"go.followtheprocess.codes/zap/internal/syntax/resolver"
-	"go.followtheprocess.codes/zap/internal/syntax/resolver/builtins"
 )
 
 // Zap represents the zap program.
 type Zap struct {
-	stdin   io.Reader   // Program input (prompts) come from here
-	stdout  io.Writer   // Normal program output is written here
-	stderr  io.Writer   // Logs and errors are written here
-	logger  *log.Logger // The logger for the application
-	version string      // The app version
+	stdin       io.Reader   // Program input (prompts) come from here
+	stdout      io.Writer   // Normal program output is written here
+	stderr      io.Writer   // Logs and errors are written here
+	logger      *log.Logger // The logger for the application
+	version     string      // The app version
+	interactive bool        // Whether prompts can be asked interactively on stdin
 }
 
 // New returns a new [Zap].
-func New(debug bool, version string, stdin io.Reader, stdout, stderr io.Writer) Zap {
+//
+// interactive reports whether stdin is an interactive terminal the user can answer
+// prompts on, otherwise prompts must be answered up front.
+func New(debug bool, version string, stdin io.Reader, interactive bool, stdout, stderr io.Writer) Zap {
 	level := log.LevelInfo
 	if debug {
 		level = log.LevelDebug
@@ -40,11 +49,12 @@ func New(debug bool, version string, stdin io.Reader, stdout, stderr io.Writer)
 	)
 
 	return Zap{
-		stdin:   stdin,
-		stdout:  stdout,
-		stderr:  stderr,
-		logger:  logger,
-		version: version,
+		stdin:       stdin,
+		stdout:      stdout,
+		stderr:      stderr,
+		logger:      logger,
+		version:     version,
+		interactive: interactive,
 	}
 }
 
@@ -56,8 +66,12 @@ func (z Zap) Hello(ctx context.Context) {
 
 // parseFile reads a .http file, parses it and resolves it.
 //
+// If options.env is not empty, the variables of the environment with that name, from
+// the environment files nearest the .http file, are available to it. Any options.vars
+// override the file's globals and are recorded in options.used if the file uses them.
+//
 // Most operations begin by parsing the file so those steps are extracted here.
-func (z Zap) parseFile(name string, r io.Reader) (spec.File, error) {
+func (z Zap) parseFile(name string, r io.Reader, options parseOptions) (spec.File, error) {
 	src, err := io.ReadAll(r)
 	if err != nil {
 		return spec.File{}, fmt.Errorf("could not read file: %w", err)
@@ -75,12 +89,18 @@ func (z Zap) parseFile(name string, r io.Reader) (spec.File, error) {
 		return spec.File{}, err
 	}
 
-	lib, err := builtins.NewLibrary()
-	if err != nil {
-		return spec.File{}, fmt.Errorf("failed to initialise the builtins library: %w", err)
+	resolverOptions := []resolver.Option{resolver.WithOverrides(options.vars)}
+
+	if options.env != "" {
+		variables, err := z.loadEnvironment(name, options.env)
+		if err != nil {
+			return spec.File{}, err
+		}
+
+		resolverOptions = append(resolverOptions, resolver.WithEnvironment(variables))
 	}
 
-	res := resolver.New(name, src, lib)
+	res := resolver.New(name, src, options.library, resolverOptions...)
 
 	resolved, err := res.Resolve(parsed)
 	if err != nil {
@@ -91,6 +111,15 @@ func (z Zap) parseFile(name string, r io.Reader) (spec.File, error) {
 		return spec.File{}, err
 	}
 
+	if options.used != nil {
+		unused := res.UnusedOverrides()
+		for key := range options.vars {
+			if !slices.Contains(unused, key) {
+				options.used.record(key)
+			}
+		}
+	}
+
 	if resolved.ConnectionTimeout == 0 {
 		resolved.ConnectionTimeout = DefaultConnectionTimeout
 	}
@@ -102,6 +131,44 @@ func (z Zap) parseFile(name string, r io.Reader) (spec.File, error) {
 	return resolved, nil
 }
 
+// loadEnvironment loads the variables of the environment called env from the environment
+// files nearest the .http file at path.
+//
+// An unknown environment is reported as a diagnostic.
+func (z Zap) loadEnvironment(path, env string) (map[string]string, error) {
+	environments, err := envfile.Find(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+
+	variables, ok := environments.Get(env)
+	if ok {
+		z.logger.Debug(
+			"Loaded environment",
+			slog.String("env", env),
+			slog.String("files", strings.Join(environments.Files(), ", ")),
+		)
+
+		return variables, nil
+	}
+
+	// There's nowhere more precise to point to, so point at the start of whichever
+	// file should have defined the environment
+	position := syntax.Position{Name: path, Line: 1, StartCol: 1, EndCol: 1}
+	msg := fmt.Sprintf("unknown environment %q, no %s or %s found", env, envfile.Public, envfile.Private)
+
+	if files := environments.Files(); len(files) != 0 {
+		position.Name = files[0]
+		msg = fmt.Sprintf("unknown environment %q, expected one of (%s)", env, strings.Join(environments.Names(), "|"))
+	}
+
+	if err := z.printDiagnostics([]syntax.Diagnostic{{Msg: msg, Position: position}}); err != nil {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("unknown environment %q", env)
+}
+
 // printDiagnostics prints the list of [syntax.Diagnostic] gathered by
 // the parsing pipeline.
 func (z Zap) printDiagnostics(diagnostics []syntax.Diagnostic) error {
@@ -120,3 +187,40 @@ func (z Zap) printDiagnostics(diagnostics []syntax.Diagnostic) error {
 
 	return nil
 }
+
+// collectFiles returns the paths of the .http files described by path.
+//
+// If path is a file, it is returned as is. If it is a directory, it is walked
+// recursively and all files with the '.http' extension are returned.
+func collectFiles(logger *log.Logger, path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not get path info: %w", err)
+	}
+
+	if !info.IsDir() {
+		logger.Debug("Path is a file")
+		return []string{path}, nil
+	}
+
+	logger.Debug("Path is a directory")
+
+	var paths []string
+
+	err = filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if filepath.Ext(path) == ".http" {
+			paths = append(paths, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not walk %s: %w", path, err)
+	}
+
+	return paths, nil
+}
@@ -2,23 +2,41 @@ package zap_test
 
 import (
 	"bytes"
+	"cmp"
+	"crypto/rand"
+	"encoding/json"
+	"encoding/xml"
 	"flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
+	"go.followtheprocess.codes/hue"
 	"go.followtheprocess.codes/snapshot"
 	"go.followtheprocess.codes/test"
+	"go.followtheprocess.codes/txtar"
 	"go.followtheprocess.codes/zap/internal/zap"
 	"go.uber.org/goleak"
 )
 
 var update = flag.Bool("update", false, "Update testdata files")
 
+func TestMain(m *testing.M) {
+	// Snapshots are plain text, whatever the terminal or $FORCE_COLOR would have
+	hue.Enabled(false)
+	os.Exit(m.Run())
+}
+
 func TestRun(t *testing.T) {
 	pattern := filepath.Join("testdata", "run", "*.http")
 	files, err := filepath.Glob(pattern)
@@ -35,7 +53,7 @@ func TestRun(t *testing.T) {
 			stdout := &bytes.Buffer{}
 			stderr := &bytes.Buffer{}
 
-			app := zap.New(false, "test", stdin, stdout, stderr)
+			app := zap.New(false, "test", stdin, false, stdout, stderr)
 
 			options := zap.RunOptions{
 				File:              "src.http",
@@ -66,6 +84,736 @@ func TestRun(t *testing.T) {
 	}
 }
 
+func TestRunOutput(t *testing.T) {
+	for _, output := range []string{"json", "yaml", "har"} {
+		t.Run(output, func(t *testing.T) {
+			server := NewTestServer(t)
+			t.Cleanup(server.Close)
+
+			t.Setenv("ZAP_TEST_URL", server.URL)
+
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			app := zap.New(false, "test", os.Stdin, false, stdout, stderr)
+
+			options := zap.RunOptions{
+				File:              "src.http",
+				Output:            output,
+				Timeout:           zap.DefaultTimeout,
+				ConnectionTimeout: zap.DefaultConnectionTimeout,
+				OverallTimeout:    zap.DefaultOverallTimeout,
+			}
+
+			src := strings.Join(
+				[]string{
+					"###",
+					"# @name = getItem",
+					"GET {{ $env.ZAP_TEST_URL }}/ok",
+					"",
+					"###",
+					"# @name = postBad",
+					"POST {{ $env.ZAP_TEST_URL }}/bad",
+				},
+				"\n",
+			)
+
+			err := app.Run(t.Context(), strings.NewReader(src), options)
+			test.Ok(t, err, test.Context("zap run returned an error: %v", stderr.String()))
+
+			snap := snapshot.New(
+				t,
+				snapshot.Update(*update),
+				snapshot.Filter(regexp.QuoteMeta(server.URL), "[URL]"),
+				snapshot.Filter(`"duration":\d+`, `"duration":"[DURATION]"`),
+				snapshot.Filter(`duration: \d+(?:\.\d+)?(?:s|ms|µs)`, "duration: [DURATION]"),
+				snapshot.Filter(`"startedDateTime": "[^"]+"`, `"startedDateTime": "[TIME]"`),
+				snapshot.Filter(`"(blocked|dns|connect|send|wait|receive|ssl|time)": -?[0-9.e+-]+`, `"$1": "[TIMING]"`),
+			)
+
+			snap.Snap(stdout.String())
+		})
+	}
+}
+
+func TestRunArchive(t *testing.T) {
+	pattern := filepath.Join("testdata", "run", "*.txtar")
+	files, err := filepath.Glob(pattern)
+	test.Ok(t, err)
+
+	// Each case runs in a temp dir, but the snapshots are kept here
+	root, err := os.Getwd()
+	test.Ok(t, err)
+
+	for _, file := range files {
+		name := filepath.Base(file)
+		t.Run(name, func(t *testing.T) {
+			server := NewTestServer(t)
+			t.Cleanup(server.Close)
+
+			t.Setenv("ZAP_TEST_URL", server.URL)
+
+			archive, _ := extractArchive(t, file)
+
+			options := zap.RunOptions{
+				File:              "src.http",
+				Output:            "yaml",
+				Timeout:           zap.DefaultTimeout,
+				ConnectionTimeout: zap.DefaultConnectionTimeout,
+				OverallTimeout:    zap.DefaultOverallTimeout,
+			}
+
+			archiveOptions(t, archive, &options)
+
+			f, err := os.Open(options.File)
+			test.Ok(t, err)
+			t.Cleanup(func() { f.Close() })
+
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			app := zap.New(false, "test", strings.NewReader(""), false, stdout, stderr)
+
+			err = app.Run(t.Context(), f, options)
+
+			snap := snapshot.New(
+				t,
+				snapshot.Update(*update),
+				snapshot.Filter(regexp.QuoteMeta(server.URL), "[URL]"),
+				snapshot.Filter(`duration: \d+(?:\.\d+)?(?:s|ms|µs)`, "duration: [DURATION]"),
+			)
+
+			result := archiveResult(t, name, archive, stdout, stderr, err)
+
+			t.Chdir(root)
+			snap.Snap(result)
+		})
+	}
+}
+
+func TestRunPrompts(t *testing.T) {
+	server := NewTestServer(t)
+	t.Cleanup(server.Close)
+
+	t.Setenv("ZAP_TEST_URL", server.URL)
+
+	src := `@prompt user The user to log in as
+
+###
+# @name = login
+# @prompt password
+POST {{ $env.ZAP_TEST_URL }}/echo
+
+{"user": "{{ user }}", "password": "{{ password }}"}
+`
+
+	tests := []struct {
+		name       string   // Name of the test case
+		stdin      string   // Contents of stdin
+		promptFile string   // --prompt-file
+		want       string   // Expected request body
+		errMsg     string   // Expected error message, if any
+		prompts    []string // --prompt flags
+	}{
+		{
+			name:    "flags",
+			prompts: []string{"user=zap", "password=secret"},
+			want:    `{"user": "zap", "password": "secret"}`,
+		},
+		{
+			name:    "request specific",
+			prompts: []string{"user=zap", "password=generic", "login.password=specific"},
+			want:    `{"user": "zap", "password": "specific"}`,
+		},
+		{
+			name:       "stdin",
+			stdin:      "user=zap\npassword=stdin\n",
+			promptFile: "-",
+			prompts:    []string{"user=flag"},
+			want:       `{"user": "flag", "password": "stdin"}`,
+		},
+		{
+			name:    "unanswered",
+			prompts: []string{"password=secret"},
+			errMsg:  "stdin is not a terminal so prompts cannot be asked, answer them with --prompt name=value or --prompt-file: user",
+		},
+		{
+			name:   "none answered",
+			errMsg: "stdin is not a terminal so prompts cannot be asked, answer them with --prompt name=value or --prompt-file: user, login.password",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			app := zap.New(false, "test", strings.NewReader(tt.stdin), false, stdout, stderr)
+
+			options := zap.RunOptions{
+				File:              "src.http",
+				Output:            "json",
+				Prompts:           tt.prompts,
+				PromptFile:        tt.promptFile,
+				Timeout:           zap.DefaultTimeout,
+				ConnectionTimeout: zap.DefaultConnectionTimeout,
+				OverallTimeout:    zap.DefaultOverallTimeout,
+			}
+
+			err := app.Run(t.Context(), strings.NewReader(src), options)
+			if tt.errMsg != "" {
+				test.Err(t, err)
+				test.Equal(t, err.Error(), tt.errMsg)
+				test.Equal(t, stdout.String(), "") // No requests should have been sent
+
+				return
+			}
+
+			test.Ok(t, err, test.Context("zap run returned an error: %v", stderr.String()))
+
+			var got struct {
+				Body string `json:"body"`
+			}
+
+			test.Ok(t, json.NewDecoder(stdout).Decode(&got))
+			test.Equal(t, got.Body, tt.want)
+		})
+	}
+}
+
+func TestInteractivePrompts(t *testing.T) {
+	src := `@prompt user The user to log in as
+@prompt env [dev|prod] = dev The environment
+
+###
+# @name = login
+# @prompt password
+POST https://{{ env }}.example.com/login
+
+{"user": "{{ user }}", "password": "{{ password }}"}
+`
+
+	tests := []struct {
+		name    string   // Name of the test case
+		stdin   string   // Keys typed at the prompts
+		want    string   // Expected user and URL of the exported request, as 'user@url'
+		prompts []string // --prompt flags
+	}{
+		{
+			name:    "typed",
+			stdin:   "zap\r",
+			prompts: []string{"env=prod", "password=secret"},
+			want:    "zap@https://prod.example.com/login",
+		},
+		{
+			name:    "default",
+			stdin:   "\r",
+			prompts: []string{"user=zap", "password=secret"},
+			want:    "zap@https://dev.example.com/login",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer verifyNoPromptLeaks(t)
+
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			// Keys are read from stdin as if typed at a terminal
+			app := zap.New(false, "test", strings.NewReader(tt.stdin), true, stdout, stderr)
+
+			options := zap.ExportOptions{
+				File:    "src.http",
+				Format:  "json",
+				Prompts: tt.prompts,
+			}
+
+			err := app.Export(t.Context(), strings.NewReader(src), options)
+			test.Ok(t, err, test.Context("zap export returned an error: %v", stderr.String()))
+
+			var got struct {
+				Requests []struct {
+					URL  string `json:"url"`
+					Body string `json:"body"`
+				} `json:"requests"`
+			}
+
+			test.Ok(t, json.Unmarshal(stdout.Bytes(), &got))
+			test.Equal(t, len(got.Requests), 1)
+
+			var body struct {
+				User     string `json:"user"`
+				Password string `json:"password"`
+			}
+
+			test.Ok(t, json.Unmarshal([]byte(got.Requests[0].Body), &body))
+			test.Equal(t, body.Password, "secret")
+			test.Equal(t, body.User+"@"+got.Requests[0].URL, tt.want)
+		})
+	}
+}
+
+// verifyNoPromptLeaks checks no goroutines have leaked after prompting interactively.
+//
+// The cursor of a text input blinks in a goroutine that may outlive the form by up to
+// its blink interval, so wait for it to finish rather than report it as a leak.
+func verifyNoPromptLeaks(t *testing.T) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for goleak.Find() != nil && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	goleak.VerifyNone(t)
+}
+
+func TestSecretPromptsNotLogged(t *testing.T) {
+	server := NewTestServer(t)
+	t.Cleanup(server.Close)
+
+	t.Setenv("ZAP_TEST_URL", server.URL)
+
+	src := `@prompt-secret token
+
+###
+# @name = getItem
+# @assert status == 200
+GET {{ $env.ZAP_TEST_URL }}/ok?token={{ token }}
+`
+
+	file := filepath.Join(t.TempDir(), "secret.http")
+	test.Ok(t, os.WriteFile(file, []byte(src), 0o644))
+
+	prompts := []string{"token=SUPERSECRET"}
+
+	t.Run("run", func(t *testing.T) {
+		stderr := &bytes.Buffer{}
+
+		app := zap.New(true, "test", strings.NewReader(""), false, io.Discard, stderr)
+
+		options := zap.RunOptions{
+			File:              file,
+			Output:            "stdout",
+			Prompts:           prompts,
+			Timeout:           zap.DefaultTimeout,
+			ConnectionTimeout: zap.DefaultConnectionTimeout,
+			OverallTimeout:    zap.DefaultOverallTimeout,
+		}
+
+		err := app.Run(t.Context(), strings.NewReader(src), options)
+		test.Ok(t, err, test.Context("zap run returned an error: %v", stderr.String()))
+
+		got := stderr.String()
+		test.True(t, strings.Contains(got, "token=********"), test.Context("debug log:\n%s", got))
+		test.False(t, strings.Contains(got, "SUPERSECRET"), test.Context("secret was logged:\n%s", got))
+	})
+
+	t.Run("test", func(t *testing.T) {
+		stderr := &bytes.Buffer{}
+
+		app := zap.New(true, "test", strings.NewReader(""), false, io.Discard, stderr)
+
+		options := zap.TestOptions{
+			Path:              file,
+			Prompts:           prompts,
+			Timeout:           zap.DefaultTimeout,
+			ConnectionTimeout: zap.DefaultConnectionTimeout,
+			OverallTimeout:    zap.DefaultOverallTimeout,
+		}
+
+		err := app.Test(t.Context(), options)
+		test.Ok(t, err, test.Context("zap test returned an error: %v", stderr.String()))
+
+		got := stderr.String()
+		test.True(t, strings.Contains(got, "token=********"), test.Context("debug log:\n%s", got))
+		test.False(t, strings.Contains(got, "SUPERSECRET"), test.Context("secret was logged:\n%s", got))
+	})
+}
+
+func TestRunDynamicBuiltins(t *testing.T) {
+	server := NewTestServer(t)
+	t.Cleanup(server.Close)
+
+	t.Setenv("ZAP_TEST_URL", server.URL)
+
+	// id is a new uuid in every request, but the same everywhere it's used within one
+	src := `@id = {{ $uuid }}
+
+###
+# @name = first
+POST {{ $env.ZAP_TEST_URL }}/echo
+
+{"id": "{{ id }}", "again": "{{ id }}", "other": "{{ $uuid }}", "year": "{{ $datetime "YYYY" }}"}
+
+###
+# @name = second
+POST {{ $env.ZAP_TEST_URL }}/echo
+
+{"id": "{{ id }}", "again": "{{ id }}", "other": "{{ $uuid }}", "year": "{{ $datetime "YYYY" }}"}
+`
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	app := zap.New(false, "test", strings.NewReader(""), false, stdout, stderr)
+
+	options := zap.RunOptions{
+		File:              "src.http",
+		Output:            "json",
+		Timeout:           zap.DefaultTimeout,
+		ConnectionTimeout: zap.DefaultConnectionTimeout,
+		OverallTimeout:    zap.DefaultOverallTimeout,
+	}
+
+	err := app.Run(t.Context(), strings.NewReader(src), options)
+	test.Ok(t, err, test.Context("zap run returned an error: %v", stderr.String()))
+
+	type ids struct {
+		ID    string `json:"id"`
+		Again string `json:"again"`
+		Other string `json:"other"`
+		Year  string `json:"year"`
+	}
+
+	decoder := json.NewDecoder(stdout)
+
+	var got []ids
+
+	for decoder.More() {
+		var record struct {
+			Body string `json:"body"`
+		}
+
+		test.Ok(t, decoder.Decode(&record))
+
+		var body ids
+
+		test.Ok(t, json.Unmarshal([]byte(record.Body), &body), test.Context("body: %s", record.Body))
+
+		got = append(got, body)
+	}
+
+	test.Equal(t, len(got), 2)
+
+	for _, body := range got {
+		test.Ok(t, uuid.Validate(body.ID))
+		test.Ok(t, uuid.Validate(body.Other))
+		test.Equal(t, body.Again, body.ID)
+		test.True(t, body.Other != body.ID, test.Context("$uuid used directly had the same value as id"))
+		test.Equal(t, body.Year, strconv.Itoa(time.Now().UTC().Year()))
+	}
+
+	test.True(t, got[0].ID != got[1].ID, test.Context("id had the same value in both requests"))
+}
+
+func TestRunSeed(t *testing.T) {
+	server := NewTestServer(t)
+	t.Cleanup(server.Close)
+
+	t.Setenv("ZAP_TEST_URL", server.URL)
+
+	src := `###
+POST {{ $env.ZAP_TEST_URL }}/echo
+
+{"id": "{{ $uuid }}", "n": {{ $randomInt 1 100 }}, "email": "{{ $random.email }}", "code": "{{ $random.alphanumeric(8) }}"}
+`
+
+	run := func(seed uint64) string {
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
+
+		app := zap.New(false, "test", strings.NewReader(""), false, stdout, stderr)
+
+		options := zap.RunOptions{
+			File:              "src.http",
+			Output:            "json",
+			Seed:              seed,
+			Timeout:           zap.DefaultTimeout,
+			ConnectionTimeout: zap.DefaultConnectionTimeout,
+			OverallTimeout:    zap.DefaultOverallTimeout,
+		}
+
+		err := app.Run(t.Context(), strings.NewReader(src), options)
+		test.Ok(t, err, test.Context("zap run returned an error: %v", stderr.String()))
+
+		var record struct {
+			Body string `json:"body"`
+		}
+
+		test.Ok(t, json.Unmarshal(stdout.Bytes(), &record))
+		test.True(t, json.Valid([]byte(record.Body)), test.Context("body: %s", record.Body))
+
+		return record.Body
+	}
+
+	test.Equal(t, run(42), run(42))
+	test.True(t, run(42) != run(7), test.Context("different seeds generated the same body"))
+}
+
+func TestTest(t *testing.T) {
+	pattern := filepath.Join("testdata", "test", "*.http")
+	files, err := filepath.Glob(pattern)
+	test.Ok(t, err)
+
+	for _, file := range files {
+		name := filepath.Base(file)
+		t.Run(name, func(t *testing.T) {
+			server := NewTestServer(t)
+			t.Cleanup(server.Close)
+
+			t.Setenv("ZAP_TEST_URL", server.URL)
+
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			app := zap.New(false, "test", os.Stdin, false, stdout, stderr)
+
+			options := zap.TestOptions{
+				Path:              file,
+				Timeout:           zap.DefaultTimeout,
+				ConnectionTimeout: zap.DefaultConnectionTimeout,
+				OverallTimeout:    zap.DefaultOverallTimeout,
+			}
+
+			err := app.Test(t.Context(), options)
+
+			// Files prefixed with fail contain failing tests
+			test.WantErr(t, err, strings.HasPrefix(name, "fail"))
+
+			snap := snapshot.New(
+				t,
+				snapshot.Update(*update),
+				snapshot.Filter(`\d+(?:\.\d+)?(?:s|ms|µs)`, "[DURATION]"),
+				snapshot.Filter(`\\+([\w\d]|\.)`, "/$1"), // Replace windows paths
+			)
+
+			snap.Snap(stdout.String())
+		})
+	}
+}
+
+func TestTestUpdate(t *testing.T) {
+	server := NewTestServer(t)
+	t.Cleanup(server.Close)
+
+	t.Setenv("ZAP_TEST_URL", server.URL)
+
+	// References are relative to the .http file, so work in a temp dir
+	t.Chdir(t.TempDir())
+
+	src := `###
+# @name = same
+GET {{ $env.ZAP_TEST_URL }}/ok
+
+<> responses/same.json
+
+###
+# @name = changed
+GET {{ $env.ZAP_TEST_URL }}/ok
+
+<> responses/changed.json
+
+###
+# @name = headers
+GET {{ $env.ZAP_TEST_URL }}/ok
+
+<> responses/headers.txt
+
+###
+# @name = added
+POST {{ $env.ZAP_TEST_URL }}/bad
+
+<> responses/new/added.json
+`
+
+	test.Ok(t, os.WriteFile("update.http", []byte(src), 0o644))
+	test.Ok(t, os.Mkdir("responses", 0o755))
+	test.Ok(t, os.WriteFile(filepath.Join("responses", "same.json"), []byte("{\"stuff\": \"here\"}\n"), 0o644))
+	test.Ok(t, os.WriteFile(filepath.Join("responses", "changed.json"), []byte("{\"stuff\": \"there\"}\n"), 0o644))
+	test.Ok(
+		t,
+		os.WriteFile(
+			filepath.Join("responses", "headers.txt"),
+			[]byte("HTTP/1.1 200 OK\nContent-Type: text/plain\n\n{\"stuff\": \"there\"}\n"),
+			0o644,
+		),
+	)
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	app := zap.New(false, "test", os.Stdin, false, stdout, stderr)
+
+	options := zap.TestOptions{
+		Path:              "update.http",
+		Timeout:           zap.DefaultTimeout,
+		ConnectionTimeout: zap.DefaultConnectionTimeout,
+		OverallTimeout:    zap.DefaultOverallTimeout,
+		Update:            true,
+	}
+
+	err := app.Test(t.Context(), options)
+	test.Ok(t, err, test.Context("zap test --update returned an error: %v", stderr.String()))
+
+	t.Logf("stdout:\n\n%s\n", stdout.String())
+
+	for _, want := range []string{
+		"unchanged responses/same.json",
+		"changed responses/changed.json",
+		"changed responses/headers.txt",
+		"added responses/new/added.json",
+		"4 references: 1 added, 2 changed, 1 unchanged",
+	} {
+		test.True(
+			t,
+			strings.Contains(filepath.ToSlash(stdout.String()), want),
+			test.Context("stdout did not contain %q", want),
+		)
+	}
+
+	for file, want := range map[string]string{
+		"same.json":      "{\"stuff\": \"here\"}\n",
+		"changed.json":   "{\"stuff\": \"here\"}\n",
+		"new/added.json": "{\"bad\": \"yes\"}\n",
+		"headers.txt":    "HTTP/1.1 200 OK\nContent-Length: 17\nContent-Type: application/json\n\n{\"stuff\": \"here\"}\n",
+	} {
+		got, err := os.ReadFile(filepath.Join("responses", filepath.FromSlash(file)))
+		test.Ok(t, err)
+		test.Diff(t, string(got), want)
+	}
+
+	// Running again should now pass without updating
+	stdout.Reset()
+
+	options.Update = false
+
+	err = app.Test(t.Context(), options)
+	test.Ok(t, err, test.Context("zap test after --update failed: %v", stdout.String()))
+}
+
+func TestTestReport(t *testing.T) {
+	for _, reporter := range []string{"junit", "tap", "json"} {
+		t.Run(reporter, func(t *testing.T) {
+			server := NewTestServer(t)
+			t.Cleanup(server.Close)
+
+			t.Setenv("ZAP_TEST_URL", server.URL)
+
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			app := zap.New(false, "test", os.Stdin, false, stdout, stderr)
+
+			options := zap.TestOptions{
+				Path:              filepath.Join("testdata", "test", "fail-assert.http"),
+				Reporter:          reporter,
+				Timeout:           zap.DefaultTimeout,
+				ConnectionTimeout: zap.DefaultConnectionTimeout,
+				OverallTimeout:    zap.DefaultOverallTimeout,
+			}
+
+			err := app.Test(t.Context(), options)
+			test.Err(t, err)
+
+			snap := snapshot.New(
+				t,
+				snapshot.Update(*update),
+				snapshot.Filter(`time="\d+\.\d+"`, `time="[DURATION]"`),
+				snapshot.Filter(`duration_ms: \d+\.\d+`, "duration_ms: [DURATION]"),
+				snapshot.Filter(`"duration": \d+`, `"duration": [DURATION]`),
+				snapshot.Filter(`testdata\\+test\\+`, "testdata/test/"), // Replace windows paths
+			)
+
+			snap.Snap(stdout.String())
+		})
+	}
+}
+
+func TestTestReportColour(t *testing.T) {
+	// Reports are read by tools rather than people, so never carry terminal colours even
+	// when they're enabled, and escape sequences aren't even valid in XML
+	hue.Enabled(true)
+	t.Cleanup(func() { hue.Enabled(false) })
+
+	for _, reporter := range []string{"", "junit", "tap", "json"} {
+		t.Run(cmp.Or(reporter, "terminal"), func(t *testing.T) {
+			server := NewTestServer(t)
+			t.Cleanup(server.Close)
+
+			t.Setenv("ZAP_TEST_URL", server.URL)
+
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			app := zap.New(false, "test", os.Stdin, false, stdout, stderr)
+
+			options := zap.TestOptions{
+				Path:              filepath.Join("testdata", "test", "fail.http"),
+				Reporter:          reporter,
+				Timeout:           zap.DefaultTimeout,
+				ConnectionTimeout: zap.DefaultConnectionTimeout,
+				OverallTimeout:    zap.DefaultOverallTimeout,
+			}
+
+			err := app.Test(t.Context(), options)
+			test.Err(t, err)
+
+			got := stdout.String()
+			test.True(t, strings.Contains(got, "there"), test.Context("no diff in output:\n%s", got))
+
+			switch reporter {
+			case "":
+				test.True(t, strings.Contains(got, "\x1b["), test.Context("terminal output not coloured:\n%s", got))
+			case "junit":
+				test.False(t, strings.Contains(got, "\x1b"), test.Context("report contains escapes:\n%q", got))
+				test.Ok(t, xml.Unmarshal(stdout.Bytes(), new(struct{})), test.Context("invalid XML:\n%s", got))
+			default:
+				test.False(t, strings.Contains(got, "\x1b"), test.Context("report contains escapes:\n%q", got))
+			}
+		})
+	}
+}
+
+func TestTestReportFile(t *testing.T) {
+	server := NewTestServer(t)
+	t.Cleanup(server.Close)
+
+	t.Setenv("ZAP_TEST_URL", server.URL)
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	app := zap.New(false, "test", os.Stdin, false, stdout, stderr)
+
+	report := filepath.Join(t.TempDir(), "report.xml")
+
+	options := zap.TestOptions{
+		Path:              filepath.Join("testdata", "test", "pass.http"),
+		Reporter:          "junit",
+		ReportFile:        report,
+		Timeout:           zap.DefaultTimeout,
+		ConnectionTimeout: zap.DefaultConnectionTimeout,
+		OverallTimeout:    zap.DefaultOverallTimeout,
+	}
+
+	err := app.Test(t.Context(), options)
+	test.Ok(t, err, test.Context("zap test returned an error: %v", stderr.String()))
+
+	// The summary still goes to stdout
+	test.True(t, strings.Contains(stdout.String(), "tests: "), test.Context("stdout missing summary: %s", stdout.String()))
+
+	contents, err := os.ReadFile(report)
+	test.Ok(t, err)
+
+	test.True(
+		t,
+		strings.Contains(string(contents), `<testsuite name="testdata`),
+		test.Context("unexpected report contents: %s", contents),
+	)
+}
+
 func TestCheckValid(t *testing.T) {
 	pattern := filepath.Join("testdata", "check", "valid", "*.http")
 	files, err := filepath.Glob(pattern)
@@ -79,7 +827,7 @@ func TestCheckValid(t *testing.T) {
 			stdout := &bytes.Buffer{}
 			stderr := &bytes.Buffer{}
 
-			app := zap.New(false, "test", os.Stdin, stdout, stderr)
+			app := zap.New(false, "test", os.Stdin, false, stdout, stderr)
 
 			err := app.Check(t.Context(), zap.CheckOptions{Path: file})
 			test.Ok(t, err)
@@ -100,7 +848,7 @@ func TestCheckValidDir(t *testing.T) {
 	stdout := &bytes.Buffer{}
 	stderr := &bytes.Buffer{}
 
-	app := zap.New(false, "test", os.Stdin, stdout, stderr)
+	app := zap.New(false, "test", os.Stdin, false, stdout, stderr)
 
 	err = app.Check(t.Context(), zap.CheckOptions{Path: path})
 	test.Ok(t, err)
@@ -129,7 +877,7 @@ func TestCheckInvalid(t *testing.T) {
 			stdout := &bytes.Buffer{}
 			stderr := &bytes.Buffer{}
 
-			app := zap.New(false, "test", os.Stdin, stdout, stderr)
+			app := zap.New(false, "test", os.Stdin, false, stdout, stderr)
 
 			err := app.Check(t.Context(), zap.CheckOptions{Path: file})
 			test.Err(t, err)
@@ -165,7 +913,7 @@ func TestExport(t *testing.T) {
 			stdout := &bytes.Buffer{}
 			stderr := &bytes.Buffer{}
 
-			app := zap.New(false, "test", os.Stdin, stdout, stderr)
+			app := zap.New(false, "test", os.Stdin, false, stdout, stderr)
 
 			format := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
 
@@ -191,15 +939,227 @@ func TestExport(t *testing.T) {
 	}
 }
 
+func TestExportArchive(t *testing.T) {
+	pattern := filepath.Join("testdata", "export", "*.txtar")
+	files, err := filepath.Glob(pattern)
+	test.Ok(t, err)
+
+	// Each case runs in a temp dir, but the snapshots are kept here
+	root, err := os.Getwd()
+	test.Ok(t, err)
+
+	for _, file := range files {
+		name := filepath.Base(file)
+		t.Run(name, func(t *testing.T) {
+			archive, _ := extractArchive(t, file)
+
+			options := zap.ExportOptions{
+				File: "src.http",
+			}
+
+			archiveOptions(t, archive, &options)
+
+			f, err := os.Open(options.File)
+			test.Ok(t, err)
+			t.Cleanup(func() { f.Close() })
+
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			app := zap.New(false, "test", strings.NewReader(""), false, stdout, stderr)
+
+			err = app.Export(t.Context(), f, options)
+
+			snap := snapshot.New(
+				t,
+				snapshot.Update(*update),
+				snapshot.Filter(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`, "[UUID]"),
+			)
+
+			result := archiveResult(t, name, archive, stdout, stderr, err)
+
+			t.Chdir(root)
+			snap.Snap(result)
+		})
+	}
+}
+
+func TestImportArchive(t *testing.T) {
+	pattern := filepath.Join("testdata", "import", "*.txtar")
+	files, err := filepath.Glob(pattern)
+	test.Ok(t, err)
+
+	// Each case runs in a temp dir, but the snapshots are kept here
+	root, err := os.Getwd()
+	test.Ok(t, err)
+
+	for _, file := range files {
+		name := filepath.Base(file)
+		t.Run(name, func(t *testing.T) {
+			archive, dir := extractArchive(t, file)
+
+			var options zap.ImportOptions
+
+			archiveOptions(t, archive, &options)
+
+			f, err := os.Open(options.File)
+			test.Ok(t, err)
+			t.Cleanup(func() { f.Close() })
+
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			app := zap.New(false, "test", strings.NewReader(""), false, stdout, stderr)
+
+			err = app.Import(t.Context(), f, options)
+
+			snap := snapshot.New(
+				t,
+				snapshot.Update(*update),
+			)
+
+			result := archiveResult(t, name, archive, stdout, stderr, err)
+
+			// Whatever was imported, to stdout or split into files, must be valid .http files
+			if err == nil {
+				if stdout.Len() != 0 {
+					test.Ok(t, os.WriteFile("imported.http", stdout.Bytes(), 0o644))
+				}
+
+				checker := zap.New(false, "test", strings.NewReader(""), false, io.Discard, stderr)
+
+				err = checker.Check(t.Context(), zap.CheckOptions{Path: dir})
+				test.Ok(t, err, test.Context("imported files are invalid: %v", stderr.String()))
+			}
+
+			t.Chdir(root)
+			snap.Snap(result)
+		})
+	}
+}
+
+// extractArchive writes every file in the txtar archive at path to a new temporary
+// directory and changes into it, so relative paths in the archive work as they would
+// for a user. It returns the parsed archive and the directory.
+func extractArchive(tb testing.TB, path string) (*txtar.Archive, string) {
+	tb.Helper()
+
+	archive, err := txtar.ParseFile(path)
+	test.Ok(tb, err)
+
+	dir := tb.TempDir()
+	tb.Chdir(dir)
+
+	for name, contents := range archive.Files() {
+		file := filepath.FromSlash(name)
+
+		test.Ok(tb, os.MkdirAll(filepath.Dir(file), 0o755))
+		test.Ok(tb, os.WriteFile(file, []byte(contents), 0o644))
+	}
+
+	return archive, dir
+}
+
+// archiveOptions decodes the 'options.json' file in archive, if there is one, over the
+// defaults in options.
+func archiveOptions(tb testing.TB, archive *txtar.Archive, options any) {
+	tb.Helper()
+
+	contents, ok := archive.Read("options.json")
+	if !ok {
+		return
+	}
+
+	test.Ok(tb, json.Unmarshal([]byte(contents), options), test.Context("invalid options.json"))
+}
+
+// archiveResult checks a command run against the archive called name succeeded, or
+// failed if the name starts with "fail", and returns what it did for snapshotting.
+//
+// That's what it wrote to stdout, the error it returned and the contents of any files
+// it wrote, each in a txtar style section.
+//
+// Warnings on stderr are logged so their format is down to the logger, rather than
+// snapshotting them every line of the archive's 'stderr' file must appear in it. Without
+// one, a command that succeeded must not have written anything there.
+func archiveResult(tb testing.TB, name string, archive *txtar.Archive, stdout, stderr *bytes.Buffer, err error) string {
+	tb.Helper()
+
+	failed := strings.HasPrefix(name, "fail")
+	if failed {
+		test.Err(tb, err, test.Context("expected an error but got none"))
+	} else {
+		test.Ok(tb, err, test.Context("unexpected error, stderr: %s", stderr.String()))
+	}
+
+	want, ok := archive.Read("stderr")
+	if !ok && !failed {
+		test.Equal(tb, stderr.String(), "", test.Context("unexpected output on stderr"))
+	}
+
+	for line := range strings.Lines(want) {
+		line = strings.TrimSpace(line)
+		test.True(tb, strings.Contains(stderr.String(), line), test.Context("stderr missing %q: %s", line, stderr))
+	}
+
+	result := &strings.Builder{}
+
+	section := func(name, contents string) {
+		if contents == "" {
+			return
+		}
+
+		fmt.Fprintf(result, "-- %s --\n%s", name, contents)
+
+		if !strings.HasSuffix(contents, "\n") {
+			result.WriteString("\n")
+		}
+	}
+
+	section("stdout", stdout.String())
+
+	if err != nil {
+		section("error", err.Error())
+	}
+
+	walkErr := filepath.WalkDir(".", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		path = filepath.ToSlash(path)
+		if _, ok := archive.Read(path); ok {
+			return nil
+		}
+
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		section(path, string(contents))
+
+		return nil
+	})
+	test.Ok(tb, walkErr)
+
+	return result.String()
+}
+
 // NewTestServer spins up a new httptest server with a few endpoints defined for use in
 // zap integration tests.
 //
-// All routes return static JSON content with the Content-Type of application/json.
-//
 // The routes defined are:
 //
-//   - GET /ok: returns a 200 OK
-//   - POST /bad: returns a 400 Bad Request
+//   - GET /ok: returns a 200 OK with the static JSON body {"stuff": "here"}
+//   - POST /bad: returns a 400 Bad Request with the static JSON body {"bad": "yes"}
+//   - GET /volatile: returns a 200 OK with a JSON body whose ids and timestamps change
+//     on every request, and a Date header
+//   - POST /echo: returns a 200 OK with the request body sent straight back, and the
+//     request's Content-Length in the X-Request-Content-Length header
+//
+// The JSON routes have a Content-Type of application/json, the echo route has whatever
+// Go sniffs from the body.
 //
 // The caller is responsible for calling server.Close via t.Cleanup.
 func NewTestServer(tb testing.TB) *httptest.Server {
@@ -221,8 +1181,29 @@ func NewTestServer(tb testing.TB) *httptest.Server {
 		fmt.Fprint(w, `{"bad": "yes"}`)
 	}
 
+	// Returns values that change every time
+	volatileHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		fmt.Fprintf(
+			w,
+			`{"id": %q, "createdAt": %d, "stuff": "here", "items": [{"id": %d, "name": "one"}]}`,
+			rand.Text(),
+			time.Now().UnixNano(),
+			time.Now().UnixMilli(),
+		)
+	}
+
+	// Sends the request body straight back
+	echoHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Date"] = nil
+		w.Header().Set("X-Request-Content-Length", strconv.FormatInt(r.ContentLength, 10))
+		io.Copy(w, r.Body) //nolint:errcheck // Fine for tests
+	}
+
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /ok", successHandler)
+	mux.HandleFunc("POST /echo", echoHandler)
+	mux.HandleFunc("GET /volatile", volatileHandler)
 	mux.HandleFunc("POST /bad", badRequestHandler)
 
 	return httptest.NewServer(mux)